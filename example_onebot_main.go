@@ -5,9 +5,11 @@ import (
 	"github.com/Jel1ySpot/GoroBot/example_plugin/message_logger"
 	"github.com/Jel1ySpot/GoroBot/example_plugin/ping"
 	"github.com/Jel1ySpot/GoroBot/example_plugin/tests"
+	"github.com/Jel1ySpot/GoroBot/pkg/archive"
 	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/help"
 	OneBotClient "github.com/Jel1ySpot/GoroBot/pkg/onebot"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -24,6 +26,8 @@ func onebot_example() {
 
 	// Use the services
 	grb.Use(onebot)
+	grb.Use(archive.Create())
+	grb.Use(help.Create())
 	grb.Use(message_logger.Create())
 	grb.Use(ping.Create())
 	grb.Use(tests.Create())
@@ -33,7 +33,10 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 			return ctx
 		}).
 		Action(func(ctx *command.Context) error {
-			limit := command.GetInt(ctx.KvArgs["upper_bound"])
+			limit, ok := ctx.Int("upper_bound")
+			if !ok {
+				limit = 6
+			}
 
 			if limit <= 0 {
 				_, _ = ctx.ReplyText("骰子点数上限必须大于 0")
@@ -7,6 +7,7 @@ import (
 	"text/template"
 
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 )
 
 var (
@@ -19,11 +20,8 @@ func (s *Service) initCmd() {
 	cmd := grb.Command("plugin")
 
 	_, _ = cmd.SubCommand("lookup").
+		Permission(entity.Owner).
 		Action(func(ctx *command.Context) error {
-			if id, ok := grb.GetOwner(ctx.BotContext().ID()); ok && id != ctx.SenderID() {
-				_, _ = ctx.ReplyText("Permission denied.")
-				return nil
-			}
 			n, err := s.LookupPlugins()
 			if err != nil {
 				return err
@@ -34,11 +32,9 @@ func (s *Service) initCmd() {
 		}).Build()
 
 	_, _ = cmd.SubCommand("load").
+		Permission(entity.Owner).
 		Argument("name", command.String, true, "需要加载的插件").
 		Action(func(ctx *command.Context) error {
-			if id, ok := grb.GetOwner(ctx.BotContext().ID()); ok && id != ctx.SenderID() {
-				return fmt.Errorf("permission denied")
-			}
 			name := ctx.KvArgs["name"]
 			if strings.ToLower(name) == "all" {
 				for name, stat := range s.pluginStat {
@@ -75,11 +71,9 @@ func (s *Service) initCmd() {
 		}).Build()
 
 	_, _ = cmd.SubCommand("enable").
+		Permission(entity.Owner).
 		Argument("name", command.String, true, "需要启用的插件").
 		Action(func(ctx *command.Context) error {
-			if id, ok := grb.GetOwner(ctx.BotContext().ID()); ok && id != ctx.SenderID() {
-				return fmt.Errorf("permission denied")
-			}
 			name := ctx.KvArgs["name"]
 			if strings.ToLower(name) == "all" {
 				s.InitPlugins()
@@ -95,11 +89,9 @@ func (s *Service) initCmd() {
 		}).Build()
 
 	_, _ = cmd.SubCommand("disable").
+		Permission(entity.Owner).
 		Argument("name", command.String, true, "需要禁用的插件").
 		Action(func(ctx *command.Context) error {
-			if id, ok := grb.GetOwner(ctx.BotContext().ID()); ok && id != ctx.SenderID() {
-				return fmt.Errorf("permission denied")
-			}
 			name := ctx.KvArgs["name"]
 			if strings.ToLower(name) == "all" {
 				for name, stat := range s.pluginStat {
@@ -61,7 +61,7 @@ func (s *Service) InitPlugin(name string) error {
 	service := createFunc()
 
 	log.Debug("Initializing plugin service %s", service.Name())
-	if err := service.Init(grb); err != nil {
+	if err := grb.InitService(service); err != nil {
 		return fmt.Errorf("failed to initialize plugin service %s: %v", service.Name(), err)
 	}
 
@@ -2,7 +2,10 @@ package tests
 
 import (
 	"encoding/json"
+	"errors"
+	"time"
 
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 )
 
@@ -31,6 +34,10 @@ func (s *Service) CommandsRegistry() error {
 		return err
 	}
 
+	if err := s.AddCommand("prompt", "测试多轮对话", promptAction); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -84,3 +91,20 @@ func (s *Service) sendImageAction(ctx *command.Context) error {
 	}
 	return nil
 }
+
+func promptAction(ctx *command.Context) error {
+	_, _ = ctx.ReplyText("你在哪个城市？（发送「取消」退出）")
+	msg, err := ctx.Prompt(time.Minute, nil)
+	switch {
+	case errors.Is(err, GoroBot.ErrPromptCanceled):
+		_, _ = ctx.ReplyText("已取消")
+		return nil
+	case errors.Is(err, GoroBot.ErrPromptTimeout):
+		_, _ = ctx.ReplyText("等待超时")
+		return nil
+	case err != nil:
+		return err
+	}
+	_, _ = ctx.ReplyText("你好，来自 ", msg.String(), " 的朋友")
+	return nil
+}
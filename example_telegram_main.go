@@ -6,9 +6,11 @@ import (
 	"github.com/Jel1ySpot/GoroBot/example_plugin/message_logger"
 	"github.com/Jel1ySpot/GoroBot/example_plugin/ping"
 	"github.com/Jel1ySpot/GoroBot/example_plugin/tests"
+	"github.com/Jel1ySpot/GoroBot/pkg/archive"
 	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/help"
 	TelegramClient "github.com/Jel1ySpot/GoroBot/pkg/telegram"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -26,6 +28,8 @@ func telegram_example() {
 	tg := TelegramClient.Create()
 
 	grb.Use(tg)
+	grb.Use(archive.Create())
+	grb.Use(help.Create())
 	grb.Use(message_logger.Create())
 	grb.Use(ping.Create())
 	grb.Use(tests.Create())
@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+)
+
+func (s *Service) initCmd() {
+	cmd := s.grb.Command("history").
+		Description("查询消息记录")
+
+	delFn, _ := cmd.SubCommand("search").
+		Description("在当前会话的消息记录中搜索").
+		Argument("keyword", command.String, true, "关键词").
+		Option("user", "u", command.String, false, "", "只搜索该用户（ID、用户名或昵称）的消息").
+		Option("since", "s", command.String, false, "", "起始时间，如 7d、12h 或 2006-01-02").
+		Action(s.searchAction).
+		Build()
+
+	s.releaseFunc = append(s.releaseFunc, delFn)
+}
+
+func (s *Service) searchAction(ctx *command.Context) error {
+	limit := s.config.SearchLimit
+	if limit <= 0 {
+		limit = defaultConfig.SearchLimit
+	}
+	q := Query{
+		BotID:   ctx.BotContext().ID(),
+		ChatID:  ctx.Message().ChatID(),
+		Keyword: ctx.KvArgs["keyword"],
+		User:    ctx.Options["user"],
+		Limit:   limit + 1, // 多取一条，搜索命令本身会被排除
+	}
+	if since := ctx.Options["since"]; since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+		q.Since = t
+	}
+
+	found, err := s.Search(q)
+	if err != nil {
+		return err
+	}
+	records := found[:0]
+	for _, record := range found {
+		if record.Message.ID != ctx.Message().ID {
+			records = append(records, record)
+		}
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	if len(records) == 0 {
+		_, _ = ctx.ReplyText("没有找到相关消息")
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "找到 %d 条消息：", len(records))
+	for _, record := range records {
+		msg := record.Message
+		name := "未知用户"
+		if msg.Sender != nil && msg.Sender.User != nil {
+			name = msg.Sender.Nickname
+			if name == "" {
+				name = msg.Sender.Name
+			}
+		}
+		fmt.Fprintf(&b, "\n[%s] %s: %s", msg.Time.Format("2006-01-02 15:04"), name, msg.Content)
+	}
+	_, _ = ctx.ReplyText(b.String())
+	return nil
+}
+
+// parseSince 解析相对时间（如 30m、12h、7d）或日期（2006-01-02）
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if len(value) > 1 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'm':
+				return now.Add(-time.Duration(n) * time.Minute), nil
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s, expected 30m, 12h, 7d or 2006-01-02", value)
+}
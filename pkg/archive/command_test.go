@@ -0,0 +1,76 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"30m", now.Add(-30 * time.Minute), true},
+		{"12h", now.Add(-12 * time.Hour), true},
+		{"7d", time.Date(2024, 3, 3, 12, 0, 0, 0, time.Local), true},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), true},
+		{"-1d", time.Time{}, false},
+		{"7w", time.Time{}, false},
+		{"d", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v, want %v, ok %t", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSearchCommand(t *testing.T) {
+	_, bot := newArchive(t)
+	group := testkit.GroupID("g")
+	alice, bob := testkit.UserID("alice"), testkit.UserID("bob")
+
+	for _, msg := range []*testkit.MessageContext{
+		bot.NewTextMessage("今天天气不错").From(alice, "alice").InGroup(group),
+		bot.NewTextMessage("天气预报说会下雨").From(bob, "bob").InGroup(group),
+		bot.NewTextMessage("别的群也在聊天气").From(alice, "alice").InGroup(testkit.GroupID("other")),
+	} {
+		if err := bot.Emit(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(text string) string {
+		t.Helper()
+		return bot.RunCommand(t, bot.NewTextMessage("/"+text).InGroup(group), text)
+	}
+
+	// 只搜索当前会话，搜索命令本身不计入结果
+	got := search("history search 天气")
+	if !strings.HasPrefix(got, "找到 2 条消息") || strings.Contains(got, "别的群") {
+		t.Fatalf("search: got %q", got)
+	}
+	if got := search("history search 天气 --user alice"); !strings.HasPrefix(got, "找到 1 条消息") || !strings.Contains(got, "今天天气不错") {
+		t.Fatalf("--user: got %q", got)
+	}
+	if got := search("history search 天气 -u " + bob); !strings.Contains(got, "下雨") || strings.Contains(got, "今天") {
+		t.Fatalf("-u: got %q", got)
+	}
+	// 之前的搜索命令与回复同样会被归档，这里按用户过滤
+	if got := search("history search 天气 --user alice --since 1h"); !strings.HasPrefix(got, "找到 1 条消息") {
+		t.Fatalf("--since: got %q", got)
+	}
+	if got := search("history search 天气 --since 2099-01-01"); got != "没有找到相关消息" {
+		t.Fatalf("--since in the future: got %q", got)
+	}
+	if got := search("history search 天气 --since soon"); !strings.Contains(got, "invalid time soon") {
+		t.Fatalf("invalid --since: got %q", got)
+	}
+}
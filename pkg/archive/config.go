@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+const DefaultConfigPath = "conf/archive/"
+
+type Config struct {
+	RetentionDays int `json:"retention_days"` // 消息保留天数，0 表示永久保留
+	PruneInterval int `json:"prune_interval"` // 清理过期消息的间隔（分钟）
+	SearchLimit   int `json:"search_limit"`   // history search 返回的最大条数
+}
+
+var defaultConfig = Config{
+	RetentionDays: 90,
+	PruneInterval: 60,
+	SearchLimit:   10,
+}
+
+func (s *Service) initConfig() error {
+	c := s.conic
+	configPath := path.Join(s.configPath, "config.json")
+	c.SetConfigFile(configPath)
+	c.WatchConfig()
+	c.BindRef("", &s.config)
+	c.SetLogger(s.logger.Debug)
+
+	if !util.FileExists(configPath) {
+		if err := util.MkdirIfNotExists(s.configPath); err != nil {
+			return fmt.Errorf("failed to create config directory: %v", err)
+		}
+
+		s.config = defaultConfig
+
+		if err := c.WriteConfig(); err != nil {
+			return fmt.Errorf("failed to write default config: %v", err)
+		}
+
+		s.logger.Info("消息归档配置文件已生成于 %s", configPath)
+		return nil
+	}
+
+	if err := c.ReadConfig(); err != nil {
+		return fmt.Errorf("failed to read archive config: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/conic"
+)
+
+// Service 将机器人收发的所有消息保存到 Instant.OpenDatabase 打开的 SQLite 数据库中，
+// 并作为 GoroBot 的消息存储，为没有历史消息接口的平台提供查询
+type Service struct {
+	grb        *GoroBot.Instant
+	logger     logger.Inst
+	db         *sql.DB
+	conic      *conic.Conic
+	config     Config
+	configPath string
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+
+	releaseFunc []func()
+}
+
+func Create() *Service {
+	return &Service{
+		configPath: DefaultConfigPath,
+		conic:      conic.New(),
+	}
+}
+
+func (s *Service) Name() string {
+	return "Archive"
+}
+
+func (s *Service) Init(grb *GoroBot.Instant) error {
+	s.grb = grb
+	s.logger = grb.GetLogger()
+
+	if !grb.DatabaseExist() {
+		return fmt.Errorf("archive requires a database, call Instant.OpenDatabase first")
+	}
+	s.db = grb.Database()
+
+	if err := s.initConfig(); err != nil {
+		return err
+	}
+
+	if err := ensureTables(s.db); err != nil {
+		return fmt.Errorf("failed to create archive tables: %v", err)
+	}
+
+	grb.UseMessageStore(s)
+	s.initCmd()
+
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	go s.pruneLoop()
+
+	return nil
+}
+
+func (s *Service) Release(grb *GoroBot.Instant) error {
+	if s.ctxCancel != nil {
+		s.ctxCancel()
+	}
+	for _, fn := range s.releaseFunc {
+		fn()
+	}
+	s.releaseFunc = nil
+	if grb.MessageStore() == GoroBot.MessageStore(s) {
+		grb.UseMessageStore(nil)
+	}
+	return nil
+}
+
+// pruneLoop 定期删除超过保留天数的消息
+func (s *Service) pruneLoop() {
+	for {
+		if s.config.RetentionDays > 0 {
+			before := time.Now().AddDate(0, 0, -s.config.RetentionDays)
+			if n, err := s.Prune(before); err != nil {
+				s.logger.Error("清理过期消息失败：%v", err)
+			} else if n > 0 {
+				s.logger.Debug("已清理 %d 条过期消息", n)
+			}
+		}
+
+		interval := time.Duration(s.config.PruneInterval) * time.Minute
+		if interval <= 0 {
+			interval = time.Duration(defaultConfig.PruneInterval) * time.Minute
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
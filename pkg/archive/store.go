@@ -0,0 +1,313 @@
+package archive
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// Record 是一条归档的消息
+type Record struct {
+	BotID    string
+	Protocol string
+	ChatID   string
+	Message  *botc.BaseMessage
+}
+
+// Query 描述一次归档查询，空字段表示不限制
+type Query struct {
+	BotID   string
+	ChatID  string
+	Keyword string // 全文检索关键词
+	User    string // 发送者 ID、用户名或昵称
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func ensureTables(db *sql.DB) error {
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS MESSAGES (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BOT_ID TEXT NOT NULL,
+    PROTOCOL TEXT NOT NULL,
+    MESSAGE_ID TEXT NOT NULL,
+    MESSAGE_TYPE INTEGER NOT NULL,
+    CHAT_ID TEXT NOT NULL,
+    CHAT_NAME TEXT,
+    SENDER_ID TEXT,
+    SENDER_NAME TEXT,
+    SENDER_NICKNAME TEXT,
+    CONTENT TEXT,
+    ELEMENTS TEXT,
+    TIME NUMERIC NOT NULL,
+    UNIQUE (BOT_ID, MESSAGE_ID)
+);`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS MESSAGES_CHAT ON MESSAGES (BOT_ID, CHAT_ID, TIME);`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS MESSAGES_TIME ON MESSAGES (TIME);`); err != nil {
+		return err
+	}
+	// DOCID 与 MESSAGES.ID 对应
+	_, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS MESSAGES_FTS USING fts4(CONTENT);`)
+	return err
+}
+
+// --- GoroBot.MessageStore 接口实现 ---
+
+func (s *Service) Save(botID string, chatID string, msg *botc.BaseMessage) error {
+	elements, err := json.Marshal(msg.Elements)
+	if err != nil {
+		return err
+	}
+
+	var chatName, senderID, senderName, senderNickname string
+	if msg.Sender != nil {
+		if msg.Sender.User != nil && msg.Sender.Base != nil {
+			senderID, senderName = msg.Sender.ID, msg.Sender.Name
+			senderNickname = msg.Sender.Nickname
+		}
+		if msg.Sender.From != nil && msg.MessageType == botc.GroupMessage {
+			chatName = msg.Sender.From.Name
+		}
+	}
+	t := msg.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	protocol, _, _ := strings.Cut(botID, ":")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(`INSERT OR IGNORE INTO MESSAGES (BOT_ID, PROTOCOL, MESSAGE_ID, MESSAGE_TYPE, CHAT_ID, CHAT_NAME, SENDER_ID, SENDER_NAME, SENDER_NICKNAME, CONTENT, ELEMENTS, TIME) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		botID, protocol, msg.ID, int(msg.MessageType), chatID, chatName, senderID, senderName, senderNickname, msg.Content, string(elements), t.Unix())
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil
+	}
+	rowID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO MESSAGES_FTS (DOCID, CONTENT) VALUES (?, ?)`, rowID, segment(msg.Content)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Service) Get(botID string, msgID string) (*botc.BaseMessage, error) {
+	rows, err := s.db.Query(`SELECT `+recordColumns+` FROM MESSAGES WHERE BOT_ID = ? AND MESSAGE_ID = ?`, botID, msgID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, GoroBot.ErrMessageNotFound
+	}
+	return records[0].Message, nil
+}
+
+func (s *Service) History(botID string, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	query := `SELECT ` + recordColumns + ` FROM MESSAGES WHERE BOT_ID = ? AND CHAT_ID = ?`
+	args := []any{botID, chatID}
+	if before != "" {
+		var rowID int64
+		err := s.db.QueryRow(`SELECT ID FROM MESSAGES WHERE BOT_ID = ? AND MESSAGE_ID = ?`, botID, before).Scan(&rowID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, GoroBot.ErrMessageNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		query += ` AND ID < ?`
+		args = append(args, rowID)
+	}
+	query += ` ORDER BY ID DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*botc.BaseMessage, len(records))
+	for idx, record := range records {
+		messages[len(records)-1-idx] = record.Message
+	}
+	return messages, nil
+}
+
+// --- 查询接口 ---
+
+// Search 按条件查询归档消息，结果按时间从新到旧排列
+func (s *Service) Search(q Query) ([]Record, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if q.BotID != "" {
+		conditions = append(conditions, `BOT_ID = ?`)
+		args = append(args, q.BotID)
+	}
+	if q.ChatID != "" {
+		conditions = append(conditions, `CHAT_ID = ?`)
+		args = append(args, q.ChatID)
+	}
+	if q.User != "" {
+		conditions = append(conditions, `(SENDER_ID = ? OR SENDER_NAME = ? OR SENDER_NICKNAME = ?)`)
+		args = append(args, q.User, q.User, q.User)
+	}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, `TIME >= ?`)
+		args = append(args, q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		conditions = append(conditions, `TIME < ?`)
+		args = append(args, q.Until.Unix())
+	}
+	if match := matchExpr(q.Keyword); match != "" {
+		conditions = append(conditions, `ID IN (SELECT DOCID FROM MESSAGES_FTS WHERE MESSAGES_FTS MATCH ?)`)
+		args = append(args, match)
+	}
+
+	query := `SELECT ` + recordColumns + ` FROM MESSAGES`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	query += ` ORDER BY TIME DESC, ID DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecords(rows)
+}
+
+// Prune 删除 before 之前的消息，返回删除的条数
+func (s *Service) Prune(before time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM MESSAGES_FTS WHERE DOCID IN (SELECT ID FROM MESSAGES WHERE TIME < ?)`, before.Unix()); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM MESSAGES WHERE TIME < ?`, before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordColumns = `BOT_ID, PROTOCOL, MESSAGE_ID, MESSAGE_TYPE, CHAT_ID, CHAT_NAME, SENDER_ID, SENDER_NAME, SENDER_NICKNAME, CONTENT, ELEMENTS, TIME`
+
+func scanRecords(rows *sql.Rows) ([]Record, error) {
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var (
+			record                Record
+			msgID, content, elems string
+			msgType               int
+			chatName              sql.NullString
+			senderID, senderName  sql.NullString
+			senderNickname        sql.NullString
+			timeUnix              int64
+		)
+		if err := rows.Scan(&record.BotID, &record.Protocol, &msgID, &msgType, &record.ChatID, &chatName, &senderID, &senderName, &senderNickname, &content, &elems, &timeUnix); err != nil {
+			return nil, err
+		}
+
+		msg := &botc.BaseMessage{
+			MessageType: botc.MessageType(msgType),
+			ID:          msgID,
+			Content:     content,
+			Time:        time.Unix(timeUnix, 0),
+		}
+		_ = json.Unmarshal([]byte(elems), &msg.Elements)
+		if senderID.String != "" {
+			msg.Sender = &entity.Sender{
+				User: &entity.User{
+					Base: &entity.Base{
+						ID:   senderID.String,
+						Name: senderName.String,
+					},
+					Nickname: senderNickname.String,
+				},
+			}
+			if msg.MessageType == botc.GroupMessage {
+				msg.Sender.From = &entity.Base{
+					ID:   record.ChatID,
+					Name: chatName.String,
+				}
+			}
+		}
+		record.Message = msg
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// segment 在中日韩文字两侧插入空格，使 FTS 默认分词器按单字建立索引
+func segment(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if isCJK(r) {
+			b.WriteRune(' ')
+			b.WriteRune(r)
+			b.WriteRune(' ')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// matchExpr 将关键词转换为 FTS 短语查询，中文关键词按连续单字匹配
+func matchExpr(keyword string) string {
+	keyword = strings.ReplaceAll(keyword, `"`, " ")
+	tokens := strings.Fields(segment(keyword))
+	if len(tokens) == 0 {
+		return ""
+	}
+	return `"` + strings.Join(tokens, " ") + `"`
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
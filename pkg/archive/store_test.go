@@ -0,0 +1,196 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newArchive 在临时数据库上启用归档，配置写入临时目录
+func newArchive(t *testing.T) (*Service, *testkit.Bot) {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+	if err := grb.OpenDatabase("sqlite3", filepath.Join(t.TempDir(), "bot.db")); err != nil {
+		t.Fatal(err)
+	}
+	s := Create()
+	s.configPath = t.TempDir()
+	if err := s.Init(grb); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.Release(grb)
+		_ = grb.Database().Close()
+	})
+	return s, bot
+}
+
+func archiveMessage(t *testing.T, s *Service, chatID, senderID, senderName, content string, at time.Time) *botc.BaseMessage {
+	t.Helper()
+	msg := &botc.BaseMessage{
+		MessageType: botc.GroupMessage,
+		ID:          fmt.Sprintf("msg-%d", at.UnixNano()),
+		Content:     content,
+		Sender: &entity.Sender{
+			User: &entity.User{
+				Base:     &entity.Base{ID: senderID, Name: senderName},
+				Nickname: senderName + " nick",
+			},
+			From: &entity.Base{ID: chatID, Name: "chat " + chatID},
+		},
+		Time: at,
+	}
+	if err := s.Save("bot", chatID, msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func contents(records []Record) string {
+	list := make([]string, len(records))
+	for n, record := range records {
+		list[n] = record.Message.Content
+	}
+	return fmt.Sprint(list)
+}
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		text    string
+		segment string
+		match   string
+	}{
+		{"hello world", "hello world", `"hello world"`},
+		{"今天天气", " 今  天  天  气 ", `"今 天 天 气"`},
+		{"go语言", "go 语  言 ", `"go 语 言"`},
+		{"カタカナ", " カ  タ  カ  ナ ", `"カ タ カ ナ"`},
+		{"한국어", " 한  국  어 ", `"한 국 어"`},
+		{`say "hi"`, `say "hi"`, `"say hi"`},
+		{"  ", "  ", ""},
+	}
+	for _, tt := range tests {
+		if got := segment(tt.text); got != tt.segment {
+			t.Errorf("segment(%q) = %q, want %q", tt.text, got, tt.segment)
+		}
+		if got := matchExpr(tt.text); got != tt.match {
+			t.Errorf("matchExpr(%q) = %q, want %q", tt.text, got, tt.match)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s, _ := newArchive(t)
+	now := time.Now()
+
+	archiveMessage(t, s, "g1", "u1", "alice", "今天天气不错", now.Add(-3*time.Hour))
+	archiveMessage(t, s, "g1", "u2", "bob", "天空很蓝", now.Add(-2*time.Hour))
+	archiveMessage(t, s, "g1", "u2", "bob", "明天天气怎么样 weather", now.Add(-time.Hour))
+	archiveMessage(t, s, "g2", "u1", "alice", "另一个群的天气", now.Add(-time.Minute))
+
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"chinese keyword", Query{ChatID: "g1", Keyword: "天气"}, "[明天天气怎么样 weather 今天天气不错]"},
+		{"single character", Query{ChatID: "g1", Keyword: "天"}, "[明天天气怎么样 weather 天空很蓝 今天天气不错]"},
+		{"phrase order", Query{ChatID: "g1", Keyword: "气天"}, "[]"},
+		{"latin keyword", Query{Keyword: "WEATHER"}, "[明天天气怎么样 weather]"},
+		{"all chats", Query{Keyword: "天气"}, "[另一个群的天气 明天天气怎么样 weather 今天天气不错]"},
+		{"user id", Query{User: "u1"}, "[另一个群的天气 今天天气不错]"},
+		{"user name", Query{ChatID: "g1", User: "bob"}, "[明天天气怎么样 weather 天空很蓝]"},
+		{"user nickname", Query{ChatID: "g1", User: "alice nick"}, "[今天天气不错]"},
+		{"since", Query{ChatID: "g1", Since: now.Add(-90 * time.Minute)}, "[明天天气怎么样 weather]"},
+		{"until", Query{ChatID: "g1", Until: now.Add(-150 * time.Minute)}, "[今天天气不错]"},
+		{"limit", Query{Keyword: "天气", Limit: 1}, "[另一个群的天气]"},
+		{"bot", Query{BotID: "other"}, "[]"},
+	}
+	for _, tt := range tests {
+		records, err := s.Search(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := contents(records); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	records, err := s.Search(Query{ChatID: "g1", User: "u1"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("got %d records, %v", len(records), err)
+	}
+	msg := records[0].Message
+	if msg.Sender.Name != "alice" || msg.Sender.Nickname != "alice nick" || msg.Sender.From.Name != "chat g1" {
+		t.Fatalf("sender not restored: %+v %+v", msg.Sender.User, msg.Sender.From)
+	}
+}
+
+func TestHistoryAndGet(t *testing.T) {
+	s, _ := newArchive(t)
+	now := time.Now()
+
+	var ids []string
+	for n := range 4 {
+		ids = append(ids, archiveMessage(t, s, "g1", "u1", "alice", fmt.Sprint(n), now.Add(time.Duration(n)*time.Second)).ID)
+	}
+	archiveMessage(t, s, "g2", "u1", "alice", "other chat", now)
+
+	history, err := s.History("bot", "g1", ids[3], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Content != "1" || history[1].Content != "2" {
+		t.Fatalf("unexpected history %v", history)
+	}
+	if _, err := s.History("bot", "g1", "missing", 2); !errors.Is(err, GoroBot.ErrMessageNotFound) {
+		t.Fatalf("unknown anchor: got %v", err)
+	}
+
+	msg, err := s.Get("bot", ids[0])
+	if err != nil || msg.Content != "0" {
+		t.Fatalf("Get: %v, %v", msg, err)
+	}
+	if _, err := s.Get("other", ids[0]); !errors.Is(err, GoroBot.ErrMessageNotFound) {
+		t.Fatalf("other bot: got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s, _ := newArchive(t)
+	now := time.Now()
+
+	archiveMessage(t, s, "g1", "u1", "alice", "旧的天气", now.AddDate(0, 0, -100))
+	archiveMessage(t, s, "g1", "u1", "alice", "新的天气", now)
+
+	n, err := s.Prune(now.AddDate(0, 0, -90))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("pruned %d messages, want 1", n)
+	}
+	records, err := s.Search(Query{Keyword: "天气"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := contents(records); got != "[新的天气]" {
+		t.Fatalf("got %s after prune", got)
+	}
+
+	// 全文索引中对应的行也被删除
+	var indexed int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM MESSAGES_FTS`).Scan(&indexed); err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 1 {
+		t.Fatalf("%d rows left in the full-text index, want 1", indexed)
+	}
+}
@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+const DefaultConfigPath = "conf/bridge/"
+
+// Channel 描述桥接的一端
+type Channel struct {
+	Bot   string `json:"bot"`   // 收发消息的 bot 上下文 ID 或协议名，留空时按群组 ID 的协议前缀选择
+	Group string `json:"group"` // 群组 ID，例如 lagrange:group&123456、telegram:-100123456
+}
+
+// Pair 中两端的消息会互相转发
+type Pair struct {
+	A Channel `json:"a"`
+	B Channel `json:"b"`
+}
+
+type Config struct {
+	Pairs   []Pair `json:"pairs"`
+	Prefix  string `json:"prefix"`   // 转发消息的前缀，{nickname} 替换为发送者昵称，{protocol} 替换为来源协议
+	MapSize int    `json:"map_size"` // 记录消息对应关系的数量，用于映射引用
+}
+
+var defaultConfig = Config{
+	Pairs:   []Pair{},
+	Prefix:  "[{nickname}] ",
+	MapSize: 2000,
+}
+
+func (s *Service) initConfig() error {
+	c := s.conic
+	configPath := path.Join(s.configPath, "config.json")
+	c.SetConfigFile(configPath)
+	c.WatchConfig()
+	c.BindRef("", &s.config)
+	c.SetLogger(s.logger.Debug)
+
+	if !util.FileExists(configPath) {
+		if err := util.MkdirIfNotExists(s.configPath); err != nil {
+			return fmt.Errorf("failed to create config directory: %v", err)
+		}
+
+		s.config = defaultConfig
+
+		if err := c.WriteConfig(); err != nil {
+			return fmt.Errorf("failed to write default config: %v", err)
+		}
+
+		s.logger.Info("消息桥接配置文件已生成于 %s，请填写需要桥接的群组", configPath)
+		return nil
+	}
+
+	if err := c.ReadConfig(); err != nil {
+		return fmt.Errorf("failed to read bridge config: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package bridge
+
+import (
+	"strings"
+	"sync"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+// relay 记录一条原始消息及其在各个桥接群组中的副本
+type relay struct {
+	mu       sync.Mutex
+	messages map[string]*botc.BaseMessage // 键为 channelKey
+}
+
+func (r *relay) set(key string, msg *botc.BaseMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[key] = msg
+}
+
+func (r *relay) get(key string) *botc.BaseMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages[key]
+}
+
+func msgKey(botID string, msgID string) string {
+	return botID + "|" + msgID
+}
+
+func channelKey(botID string, groupID string) string {
+	return botID + "|" + groupID
+}
+
+// fileBuilder 由支持发送文件的 MessageBuilder 实现
+type fileBuilder interface {
+	File(path string, name ...string) botc.MessageBuilder
+}
+
+// target 是一条消息需要转发到的群组
+type target struct {
+	bot   botc.BotContext
+	group string
+}
+
+func (s *Service) onMessage(ctx botc.MessageContext) {
+	msg := ctx.Message()
+	source := ctx.BotContext()
+	if msg == nil || source == nil || msg.MessageType != botc.GroupMessage {
+		return
+	}
+	// 机器人自己发出的消息（包括转发产生的副本）不再转发，避免回环
+	if s.fromBot(msg) {
+		return
+	}
+	key := msgKey(source.ID(), msg.ID)
+	if _, ok := s.relays.Get(key); ok {
+		return
+	}
+
+	targets := s.targets(source, msg.ChatID())
+	if len(targets) == 0 {
+		return
+	}
+
+	r := &relay{messages: map[string]*botc.BaseMessage{
+		channelKey(source.ID(), msg.ChatID()): msg,
+	}}
+	s.relays.Add(key, r)
+
+	for _, t := range targets {
+		sent, err := s.forward(source, msg, t)
+		if err != nil {
+			s.logger.Error("转发消息 %s 到 %s 失败：%v", msg.ID, t.group, err)
+			continue
+		}
+		if sent == nil {
+			continue
+		}
+		r.set(channelKey(t.bot.ID(), t.group), sent)
+		s.relays.Add(msgKey(t.bot.ID(), sent.ID), r)
+	}
+}
+
+// fromBot 判断消息是否由当前运行的任一账号发出
+func (s *Service) fromBot(msg *botc.BaseMessage) bool {
+	if msg.Sender == nil || msg.Sender.User == nil || msg.Sender.Base == nil {
+		return false
+	}
+	for _, bot := range s.grb.Contexts() {
+		if bot.ID() == msg.Sender.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// targets 返回与来源群组配对的所有群组
+func (s *Service) targets(source botc.BotContext, groupID string) []target {
+	var targets []target
+	for _, pair := range s.config.Pairs {
+		var to Channel
+		switch {
+		case matches(pair.A, source, groupID):
+			to = pair.B
+		case matches(pair.B, source, groupID):
+			to = pair.A
+		default:
+			continue
+		}
+		bot, err := s.grb.Account(to.Bot, to.Group)
+		if err != nil {
+			s.logger.Warning("桥接目标 %s 没有可用的账号：%v", to.Group, err)
+			continue
+		}
+		if bot.ID() == source.ID() && to.Group == groupID {
+			continue
+		}
+		targets = append(targets, target{bot: bot, group: to.Group})
+	}
+	return targets
+}
+
+func matches(ch Channel, bot botc.BotContext, groupID string) bool {
+	if ch.Group != groupID {
+		return false
+	}
+	return ch.Bot == "" || ch.Bot == bot.ID() || ch.Bot == bot.Protocol()
+}
+
+// forward 通过目标账号重新构建并发送消息，图片和文件经资源系统下载后重新上传
+func (s *Service) forward(source botc.BotContext, msg *botc.BaseMessage, t target) (*botc.BaseMessage, error) {
+	b := t.bot.NewMessageBuilder()
+
+	if quoted := s.quoted(source, msg, t); quoted != nil {
+		b.Quote(quoted)
+	}
+
+	if prefix := s.prefix(source, msg); prefix != "" {
+		b.Text(prefix)
+	}
+
+	for _, elem := range msg.Elements {
+		switch elem.Type {
+		case botc.QuoteElement:
+		case botc.ImageElement:
+			path, err := s.grb.LoadResourceFromID(elem.Source)
+			if err != nil {
+				s.logger.Warning("加载图片 %s 失败：%v", elem.Source, err)
+				b.Text("[图片]")
+				continue
+			}
+			b.ImageFromFile(path)
+		case botc.FileElement:
+			fb, ok := b.(fileBuilder)
+			if !ok {
+				b.Text("[文件] " + elem.Content)
+				continue
+			}
+			path, err := s.grb.LoadResourceFromID(elem.Source)
+			if err != nil {
+				s.logger.Warning("加载文件 %s 失败：%v", elem.Source, err)
+				b.Text("[文件] " + elem.Content)
+				continue
+			}
+			fb.File(path, elem.Content)
+		default:
+			if elem.Content != "" {
+				b.Text(elem.Content)
+			}
+		}
+	}
+
+	return b.Send(t.group)
+}
+
+// quoted 返回被引用消息在目标群组中对应的消息，没有对应关系时返回 nil
+func (s *Service) quoted(source botc.BotContext, msg *botc.BaseMessage, t target) *botc.BaseMessage {
+	for _, elem := range msg.Elements {
+		if elem.Type != botc.QuoteElement {
+			continue
+		}
+		// 部分适配器的引用元素保存完整的被引用消息，其余只保存消息 ID
+		quotedID := elem.Source
+		if m, err := botc.UnmarshallMessage(elem.Source); err == nil && m.ID != "" {
+			quotedID = m.ID
+		}
+		if r, ok := s.relays.Get(msgKey(source.ID(), quotedID)); ok {
+			return r.get(channelKey(t.bot.ID(), t.group))
+		}
+		return nil
+	}
+	return nil
+}
+
+func (s *Service) prefix(source botc.BotContext, msg *botc.BaseMessage) string {
+	nickname := ""
+	if msg.Sender != nil && msg.Sender.User != nil {
+		nickname = msg.Sender.Nickname
+		if nickname == "" && msg.Sender.Base != nil {
+			nickname = msg.Sender.Name
+			if nickname == "" {
+				nickname = msg.Sender.ID
+			}
+		}
+	}
+	return strings.NewReplacer(
+		"{nickname}", nickname,
+		"{protocol}", source.Protocol(),
+	).Replace(s.config.Prefix)
+}
@@ -0,0 +1,63 @@
+package bridge
+
+import (
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+	"github.com/Jel1ySpot/conic"
+)
+
+// Service 在不同平台、不同账号的群组之间互相转发消息
+type Service struct {
+	grb        *GoroBot.Instant
+	logger     logger.Inst
+	conic      *conic.Conic
+	config     Config
+	configPath string
+
+	// relays 以 msgKey 为键记录每条消息所属的转发记录，用于映射引用和防止回环
+	relays *util.LRU[string, *relay]
+
+	releaseFunc func()
+}
+
+func Create() *Service {
+	return &Service{
+		configPath: DefaultConfigPath,
+		conic:      conic.New(),
+	}
+}
+
+func (s *Service) Name() string {
+	return "Bridge"
+}
+
+func (s *Service) Init(grb *GoroBot.Instant) error {
+	s.grb = grb
+	s.logger = grb.GetLogger()
+
+	if err := s.initConfig(); err != nil {
+		return err
+	}
+
+	size := s.config.MapSize
+	if size <= 0 {
+		size = defaultConfig.MapSize
+	}
+	s.relays = util.NewLRU[string, *relay](size)
+
+	s.releaseFunc, _ = grb.On(GoroBot.MessageEvent(func(ctx botc.MessageContext) {
+		s.onMessage(ctx)
+	}))
+
+	return nil
+}
+
+func (s *Service) Release(grb *GoroBot.Instant) error {
+	if s.releaseFunc != nil {
+		s.releaseFunc()
+		s.releaseFunc = nil
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package console
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+const DefaultConfigPath = "conf/console/"
+
+type Config struct {
+	CommandPrefix string `json:"command_prefix"`
+	MetaPrefix    string `json:"meta_prefix"` // 控制台自身的元命令前缀
+	Sender        string `json:"sender"`      // 初始模拟发送者
+	Group         string `json:"group"`       // 初始所在群组，留空为私聊
+	Authority     string `json:"authority"`   // 初始权限：member / group_admin / group_owner / admin / owner
+}
+
+var defaultConfig = Config{
+	CommandPrefix: "/",
+	MetaPrefix:    ":",
+	Sender:        "developer",
+	Group:         "",
+	Authority:     "owner",
+}
+
+func (s *Service) initConfig() error {
+	c := s.conic
+	configPath := path.Join(s.configPath, "config.json")
+	c.SetConfigFile(configPath)
+	c.WatchConfig()
+	c.BindRef("", &s.config)
+	c.SetLogger(s.logger.Debug)
+
+	if !util.FileExists(configPath) {
+		if err := util.MkdirIfNotExists(s.configPath); err != nil {
+			return fmt.Errorf("failed to create config directory: %v", err)
+		}
+
+		s.config = defaultConfig
+
+		if err := c.WriteConfig(); err != nil {
+			return fmt.Errorf("failed to write default config: %v", err)
+		}
+
+		s.logger.Info("Console config file created at %s with default settings", configPath)
+		return nil
+	}
+
+	if err := c.ReadConfig(); err != nil {
+		return fmt.Errorf("failed to read console config: %v", err)
+	}
+
+	if s.config.CommandPrefix == "" {
+		s.config.CommandPrefix = defaultConfig.CommandPrefix
+	}
+	if s.config.MetaPrefix == "" {
+		s.config.MetaPrefix = defaultConfig.MetaPrefix
+	}
+	if s.config.Sender == "" {
+		s.config.Sender = defaultConfig.Sender
+	}
+
+	return nil
+}
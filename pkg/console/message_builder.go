@@ -0,0 +1,108 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+type MessageBuilder struct {
+	service  *Service
+	elements []*botc.MessageElement
+	err      error
+}
+
+func (m *MessageBuilder) Protocol() string {
+	return Protocol
+}
+
+func (m *MessageBuilder) append(elementType botc.ElementType, content string, source string) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	m.elements = append(m.elements, &botc.MessageElement{
+		Type:    elementType,
+		Content: content,
+		Source:  source,
+	})
+	return m
+}
+
+func (m *MessageBuilder) Text(text string) botc.MessageBuilder {
+	return m.append(botc.TextElement, text, "")
+}
+
+func (m *MessageBuilder) Quote(msg *botc.BaseMessage) botc.MessageBuilder {
+	return m.append(botc.QuoteElement, "[回复]", msg.Marshall())
+}
+
+func (m *MessageBuilder) Mention(id string) botc.MessageBuilder {
+	return m.append(botc.MentionElement, fmt.Sprintf("@%s", id), id)
+}
+
+func (m *MessageBuilder) ImageFromFile(path string) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	if _, err := os.Stat(path); err != nil {
+		m.err = fmt.Errorf("failed to open image file: %w", err)
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", m.service.saveFileResource(path))
+}
+
+func (m *MessageBuilder) ImageFromUrl(url string) botc.MessageBuilder {
+	return m.append(botc.ImageElement, "[图片]", url)
+}
+
+func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	id, err := m.service.grb.SaveResourceData(data, "dat")
+	if err != nil {
+		m.err = err
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", id)
+}
+
+func (m *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	id, err := m.service.grb.SaveResourceReader(r, "dat")
+	if err != nil {
+		m.err = err
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", id)
+}
+
+func (m *MessageBuilder) ReplyTo(msgCtx botc.MessageContext) (*botc.BaseMessage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if ctx, ok := msgCtx.(*command.Context); ok {
+		msgCtx = ctx.MessageContext
+	}
+	return msgCtx.Reply(m.elements)
+}
+
+func (m *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if info, ok := entity.ParseInfo(id); ok && len(info.Args) > 0 && info.Args[0] == "group" {
+		return m.service.SendGroupMessage(entity.Group{Base: &entity.Base{ID: id}}, m.elements)
+	}
+	if !strings.HasPrefix(id, Protocol+":") {
+		id = GenUserID(id)
+	}
+	return m.service.SendDirectMessage(entity.User{Base: &entity.Base{ID: id}}, m.elements)
+}
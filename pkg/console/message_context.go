@@ -0,0 +1,86 @@
+package console
+
+import (
+	"fmt"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+type MessageContext struct {
+	service *Service
+	base    *botc.BaseMessage
+}
+
+// newMessage 以当前模拟身份构造一条入站消息
+func (s *Service) newMessage(elements []*botc.MessageElement) *MessageContext {
+	s.identMu.RLock()
+	sender, group, authority := s.sender, s.group, s.authority
+	s.identMu.RUnlock()
+
+	msg := &botc.BaseMessage{
+		MessageType: botc.DirectMessage,
+		ID:          s.genMessageID(),
+		Content:     botc.ElemsToString(elements),
+		Elements:    elements,
+		Sender: &entity.Sender{
+			User: &entity.User{
+				Base: &entity.Base{
+					ID:   GenUserID(sender),
+					Name: sender,
+				},
+				Nickname:  sender,
+				Authority: authority,
+			},
+		},
+		Time: time.Now(),
+	}
+	if group != "" {
+		msg.MessageType = botc.GroupMessage
+		msg.Sender.From = &entity.Base{
+			ID:   GenGroupID(group),
+			Name: group,
+		}
+	}
+
+	return &MessageContext{service: s, base: msg}
+}
+
+func (m *MessageContext) Protocol() string {
+	return Protocol
+}
+
+func (m *MessageContext) BotContext() botc.BotContext {
+	return m.service
+}
+
+func (m *MessageContext) String() string {
+	return m.base.Content
+}
+
+func (m *MessageContext) Message() *botc.BaseMessage {
+	return m.base
+}
+
+func (m *MessageContext) SenderID() string {
+	return m.base.Sender.ID
+}
+
+func (m *MessageContext) NewMessageBuilder() botc.MessageBuilder {
+	return &MessageBuilder{service: m.service}
+}
+
+func (m *MessageContext) Reply(elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	if elements == nil {
+		return nil, fmt.Errorf("elements is nil")
+	}
+	if m.base.MessageType == botc.GroupMessage {
+		return m.service.SendGroupMessage(entity.Group{Base: m.base.Sender.From}, elements)
+	}
+	return m.service.SendDirectMessage(*m.base.Sender.User, elements)
+}
+
+func (m *MessageContext) ReplyText(a ...any) (*botc.BaseMessage, error) {
+	return m.Reply(botc.NewBuilder().Text(fmt.Sprint(a...)).Build())
+}
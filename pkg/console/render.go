@@ -0,0 +1,43 @@
+package console
+
+import (
+	"fmt"
+	"strings"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+var elementLabels = map[botc.ElementType]string{
+	botc.ImageElement:   "图片",
+	botc.VideoElement:   "视频",
+	botc.FileElement:    "文件",
+	botc.VoiceElement:   "语音",
+	botc.StickerElement: "表情",
+	botc.LinkElement:    "链接",
+}
+
+// Render 将消息元素渲染为终端可读的文本，多媒体元素显示为 [类型:资源ID]
+func Render(elements []*botc.MessageElement) string {
+	var sb strings.Builder
+	for _, elem := range elements {
+		switch elem.Type {
+		case botc.TextElement, botc.OtherElement:
+			sb.WriteString(elem.Content)
+		case botc.MentionElement:
+			sb.WriteString(fmt.Sprintf("[@%s]", elem.Source))
+		case botc.QuoteElement:
+			id := elem.Source
+			if msg, err := botc.UnmarshallMessage(elem.Source); err == nil {
+				id = msg.ID
+			}
+			sb.WriteString(fmt.Sprintf("[回复:%s]", id))
+		default:
+			label, ok := elementLabels[elem.Type]
+			if !ok {
+				label = "未知"
+			}
+			sb.WriteString(fmt.Sprintf("[%s:%s]", label, elem.Source))
+		}
+	}
+	return sb.String()
+}
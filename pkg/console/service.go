@@ -0,0 +1,511 @@
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	urlpkg "net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/conic"
+)
+
+const Protocol = "console"
+
+// Service 是基于标准输入输出的适配器，方便在本地调试插件
+type Service struct {
+	config     Config
+	configPath string
+	conic      *conic.Conic
+
+	grb      *GoroBot.Instant
+	logger   logger.Inst
+	status   botc.LoginStatus
+	statusMu sync.Mutex
+
+	// Input / Output 默认为 os.Stdin / os.Stdout。
+	// Release 时 Input 如果实现了 io.Closer（os.Stdin 除外）会被关闭
+	Input  io.Reader
+	Output io.Writer
+	outMu  sync.Mutex
+
+	// 当前模拟的会话身份
+	sender    string
+	group     string
+	authority entity.Authority
+	identMu   sync.RWMutex
+
+	msgSeq      atomic.Int64
+	done        chan struct{}
+	releaseOnce sync.Once
+}
+
+// Create 创建控制台适配器，可选传入配置目录，默认为 DefaultConfigPath
+func Create(configPath ...string) *Service {
+	s := &Service{
+		configPath: DefaultConfigPath,
+		conic:      conic.New(),
+		status:     botc.Offline,
+		Input:      os.Stdin,
+		Output:     os.Stdout,
+	}
+	if len(configPath) > 0 && configPath[0] != "" {
+		s.configPath = configPath[0]
+	}
+	return s
+}
+
+func (s *Service) Name() string {
+	return "Console-adapter"
+}
+
+func (s *Service) Init(grb *GoroBot.Instant) error {
+	s.grb = grb
+	s.logger = grb.GetLogger()
+
+	if err := s.initConfig(); err != nil {
+		return err
+	}
+
+	s.sender = s.config.Sender
+	s.group = s.config.Group
+	s.authority = entity.Owner
+	if a, ok := entity.ParseAuthority(s.config.Authority); ok {
+		s.authority = a
+	}
+
+	s.done = make(chan struct{})
+	grb.AddContext(s)
+	s.setStatus(botc.Online, "")
+
+	go s.readLoop()
+
+	s.logger.Success("Console adapter initialized, type %shelp for meta commands", s.config.MetaPrefix)
+	return nil
+}
+
+func (s *Service) Release(grb *GoroBot.Instant) error {
+	s.releaseOnce.Do(func() {
+		if s.done != nil {
+			close(s.done)
+		}
+		// 关闭输入以唤醒阻塞在读取上的 scan，os.Stdin 无法中断，scan 会在下一行输入后退出
+		if closer, ok := s.Input.(io.Closer); ok && s.Input != os.Stdin {
+			_ = closer.Close()
+		}
+	})
+	s.setStatus(botc.Offline, "adapter released")
+	grb.RemoveContext(s.ID())
+	return nil
+}
+
+// readLoop 处理 scan 读到的每一行，Release 后立即返回
+func (s *Service) readLoop() {
+	lines := make(chan string)
+	go s.scan(lines)
+
+	s.prompt()
+	for {
+		select {
+		case <-s.done:
+			return
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			s.HandleLine(line)
+			s.prompt()
+		}
+	}
+}
+
+// scan 逐行读取 Input 发送到 lines，输入结束或 Release 后关闭 lines
+func (s *Service) scan(lines chan<- string) {
+	defer close(lines)
+	scanner := bufio.NewScanner(s.Input)
+	for scanner.Scan() {
+		select {
+		case lines <- scanner.Text():
+		case <-s.done:
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		select {
+		case <-s.done:
+		default:
+			s.logger.Error("Console input closed: %v", err)
+		}
+	}
+}
+
+// HandleLine 处理一行输入：元命令、命令或普通消息
+func (s *Service) HandleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	if strings.HasPrefix(line, s.config.MetaPrefix) {
+		s.handleMeta(strings.TrimPrefix(line, s.config.MetaPrefix))
+		return
+	}
+
+	msg := s.newMessage(botc.NewBuilder().Text(line).Build())
+	s.emit(msg)
+}
+
+func (s *Service) emit(msg *MessageContext) {
+	if err := s.grb.Dispatch(msg, msg.String(), s.config.CommandPrefix); err != nil {
+		s.logger.Error("Failed to emit message event: %v", err)
+	}
+}
+
+func (s *Service) handleMeta(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	args := fields[1:]
+
+	switch strings.ToLower(fields[0]) {
+	case "help":
+		s.println(metaHelp(s.config.MetaPrefix))
+	case "user":
+		if len(args) == 0 {
+			s.println("usage: user <id>")
+			return
+		}
+		s.identMu.Lock()
+		s.sender = args[0]
+		s.identMu.Unlock()
+		s.println("sender -> ", GenUserID(args[0]))
+	case "group":
+		s.identMu.Lock()
+		if len(args) == 0 {
+			s.group = ""
+		} else {
+			s.group = args[0]
+		}
+		s.identMu.Unlock()
+		if len(args) == 0 {
+			s.println("switched to direct message")
+		} else {
+			s.println("group -> ", GenGroupID(args[0]))
+		}
+	case "dm":
+		s.identMu.Lock()
+		s.group = ""
+		s.identMu.Unlock()
+		s.println("switched to direct message")
+	case "auth":
+		if len(args) == 0 {
+			s.println("usage: auth <member|group_admin|group_owner|admin|owner>")
+			return
+		}
+		a, ok := entity.ParseAuthority(args[0])
+		if !ok {
+			s.println("unknown authority: ", args[0])
+			return
+		}
+		if a == entity.Banned {
+			s.println("platform authority cannot be banned, use the role command instead")
+			return
+		}
+		s.identMu.Lock()
+		s.authority = a
+		s.identMu.Unlock()
+		s.println("authority -> ", a)
+	case "image":
+		if len(args) == 0 {
+			s.println("usage: image <path> [caption...]")
+			return
+		}
+		if _, err := os.Stat(args[0]); err != nil {
+			s.println("image not found: ", err)
+			return
+		}
+		b := botc.NewBuilder()
+		if len(args) > 1 {
+			b.Text(strings.Join(args[1:], " "))
+		}
+		b.Append(botc.ImageElement, "[图片]", s.saveFileResource(args[0]))
+		s.emit(s.newMessage(b.Build()))
+	case "join", "leave", "poke":
+		s.emitNotice(strings.ToLower(fields[0]), args)
+	case "whoami":
+		s.identMu.RLock()
+		sender, group, authority := s.sender, s.group, s.authority
+		s.identMu.RUnlock()
+		if group == "" {
+			s.println(GenUserID(sender), " (", authority, ") in direct message")
+		} else {
+			s.println(GenUserID(sender), " (", authority, ") in ", GenGroupID(group))
+		}
+	default:
+		s.println("unknown meta command: ", fields[0])
+	}
+}
+
+func (s *Service) emitNotice(kind string, args []string) {
+	s.identMu.RLock()
+	sender, group := s.sender, s.group
+	s.identMu.RUnlock()
+
+	if group == "" && kind != "poke" {
+		s.println(kind, " requires a group, use group <id> first")
+		return
+	}
+
+	user := &entity.User{
+		Base: &entity.Base{
+			ID:   GenUserID(sender),
+			Name: sender,
+		},
+		Nickname: sender,
+	}
+
+	var payload event.Payload
+	switch kind {
+	case "join":
+		payload = &event.MemberJoin{Member: user}
+	case "leave":
+		payload = &event.MemberLeave{Member: user}
+	case "poke":
+		target := &entity.User{Base: &entity.Base{ID: s.ID(), Name: "bot"}}
+		if len(args) > 0 {
+			target = &entity.User{Base: &entity.Base{ID: GenUserID(args[0]), Name: args[0]}}
+		}
+		payload = &event.Poke{Operator: user, Target: target}
+	}
+
+	ctx := event.NewContext(s, payload)
+	ctx.Sender = &entity.Sender{User: user}
+	if group != "" {
+		ctx.Group = &entity.Group{Base: &entity.Base{ID: GenGroupID(group), Name: group}}
+		ctx.Sender.From = ctx.Group.Base
+	}
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("Failed to emit %s event: %v", ctx.Content, err)
+	}
+}
+
+// setStatus 更新登录状态，状态变化时触发 bot_online / bot_offline 事件
+func (s *Service) setStatus(status botc.LoginStatus, reason string) {
+	s.statusMu.Lock()
+	if s.status == status {
+		s.statusMu.Unlock()
+		return
+	}
+	s.status = status
+	s.statusMu.Unlock()
+
+	var ctx *event.Context
+	if status == botc.Online {
+		ctx = event.NewContext(s, &event.BotOnline{})
+	} else {
+		ctx = event.NewContext(s, &event.BotOffline{Reason: reason})
+	}
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("Failed to emit %s event: %v", ctx.Content, err)
+	}
+}
+
+func metaHelp(prefix string) string {
+	return strings.Join([]string{
+		prefix + "user <id>          切换模拟发送者",
+		prefix + "group [id]         切换到群聊，省略 id 则切换到私聊",
+		prefix + "dm                 切换到私聊",
+		prefix + "auth <level>       设置发送者权限",
+		prefix + "image <path> [...] 发送一张本地图片",
+		prefix + "join / leave       以当前身份触发入群 / 退群事件",
+		prefix + "poke [id]          以当前身份戳一戳 id，省略则戳机器人",
+		prefix + "whoami             显示当前身份",
+	}, "\n")
+}
+
+func (s *Service) prompt() {
+	s.identMu.RLock()
+	sender, group := s.sender, s.group
+	s.identMu.RUnlock()
+
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	if group == "" {
+		_, _ = fmt.Fprintf(s.Output, "%s> ", sender)
+	} else {
+		_, _ = fmt.Fprintf(s.Output, "%s@%s> ", sender, group)
+	}
+}
+
+func (s *Service) println(a ...any) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	_, _ = fmt.Fprintln(s.Output, fmt.Sprint(a...))
+}
+
+func (s *Service) saveFileResource(path string) string {
+	return s.grb.SaveResourceLink(s.ID(), urlpkg.Values{"file": {path}}.Encode())
+}
+
+func (s *Service) genMessageID() string {
+	return fmt.Sprintf("%s:msg&%d", Protocol, s.msgSeq.Add(1))
+}
+
+// --- BotContext 接口实现 ---
+
+func (s *Service) ID() string {
+	return GenUserID("bot")
+}
+
+func (s *Service) Protocol() string {
+	return Protocol
+}
+
+func (s *Service) Status() botc.LoginStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	return s.status
+}
+
+func (s *Service) NewMessageBuilder() botc.MessageBuilder {
+	return &MessageBuilder{service: s}
+}
+
+func (s *Service) SendDirectMessage(target entity.User, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	s.println("[bot -> ", target.ID, "] ", Render(elements))
+	msg := s.outboundMessage(botc.DirectMessage, nil, elements)
+	s.grb.StoreMessage(s.ID(), target.ID, msg)
+	return msg, nil
+}
+
+func (s *Service) SendGroupMessage(target entity.Group, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	s.println("[bot -> ", target.ID, "] ", Render(elements))
+	msg := s.outboundMessage(botc.GroupMessage, target.Base, elements)
+	s.grb.StoreMessage(s.ID(), target.ID, msg)
+	return msg, nil
+}
+
+// --- MessageOperator 接口实现 ---
+
+func (s *Service) Recall(msgID string) error {
+	s.println("[bot recalled ", msgID, "]")
+	return nil
+}
+
+func (s *Service) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	s.println("[bot edited ", msgID, "] ", Render(elements))
+	return &botc.BaseMessage{
+		ID:       msgID,
+		Content:  botc.ElemsToString(elements),
+		Elements: elements,
+	}, nil
+}
+
+func (s *Service) React(msgID string, emoji string) error {
+	s.println("[bot reacted ", emoji, " to ", msgID, "]")
+	return nil
+}
+
+// --- MessageHistory 接口实现 ---
+
+func (s *Service) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	return s.grb.StoredMessage(s.ID(), msgID)
+}
+
+func (s *Service) GetHistory(_ botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	return s.grb.StoredHistory(s.ID(), chatID, before, limit)
+}
+
+// --- GroupManager 接口实现 ---
+
+func (s *Service) Kick(groupID, userID string, reject bool) error {
+	s.println("[bot kicked ", userID, " from ", groupID, "]")
+	return nil
+}
+
+func (s *Service) Mute(groupID, userID string, duration time.Duration) error {
+	if duration == 0 {
+		s.println("[bot unmuted ", userID, " in ", groupID, "]")
+	} else {
+		s.println("[bot muted ", userID, " in ", groupID, " for ", duration.String(), "]")
+	}
+	return nil
+}
+
+func (s *Service) MuteAll(groupID string, enable bool) error {
+	s.println("[bot set mute all of ", groupID, " to ", enable, "]")
+	return nil
+}
+
+func (s *Service) SetAdmin(groupID, userID string, enable bool) error {
+	s.println("[bot set admin of ", userID, " in ", groupID, " to ", enable, "]")
+	return nil
+}
+
+func (s *Service) SetCard(groupID, userID, card string) error {
+	s.println("[bot set card of ", userID, " in ", groupID, " to ", card, "]")
+	return nil
+}
+
+func (s *Service) SetTitle(groupID, userID, title string) error {
+	s.println("[bot set title of ", userID, " in ", groupID, " to ", title, "]")
+	return nil
+}
+
+func (s *Service) Contacts() []entity.User {
+	return nil
+}
+
+func (s *Service) Groups() []entity.Group {
+	return nil
+}
+
+// DownloadResourceFromRefLink 控制台资源都是本地文件，直接返回 file 参数
+func (s *Service) DownloadResourceFromRefLink(refLink string) (string, error) {
+	values, err := urlpkg.ParseQuery(refLink)
+	if err != nil {
+		return "", fmt.Errorf("invalid ref link: %w", err)
+	}
+	file := values.Get("file")
+	if file == "" {
+		return "", fmt.Errorf("ref link missing file")
+	}
+	return file, nil
+}
+
+func (s *Service) outboundMessage(messageType botc.MessageType, from *entity.Base, elements []*botc.MessageElement) *botc.BaseMessage {
+	return &botc.BaseMessage{
+		MessageType: messageType,
+		ID:          s.genMessageID(),
+		Content:     botc.ElemsToString(elements),
+		Elements:    elements,
+		Sender: &entity.Sender{
+			User: &entity.User{
+				Base: &entity.Base{
+					ID:   s.ID(),
+					Name: "bot",
+				},
+			},
+			From: from,
+		},
+		Time: time.Now(),
+	}
+}
+
+func GenUserID(id string) string {
+	return fmt.Sprintf("%s:user&%s", Protocol, id)
+}
+
+func GenGroupID(id string) string {
+	return fmt.Sprintf("%s:group&%s", Protocol, id)
+}
@@ -0,0 +1,70 @@
+package GoroBot
+
+import (
+	"errors"
+	"fmt"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+var ErrNoContext = errors.New("no available bot context")
+
+// Account 选择用于发送消息的 bot 上下文。
+// account 可以是上下文 ID（指定账号）或协议名（该协议下任一账号），
+// 为空时根据 targetID 的协议前缀选择；同一协议下有多个账号时优先选择在线的账号
+func (i *Instant) Account(account string, targetID string) (botc.BotContext, error) {
+	protocol := ""
+	if info, ok := entity.ParseInfo(targetID); ok {
+		protocol = info.Protocol
+	}
+
+	i.contextsMu.RLock()
+	defer i.contextsMu.RUnlock()
+
+	if context, ok := i.contexts[account]; ok {
+		if protocol != "" && context.Protocol() != protocol {
+			return nil, fmt.Errorf("bot context %s cannot send to %s target %s", account, protocol, targetID)
+		}
+		return context, nil
+	}
+
+	if account != "" {
+		if protocol != "" && account != protocol {
+			return nil, fmt.Errorf("%w: %s for target %s", ErrNoContext, account, targetID)
+		}
+		protocol = account
+	}
+	if protocol == "" {
+		return nil, fmt.Errorf("%w: cannot resolve protocol of target %s", ErrNoContext, targetID)
+	}
+
+	contexts := i.contextsByProtocol(protocol)
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoContext, protocol)
+	}
+	for _, context := range contexts {
+		if context.Status() == botc.Online {
+			return context, nil
+		}
+	}
+	return contexts[0], nil
+}
+
+// SendDirectMessage 通过指定账号向用户发送私聊消息，account 的含义见 Account
+func (i *Instant) SendDirectMessage(account string, userID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	context, err := i.Account(account, userID)
+	if err != nil {
+		return nil, err
+	}
+	return context.SendDirectMessage(entity.User{Base: &entity.Base{ID: userID}}, elements)
+}
+
+// SendGroupMessage 通过指定账号向群组发送消息，account 的含义见 Account
+func (i *Instant) SendGroupMessage(account string, groupID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	context, err := i.Account(account, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return context.SendGroupMessage(entity.Group{Base: &entity.Base{ID: groupID}}, elements)
+}
@@ -22,6 +22,20 @@ type BaseMessage struct {
 	Time        time.Time
 }
 
+// ChatID 返回消息所在会话的 ID，群聊为群组 ID，私聊为发送者 ID
+func (m *BaseMessage) ChatID() string {
+	if m.Sender == nil {
+		return ""
+	}
+	if m.MessageType == GroupMessage && m.Sender.From != nil {
+		return m.Sender.From.ID
+	}
+	if m.Sender.User != nil && m.Sender.Base != nil {
+		return m.Sender.ID
+	}
+	return ""
+}
+
 func (m *BaseMessage) Marshall() string {
 	bytes, err := json.Marshal(m)
 	if err != nil {
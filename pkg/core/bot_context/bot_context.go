@@ -14,6 +14,7 @@ type BotContext interface {
 	SendGroupMessage(target entity.Group, elements []*MessageElement) (*BaseMessage, error)
 	Contacts() []entity.User
 	Groups() []entity.Group
+	// DownloadResourceFromRefLink 由各协议适配器实现，用于根据 refLink 下载资源到本地
 	DownloadResourceFromRefLink(refLink string) (string, error)
 }
 
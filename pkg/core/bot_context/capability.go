@@ -0,0 +1,62 @@
+package bot_context
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrUnsupported 表示适配器实现了能力接口，但所在平台不支持该操作
+var ErrUnsupported = errors.New("operation not supported by this adapter")
+
+// MessageOperator 是可选的消息操作能力，插件通过类型断言检测适配器是否支持：
+//
+//	if op, ok := ctx.BotContext().(botc.MessageOperator); ok {
+//		_ = op.Recall(msg.ID)
+//	}
+//
+// msgID 为 BaseMessage.ID
+type MessageOperator interface {
+	// Recall 撤回（删除）一条消息
+	Recall(msgID string) error
+	// Edit 编辑机器人发出的消息，返回编辑后的消息
+	Edit(msgID string, elements []*MessageElement) (*BaseMessage, error)
+	// React 对消息添加表情回应，emoji 为 Unicode 表情或平台表情 ID
+	React(msgID string, emoji string) error
+}
+
+// GroupManager 是可选的群管理能力，groupID / userID 为适配器生成的群组、用户 ID。
+// 机器人需要在群内拥有相应权限
+type GroupManager interface {
+	// Kick 将成员移出群聊，reject 为 true 时拒绝其再次申请加入
+	Kick(groupID, userID string, reject bool) error
+	// Mute 禁言成员 duration 时长，duration 为 0 时解除禁言
+	Mute(groupID, userID string, duration time.Duration) error
+	// MuteAll 开启或关闭全员禁言
+	MuteAll(groupID string, enable bool) error
+	// SetAdmin 设置或取消管理员
+	SetAdmin(groupID, userID string, enable bool) error
+	// SetCard 设置成员群名片（群昵称），card 为空时清除
+	SetCard(groupID, userID, card string) error
+	// SetTitle 设置成员专属头衔，title 为空时清除
+	SetTitle(groupID, userID, title string) error
+}
+
+// MessageHistory 是可选的消息查询能力。平台没有历史消息接口时，
+// 适配器会回退到 GoroBot 的消息存储，未启用消息存储时返回 ErrUnsupported
+type MessageHistory interface {
+	// GetMessage 根据 BaseMessage.ID 获取消息
+	GetMessage(msgID string) (*BaseMessage, error)
+	// GetHistory 获取会话中 before 之前的最多 limit 条消息，按时间从早到晚排列。
+	// chatType 为会话类型，chatID 为群组或用户 ID（部分平台两者格式相同，不能从 ID 区分），
+	// before 为空时从最新一条消息开始
+	GetHistory(chatType MessageType, chatID string, before string, limit int) ([]*BaseMessage, error)
+}
+
+// ResourceOpener 是可选的资源下载能力，适配器打开 refLink 对应资源的内容流，
+// GoroBot 边读取边保存到资源存储，不需要先把整个文件写到磁盘或读入内存。
+// ctx 取消时应中止下载，size 为内容长度，未知时为 -1
+type ResourceOpener interface {
+	OpenResourceFromRefLink(ctx context.Context, refLink string) (body io.ReadCloser, size int64, err error)
+}
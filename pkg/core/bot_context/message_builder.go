@@ -1,5 +1,7 @@
 package bot_context
 
+import "io"
+
 type MessageBuilder interface {
 	Protocol() string
 	Text(text string) MessageBuilder
@@ -8,6 +10,8 @@ type MessageBuilder interface {
 	ImageFromFile(path string) MessageBuilder
 	ImageFromUrl(url string) MessageBuilder
 	ImageFromData(data []byte) MessageBuilder
+	// ImageFromReader 从 r 读取图片，平台支持时不会把整张图片读入内存
+	ImageFromReader(r io.Reader) MessageBuilder
 	ReplyTo(msg MessageContext) (*BaseMessage, error)
 	Send(id string) (*BaseMessage, error)
 }
@@ -0,0 +1,518 @@
+package GoroBot
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// ChannelPolicy 是命令在某个作用域中的设置，未设置的字段沿用更宽泛的作用域。
+// 作用域为空时表示全局，为协议名（如 "onebot"）时表示该协议，否则为群组或私聊的会话 ID
+type ChannelPolicy struct {
+	Prefixes    []string        `json:"prefixes,omitempty"`     // 命令前缀，为 nil 时沿用上一级
+	MentionOnly *bool           `json:"mention_only,omitempty"` // 只响应 @机器人 的命令
+	Suggest     *bool           `json:"suggest,omitempty"`      // 回复未知命令的拼写建议，默认开启
+	Commands    map[string]bool `json:"commands,omitempty"`     // 顶级命令名 -> 是否启用
+	Plugins     map[string]bool `json:"plugins,omitempty"`      // 插件或服务名 -> 是否启用
+}
+
+// 用于修改会话设置的命令，不能被禁用，避免无法恢复
+const channelCommand = "channel"
+
+// ChannelPolicy 返回作用域中单独设置的策略，没有设置时返回 false
+func (i *Instant) ChannelPolicy(scope string) (ChannelPolicy, bool, error) {
+	if err := i.loadChannelPolicies(); err != nil {
+		return ChannelPolicy{}, false, err
+	}
+	i.channelPoliciesMu.RLock()
+	defer i.channelPoliciesMu.RUnlock()
+	policy, ok := i.channelPolicies[scope]
+	return policy, ok, nil
+}
+
+// ChannelScopes 返回所有单独设置了策略的作用域，按名称排序
+func (i *Instant) ChannelScopes() ([]string, error) {
+	if err := i.loadChannelPolicies(); err != nil {
+		return nil, err
+	}
+	i.channelPoliciesMu.RLock()
+	defer i.channelPoliciesMu.RUnlock()
+	scopes := make([]string, 0, len(i.channelPolicies))
+	for scope := range i.channelPolicies {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+// UpdateChannelPolicy 修改作用域的策略，连接数据库时会被持久化。
+// update 修改后策略为空时删除该作用域的设置
+func (i *Instant) UpdateChannelPolicy(scope string, update func(policy *ChannelPolicy)) error {
+	if err := i.loadChannelPolicies(); err != nil {
+		return err
+	}
+
+	i.channelPoliciesMu.Lock()
+	defer i.channelPoliciesMu.Unlock()
+
+	policy := i.channelPolicies[scope].clone()
+	update(&policy)
+	empty := policy.empty()
+
+	if i.DatabaseExist() {
+		var err error
+		if empty {
+			_, err = i.db.Exec(`DELETE FROM CHANNEL_POLICIES WHERE SCOPE = ?`, scope)
+		} else {
+			var data []byte
+			if data, err = json.Marshal(policy); err == nil {
+				_, err = i.db.Exec(`INSERT OR REPLACE INTO CHANNEL_POLICIES (SCOPE, POLICY) VALUES (?, ?)`, scope, string(data))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if empty {
+		delete(i.channelPolicies, scope)
+	} else {
+		i.channelPolicies[scope] = policy
+	}
+	return nil
+}
+
+// Dispatch 根据消息所在会话的策略判断消息是否为命令，是则派发 command 事件，否则派发 message 事件。
+// text 为用于识别命令的文本，defaultPrefixes 为适配器配置的命令前缀，会话、协议和全局都没有设置前缀时使用。
+// 前缀为空字符串时所有消息都视为命令，这样识别的命令没有匹配时不回复拼写建议
+func (i *Instant) Dispatch(msg botc.MessageContext, text string, defaultPrefixes ...string) error {
+	prefixes, mentionOnly := defaultPrefixes, false
+	if err := i.loadChannelPolicies(); err != nil {
+		i.logger.Error("load channel policies failed: %v", err)
+	} else {
+		i.channelPoliciesMu.RLock()
+		i.eachChannelPolicy(msg, func(policy ChannelPolicy) bool {
+			if policy.Prefixes != nil {
+				prefixes = policy.Prefixes
+				return false
+			}
+			return true
+		})
+		i.eachChannelPolicy(msg, func(policy ChannelPolicy) bool {
+			if policy.MentionOnly != nil {
+				mentionOnly = *policy.MentionOnly
+				return false
+			}
+			return true
+		})
+		i.channelPoliciesMu.RUnlock()
+	}
+
+	text = strings.TrimSpace(text)
+	// 私聊中无法 @机器人，视为已提及
+	mentioned := msg.Message() != nil && msg.Message().MessageType != botc.GroupMessage
+	if mention := botMention(msg); mention != nil {
+		mentioned = true
+		text = strings.TrimSpace(strings.TrimPrefix(text, strings.TrimSpace(mention.Content)))
+	}
+
+	if !mentionOnly || mentioned {
+		anyText := false
+		for _, prefix := range prefixes {
+			if prefix == "" {
+				anyText = true
+				continue
+			}
+			if !strings.HasPrefix(text, prefix) {
+				continue
+			}
+			if cmd := strings.TrimSpace(text[len(prefix):]); cmd != "" {
+				i.CommandEmit(command.NewCommandContext(msg, cmd))
+				return nil
+			}
+		}
+		// 空前缀表示所有消息都是命令，其他前缀都不匹配时才使用
+		if anyText && text != "" {
+			cmdCtx := command.NewCommandContext(msg, text)
+			cmdCtx.Implicit = true
+			i.CommandEmit(cmdCtx)
+			return nil
+		}
+	}
+
+	return i.MessageEmit(msg)
+}
+
+// CommandAvailable 判断顶级命令在消息所在会话中是否可用
+func (i *Instant) CommandAvailable(msg botc.MessageContext, name string, plugin string) bool {
+	if strings.EqualFold(name, channelCommand) {
+		return true
+	}
+	if err := i.loadChannelPolicies(); err != nil {
+		i.logger.Error("load channel policies failed: %v", err)
+		return true
+	}
+
+	i.channelPoliciesMu.RLock()
+	defer i.channelPoliciesMu.RUnlock()
+
+	enabled := true
+	i.eachChannelPolicy(msg, func(policy ChannelPolicy) bool {
+		for cmd, ok := range policy.Commands {
+			if strings.EqualFold(cmd, name) {
+				enabled = ok
+				return false
+			}
+		}
+		if plugin == "" {
+			return true
+		}
+		for p, ok := range policy.Plugins {
+			if strings.EqualFold(p, plugin) {
+				enabled = ok
+				return false
+			}
+		}
+		return true
+	})
+	return enabled
+}
+
+// SuggestEnabled 判断是否在消息所在会话中回复未知命令的拼写建议，没有设置时开启
+func (i *Instant) SuggestEnabled(msg botc.MessageContext) bool {
+	if err := i.loadChannelPolicies(); err != nil {
+		i.logger.Error("load channel policies failed: %v", err)
+		return false
+	}
+
+	i.channelPoliciesMu.RLock()
+	defer i.channelPoliciesMu.RUnlock()
+
+	enabled := true
+	i.eachChannelPolicy(msg, func(policy ChannelPolicy) bool {
+		if policy.Suggest != nil {
+			enabled = *policy.Suggest
+			return false
+		}
+		return true
+	})
+	return enabled
+}
+
+// AvailableCommandSchemas 返回在 ctx 所在会话中可用的顶级命令 Schema
+func (i *Instant) AvailableCommandSchemas(ctx *command.Context) []command.Schema {
+	return i.commands.AvailableSchemas(ctx)
+}
+
+// eachChannelPolicy 从会话、协议到全局依次遍历设置了策略的作用域，fn 返回 false 时停止。
+// 需在持有 channelPoliciesMu 时调用
+func (i *Instant) eachChannelPolicy(msg botc.MessageContext, fn func(policy ChannelPolicy) bool) {
+	for _, scope := range channelScopes(msg) {
+		if policy, ok := i.channelPolicies[scope]; ok && !fn(policy) {
+			return
+		}
+	}
+}
+
+// channelScopes 返回消息所在会话从具体到宽泛的作用域
+func channelScopes(msg botc.MessageContext) []string {
+	var scopes []string
+	if base := msg.Message(); base != nil && base.ChatID() != "" {
+		scopes = append(scopes, base.ChatID())
+	}
+	if bot := msg.BotContext(); bot != nil {
+		scopes = append(scopes, bot.Protocol())
+	}
+	return append(scopes, "")
+}
+
+// botMention 返回消息中 @机器人 的元素，没有时返回 nil
+func botMention(msg botc.MessageContext) *botc.MessageElement {
+	base, bot := msg.Message(), msg.BotContext()
+	if base == nil || bot == nil {
+		return nil
+	}
+	self := bot.ID()
+	info, ok := entity.ParseInfo(self)
+	for _, elem := range base.Elements {
+		if elem.Type != botc.MentionElement {
+			continue
+		}
+		// 部分适配器的提及元素只包含平台上的用户 ID
+		if elem.Source == self || (ok && len(info.Args) > 0 && elem.Source == info.Args[len(info.Args)-1]) {
+			return elem
+		}
+	}
+	return nil
+}
+
+func (p ChannelPolicy) clone() ChannelPolicy {
+	c := ChannelPolicy{MentionOnly: p.MentionOnly, Suggest: p.Suggest}
+	if p.Prefixes != nil {
+		c.Prefixes = append([]string{}, p.Prefixes...)
+	}
+	if p.Commands != nil {
+		c.Commands = make(map[string]bool, len(p.Commands))
+		for k, v := range p.Commands {
+			c.Commands[k] = v
+		}
+	}
+	if p.Plugins != nil {
+		c.Plugins = make(map[string]bool, len(p.Plugins))
+		for k, v := range p.Plugins {
+			c.Plugins[k] = v
+		}
+	}
+	return c
+}
+
+func (p ChannelPolicy) empty() bool {
+	return p.Prefixes == nil && p.MentionOnly == nil && p.Suggest == nil && len(p.Commands) == 0 && len(p.Plugins) == 0
+}
+
+// loadChannelPolicies 在第一次使用时从数据库加载会话策略，未连接数据库时只使用内存
+func (i *Instant) loadChannelPolicies() error {
+	i.channelPoliciesMu.Lock()
+	defer i.channelPoliciesMu.Unlock()
+
+	if i.channelPoliciesLoaded || !i.DatabaseExist() {
+		return nil
+	}
+
+	if err := ensureChannelPolicyTable(i.db); err != nil {
+		return fmt.Errorf("failed to create channel policy table: %v", err)
+	}
+
+	rows, err := i.db.Query(`SELECT SCOPE, POLICY FROM CHANNEL_POLICIES`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scope, data string
+		if err := rows.Scan(&scope, &data); err != nil {
+			return err
+		}
+		var policy ChannelPolicy
+		if err := json.Unmarshal([]byte(data), &policy); err != nil {
+			return fmt.Errorf("invalid channel policy for %q: %v", scope, err)
+		}
+		i.channelPolicies[scope] = policy
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	i.channelPoliciesLoaded = true
+	return nil
+}
+
+func ensureChannelPolicyTable(db *sql.DB) error {
+	_, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS CHANNEL_POLICIES (
+    SCOPE TEXT PRIMARY KEY NOT NULL,
+    POLICY TEXT NOT NULL
+);`)
+	return err
+}
+
+func (i *Instant) initChannelCmd() {
+	cmd := i.Command(channelCommand).
+		Description("管理命令前缀和可用命令").
+		Permission(entity.GroupAdmin)
+
+	scopeOption := func(f *command.FormatBuilder) *command.FormatBuilder {
+		return f.Option("scope", "s", command.Enum("chat", "protocol", "global"), false, "chat", "作用范围：当前会话、当前协议或全局")
+	}
+
+	scopeOption(cmd.SubCommand("show").
+		Description("查看设置")).
+		Action(func(ctx *command.Context) error {
+			scope, name, err := channelScopeOf(ctx)
+			if err != nil {
+				return err
+			}
+			policy, ok, err := i.ChannelPolicy(scope)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				_, _ = ctx.ReplyText(fmt.Sprintf("%s没有单独的设置", name))
+				return nil
+			}
+			_, _ = ctx.ReplyText(name + "的设置：" + policy.String())
+			return nil
+		})
+
+	scopeOption(cmd.SubCommand("prefix").
+		Description("设置命令前缀，可以设置多个").
+		Argument("prefix", command.String, false, "命令前缀").
+		Option("reset", "r", command.Boolean, false, "", "恢复为上一级的设置")).
+		Action(func(ctx *command.Context) error {
+			reset, _ := ctx.Bool("reset")
+			if !reset && len(ctx.Arguments) == 0 {
+				return fmt.Errorf("请指定命令前缀，或使用 --reset 恢复")
+			}
+			return i.updateChannel(ctx, func(policy *ChannelPolicy) string {
+				if reset {
+					policy.Prefixes = nil
+					return "已恢复命令前缀"
+				}
+				policy.Prefixes = append([]string{}, ctx.Arguments...)
+				return "命令前缀已设置为 " + strings.Join(ctx.Arguments, " ")
+			})
+		})
+
+	for _, enable := range []bool{false, true} {
+		sub, verb := cmd.SubCommand("disable"), "禁用"
+		if enable {
+			sub, verb = cmd.SubCommand("enable"), "启用"
+		}
+		scopeOption(sub.
+			Description(verb+"命令或插件").
+			Argument("name", command.String, true, "顶级命令名或插件名").
+			Option("plugin", "p", command.Boolean, false, "", "按插件"+verb)).
+			Action(func(ctx *command.Context) error {
+				name := ctx.KvArgs["name"]
+				plugin, _ := ctx.Bool("plugin")
+				if !plugin && strings.EqualFold(name, channelCommand) {
+					return fmt.Errorf("不能%s %s 命令", verb, channelCommand)
+				}
+				return i.updateChannel(ctx, func(policy *ChannelPolicy) string {
+					target, kind := &policy.Commands, "命令"
+					if plugin {
+						target, kind = &policy.Plugins, "插件"
+					}
+					if *target == nil {
+						*target = make(map[string]bool)
+					}
+					(*target)[name] = enable
+					return fmt.Sprintf("已%s%s %s", verb, kind, name)
+				})
+			})
+	}
+
+	switches := []struct {
+		name, desc, on, off string
+		field               func(policy *ChannelPolicy) **bool
+	}{
+		{"mention", "设置是否只响应 @机器人 的命令", "只响应 @机器人 的命令", "不需要 @机器人 也会响应命令",
+			func(policy *ChannelPolicy) **bool { return &policy.MentionOnly }},
+		{"suggest", "设置是否回复未知命令的拼写建议", "会回复未知命令的拼写建议", "不再回复未知命令",
+			func(policy *ChannelPolicy) **bool { return &policy.Suggest }},
+	}
+	for _, sw := range switches {
+		scopeOption(cmd.SubCommand(sw.name).
+			Description(sw.desc).
+			Argument("mode", command.Enum("on", "off", "inherit"), true, "on / off / inherit")).
+			Action(func(ctx *command.Context) error {
+				mode, _ := ctx.Choice("mode")
+				return i.updateChannel(ctx, func(policy *ChannelPolicy) string {
+					field := sw.field(policy)
+					switch mode {
+					case "inherit":
+						*field = nil
+						return "已恢复为上一级的设置"
+					case "on":
+						on := true
+						*field = &on
+						return sw.on
+					default:
+						off := false
+						*field = &off
+						return sw.off
+					}
+				})
+			})
+	}
+
+	scopeOption(cmd.SubCommand("reset").
+		Description("清除所有设置")).
+		Action(func(ctx *command.Context) error {
+			return i.updateChannel(ctx, func(policy *ChannelPolicy) string {
+				*policy = ChannelPolicy{}
+				return "已清除所有设置"
+			})
+		})
+
+	_, _ = cmd.Build()
+}
+
+// updateChannel 修改命令 --scope 选项对应作用域的策略，并回复 update 返回的文本
+func (i *Instant) updateChannel(ctx *command.Context, update func(policy *ChannelPolicy) string) error {
+	scope, name, err := channelScopeOf(ctx)
+	if err != nil {
+		return err
+	}
+	var reply string
+	if err := i.UpdateChannelPolicy(scope, func(policy *ChannelPolicy) {
+		reply = update(policy)
+	}); err != nil {
+		return err
+	}
+	_, _ = ctx.ReplyText(name + reply)
+	return nil
+}
+
+// channelScopeOf 返回命令 --scope 选项对应的作用域和用于回复的名称，协议和全局设置需要 Admin 权限
+func channelScopeOf(ctx *command.Context) (string, string, error) {
+	choice, _ := ctx.Choice("scope")
+	if choice != "chat" && ctx.Authority() < entity.Admin {
+		return "", "", fmt.Errorf("%w: requires %s to change %s settings", command.ErrPermissionDenied, entity.Admin, choice)
+	}
+	switch choice {
+	case "global":
+		return "", "全局", nil
+	case "protocol":
+		protocol := ctx.BotContext().Protocol()
+		return protocol, fmt.Sprintf("协议 %s ", protocol), nil
+	default:
+		chatID := ctx.Message().ChatID()
+		if chatID == "" {
+			return "", "", fmt.Errorf("无法确定当前会话")
+		}
+		return chatID, "当前会话", nil
+	}
+}
+
+func (p ChannelPolicy) String() string {
+	var b strings.Builder
+	if p.Prefixes != nil {
+		fmt.Fprintf(&b, "\n命令前缀：%s", strings.Join(p.Prefixes, " "))
+	}
+	if p.MentionOnly != nil {
+		fmt.Fprintf(&b, "\n只响应 @机器人：%t", *p.MentionOnly)
+	}
+	if p.Suggest != nil {
+		fmt.Fprintf(&b, "\n拼写建议：%t", *p.Suggest)
+	}
+	for _, group := range []struct {
+		kind  string
+		items map[string]bool
+	}{{"命令", p.Commands}, {"插件", p.Plugins}} {
+		var enabled, disabled []string
+		for name, ok := range group.items {
+			if ok {
+				enabled = append(enabled, name)
+			} else {
+				disabled = append(disabled, name)
+			}
+		}
+		sort.Strings(enabled)
+		sort.Strings(disabled)
+		if len(disabled) > 0 {
+			fmt.Fprintf(&b, "\n禁用的%s：%s", group.kind, strings.Join(disabled, " "))
+		}
+		if len(enabled) > 0 {
+			fmt.Fprintf(&b, "\n启用的%s：%s", group.kind, strings.Join(enabled, " "))
+		}
+	}
+	return b.String()
+}
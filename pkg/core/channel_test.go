@@ -0,0 +1,175 @@
+package GoroBot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+// newDispatchBot 注册回显参数的 say 命令，返回的函数派发消息并返回命令的回复，作为普通消息派发时返回空
+func newDispatchBot(t *testing.T) (*GoroBot.Instant, *testkit.Bot, func(msg *testkit.MessageContext, defaults ...string) string) {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+	if _, err := grb.Command("say").
+		Argument("text", command.String, false, "").
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("say:" + ctx.KvArgs["text"])
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 中间件在 Dispatch 中同步执行，可以据此判断消息被派发为命令还是普通消息
+	var isCommand bool
+	grb.Middleware(func(msg botc.MessageContext, next func(...GoroBot.MiddlewareCallback) error) error {
+		_, isCommand = msg.(*command.Context)
+		return next()
+	})
+
+	dispatch := func(msg *testkit.MessageContext, defaults ...string) string {
+		t.Helper()
+		bot.Reset()
+		if err := grb.Dispatch(msg, msg.Message().Content, defaults...); err != nil {
+			t.Fatal(err)
+		}
+		if !isCommand {
+			return ""
+		}
+		out, err := bot.WaitOutbound(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out[0].Text()
+	}
+	return grb, bot, dispatch
+}
+
+func setPolicy(t *testing.T, grb *GoroBot.Instant, scope string, update func(policy *GoroBot.ChannelPolicy)) {
+	t.Helper()
+	if err := grb.UpdateChannelPolicy(scope, update); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatchPrefixes(t *testing.T) {
+	grb, bot, dispatch := newDispatchBot(t)
+	group, other := testkit.GroupID("g"), testkit.GroupID("other")
+	inGroup := func(text string) *testkit.MessageContext { return bot.NewTextMessage(text).InGroup(group) }
+	inOther := func(text string) *testkit.MessageContext { return bot.NewTextMessage(text).InGroup(other) }
+
+	check := func(name string, msg *testkit.MessageContext, want string) {
+		t.Helper()
+		if got := dispatch(msg, "/"); got != want {
+			t.Errorf("%s: %q dispatched as %q, want %q", name, msg.Message().Content, got, want)
+		}
+	}
+
+	// 没有设置策略时使用适配器的默认前缀
+	check("default", inGroup("/say a"), "say:a")
+	check("default", inGroup("#say a"), "")
+	check("default", inGroup("/"), "")
+
+	// 全局前缀覆盖适配器默认值
+	setPolicy(t, grb, "", func(p *GoroBot.ChannelPolicy) { p.Prefixes = []string{"#"} })
+	check("global", inGroup("#say a"), "say:a")
+	check("global", inGroup("/say a"), "")
+
+	// 协议前缀优先于全局
+	setPolicy(t, grb, testkit.Protocol, func(p *GoroBot.ChannelPolicy) { p.Prefixes = []string{"!", "？"} })
+	check("protocol", inGroup("!say a"), "say:a")
+	check("protocol", inGroup("？say b"), "say:b")
+	check("protocol", inGroup("#say a"), "")
+
+	// 会话前缀优先于协议，只影响该会话
+	setPolicy(t, grb, group, func(p *GoroBot.ChannelPolicy) { p.Prefixes = []string{"~"} })
+	check("chat", inGroup("~say a"), "say:a")
+	check("chat", inGroup("!say a"), "")
+	check("other chat", inOther("!say a"), "say:a")
+	check("other chat", inOther("~say a"), "")
+
+	// 删除会话设置后回到协议前缀
+	setPolicy(t, grb, group, func(p *GoroBot.ChannelPolicy) { p.Prefixes = nil })
+	check("chat removed", inGroup("!say a"), "say:a")
+}
+
+func TestDispatchEmptyPrefix(t *testing.T) {
+	grb, bot, dispatch := newDispatchBot(t)
+	group := testkit.GroupID("g")
+	setPolicy(t, grb, group, func(p *GoroBot.ChannelPolicy) { p.Prefixes = []string{"", "/"} })
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"say a", "say:a"},
+		// 其他前缀优先匹配，不会把前缀当作命令名
+		{"/say b", "say:b"},
+		{"  say c  ", "say:c"},
+		// 空白消息不是命令
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := dispatch(bot.NewTextMessage(tt.text).InGroup(group)); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// 不影响其他会话
+	if got := dispatch(bot.NewTextMessage("say a").InGroup(testkit.GroupID("other")), "/"); got != "" {
+		t.Fatalf("other chat: got %q", got)
+	}
+
+	// 普通聊天不会收到拼写建议，使用前缀时仍然回复
+	bot.Reset()
+	if err := grb.Dispatch(bot.NewTextMessage("sya a").InGroup(group), "sya a"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected suggestion %q", out[0].Text())
+	}
+	if got := dispatch(bot.NewTextMessage("/sya a").InGroup(group)); !strings.Contains(got, "你是不是想输入 say") {
+		t.Fatalf("prefixed typo: got %q", got)
+	}
+}
+
+func TestDispatchMentionOnly(t *testing.T) {
+	grb, bot, dispatch := newDispatchBot(t)
+	group, other := testkit.GroupID("g"), testkit.GroupID("other")
+	on, off := true, false
+	setPolicy(t, grb, testkit.Protocol, func(p *GoroBot.ChannelPolicy) { p.MentionOnly = &on })
+	mention := func(id, text string) *testkit.MessageContext {
+		return bot.NewMessage(botc.NewBuilder().Mention(id).Text(text).Build()...)
+	}
+
+	tests := []struct {
+		name string
+		msg  *testkit.MessageContext
+		want string
+	}{
+		{"not mentioned", bot.NewTextMessage("/say a").InGroup(group), ""},
+		{"mentioned", mention(bot.ID(), " /say a").InGroup(group), "say:a"},
+		{"other user mentioned", mention(testkit.UserID("alice"), " /say a").InGroup(group), ""},
+		// 私聊中无法 @机器人，视为已提及
+		{"direct message", bot.NewTextMessage("/say a"), "say:a"},
+		{"direct message mentioned", mention(bot.ID(), " /say a"), "say:a"},
+	}
+	for _, tt := range tests {
+		if got := dispatch(tt.msg, "/"); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 会话设置覆盖协议设置
+	setPolicy(t, grb, other, func(p *GoroBot.ChannelPolicy) { p.MentionOnly = &off })
+	if got := dispatch(bot.NewTextMessage("/say a").InGroup(other), "/"); got != "say:a" {
+		t.Fatalf("mention-only disabled for the chat: got %q", got)
+	}
+}
@@ -3,10 +3,20 @@ package GoroBot
 import "github.com/Jel1ySpot/GoroBot/pkg/core/command"
 
 func (i *Instant) Command(name string) *command.FormatBuilder {
-	return command.NewCommandFormatBuilder(name, i.commands)
+	builder := command.NewCommandFormatBuilder(name, i.commands)
+	if service := i.initializingService(); service != "" {
+		builder.Plugin(service)
+	}
+	return builder
 }
 
 // GetCommandSchemas 返回所有已注册的顶级命令 Schema
 func (i *Instant) GetCommandSchemas() []command.Schema {
 	return i.commands.GetSchemas()
 }
+
+// CommandFromStruct 根据 args 的结构体标签注册命令，标签说明见 command.Struct。
+// 返回的 FormatBuilder 可以继续设置描述、权限等，最后调用 Build 注册
+func CommandFromStruct[T any](i *Instant, name string, args *T, handler func(ctx *command.Context, args *T) error) *command.FormatBuilder {
+	return command.Struct(i.Command(name), args, handler)
+}
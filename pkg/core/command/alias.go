@@ -1,18 +1,30 @@
 package command
 
-func (r *Registry) CheckAlias(ctx *Context) {
+import "time"
+
+// CheckAlias 检查消息是否匹配别名，parents 为上级命令的 Registry
+func (r *Registry) CheckAlias(ctx *Context, parents []*Registry) {
+	path := append(append([]*Registry(nil), parents...), r)
+
 	for _, alias := range r.Aliases {
 		if alias.pattern.MatchString(ctx.String()) {
 			target := ctx.Clone().setSchema(&r.Schema)
 			if alias.transform != nil {
 				target = alias.transform(target)
 			}
-			_ = r.Handler(target)
+			// 别名匹配的是普通消息，检查不通过或冷却中时不回复，也不记录
+			if check(target, path) != nil || takeCooldowns(target, path) != nil {
+				return
+			}
+			start := time.Now()
+			target.record(path, start, invoke(target, path, func() error {
+				return r.Handler(target)
+			}))
 			return
 		}
 	}
 
 	for i := range r.SubRegistries {
-		r.SubRegistries[i].CheckAlias(ctx.Clone())
+		r.SubRegistries[i].CheckAlias(ctx.Clone(), path)
 	}
 }
@@ -2,6 +2,9 @@ package command
 
 import (
 	"regexp"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 )
 
 type alias struct {
@@ -77,6 +80,44 @@ func (f *FormatBuilder) Action(handler Handler) *FormatBuilder {
 	return f
 }
 
+// Plugin 设置命令所属的插件或服务，用于按插件禁用命令
+func (f *FormatBuilder) Plugin(name string) *FormatBuilder {
+	root := f
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.registry.Plugin = name
+	return f
+}
+
+// Permission 要求发送者权限不低于 level，对所有子命令同样生效
+func (f *FormatBuilder) Permission(level entity.Authority) *FormatBuilder {
+	if f.err != nil {
+		return f
+	}
+	f.registry.Authority = max(f.registry.Authority, level)
+	return f.Check(RequireAuthority(level))
+}
+
+// Check 添加一个在处理函数之前执行的检查，对所有子命令同样生效
+func (f *FormatBuilder) Check(fn CheckFunc) *FormatBuilder {
+	if f.err != nil {
+		return f
+	}
+	f.registry.Checks = append(f.registry.Checks, fn)
+	return f
+}
+
+// Cooldown 限制命令在 duration 内只能使用一次，scope 决定按用户、群组还是全局计算，
+// 对所有子命令同样生效
+func (f *FormatBuilder) Cooldown(scope CooldownScope, duration time.Duration) *FormatBuilder {
+	if f.err != nil {
+		return f
+	}
+	f.registry.Cooldowns = append(f.registry.Cooldowns, newCooldown(scope, duration))
+	return f
+}
+
 func (f *FormatBuilder) Alias(pattern string, transform func(*Context) *Context) *FormatBuilder {
 	if f.err != nil {
 		return f
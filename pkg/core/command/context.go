@@ -12,6 +12,9 @@ type Context struct {
 	botc.MessageContext
 
 	schema    *Schema
+	resolver  AuthorityResolver
+	prompter  Prompter
+	recorder  Recorder
 	argIndex  int
 	argQueue  []string
 	raw       string
@@ -19,6 +22,13 @@ type Context struct {
 	Arguments []string
 	KvArgs    map[string]string
 	Options   map[string]string
+
+	// Implicit 表示消息没有命令前缀，只因会话设置了空前缀而被视为命令，没有命令匹配时不回复拼写建议
+	Implicit bool
+
+	values      map[string]any                // 参数和选项转换后的值
+	consumed    map[*botc.MessageElement]bool // 已被参数使用的提及、图片和文件元素
+	middlewares []Middleware                  // 命令中间件，在 System.Emit 时设置
 }
 
 func NewCommandContext(msg botc.MessageContext, text string) *Context {
@@ -36,6 +46,7 @@ func NewCommandContext(msg botc.MessageContext, text string) *Context {
 		Arguments:      []string{},
 		KvArgs:         make(map[string]string),
 		Options:        make(map[string]string),
+		values:         make(map[string]any),
 	}
 }
 
@@ -59,15 +70,32 @@ func (ctx *Context) Clone() *Context {
 		options[k] = v
 	}
 
+	values := make(map[string]any, len(ctx.values))
+	for k, v := range ctx.values {
+		values[k] = v
+	}
+
+	consumed := make(map[*botc.MessageElement]bool, len(ctx.consumed))
+	for k, v := range ctx.consumed {
+		consumed[k] = v
+	}
+
 	return &Context{
 		MessageContext: ctx.MessageContext,
 		schema:         ctx.schema,
+		resolver:       ctx.resolver,
+		prompter:       ctx.prompter,
+		recorder:       ctx.recorder,
+		middlewares:    ctx.middlewares,
 		argQueue:       argQueue,
 		raw:            ctx.raw,
 		Commands:       commands,
 		Arguments:      arguments,
 		KvArgs:         kvArgs,
 		Options:        options,
+		Implicit:       ctx.Implicit,
+		values:         values,
+		consumed:       consumed,
 	}
 }
 
@@ -78,10 +106,12 @@ func (ctx *Context) AppendArg(value string) error {
 	ctx.Arguments = append(ctx.Arguments, value)
 	if ctx.argIndex < len(ctx.schema.Arguments) {
 		arg := ctx.schema.Arguments[ctx.argIndex]
-		if !CheckInputType(value, arg.Type) {
-			return fmt.Errorf("argument '%s' expected type '%s', received '%s'", arg.Name, arg.Type, value)
+		v, err := convert(ctx, value, arg.Type)
+		if err != nil {
+			return fmt.Errorf("argument '%s' %v", arg.Name, err)
 		}
 		ctx.KvArgs[arg.Name] = value
+		ctx.values[arg.Name] = v
 	}
 	ctx.argIndex++
 	return nil
@@ -93,14 +123,19 @@ func (ctx *Context) processTokens(schema *Schema) error {
 	for k := range ctx.Options {
 		delete(ctx.Options, k)
 	}
+	for k := range ctx.KvArgs {
+		delete(ctx.KvArgs, k)
+	}
+	ctx.values = make(map[string]any)
+	ctx.consumed = nil
 
 	queue := append([]string(nil), ctx.argQueue...)
 	if len(queue) == 0 {
-		return fmt.Errorf("unmatched command")
+		return ErrUnmatchedCommand
 	}
 
 	if !schema.Match(queue[0]) {
-		return fmt.Errorf("unmatched command")
+		return ErrUnmatchedCommand
 	}
 
 	ctx.Commands = append(ctx.Commands, schema.Name)
@@ -131,35 +166,41 @@ func (ctx *Context) processTokens(schema *Schema) error {
 		if strings.HasPrefix(token, "--") {
 			queue = queue[1:]
 			key, value, err := parseLongOption(token, &queue, currentSchema)
+			if err == nil {
+				err = ctx.setOption(currentSchema, key, value)
+			}
 			if err != nil {
-				return err
+				return ctx.parseError(err)
 			}
-			ctx.Options[key] = value
 			continue
 		}
 
-		if strings.HasPrefix(token, "-") {
+		if strings.HasPrefix(token, "-") && !isNegativeNumber(token, currentSchema) {
 			queue = queue[1:]
 			options, err := parseShortOption(token, &queue, currentSchema)
 			if err != nil {
-				return err
+				return ctx.parseError(err)
 			}
 			for k, v := range options {
-				ctx.Options[k] = v
+				if err := ctx.setOption(currentSchema, k, v); err != nil {
+					return ctx.parseError(err)
+				}
 			}
 			continue
 		}
 
 		err := ctx.AppendArg(token)
 		if err != nil {
-			return err
+			return ctx.parseError(err)
 		}
 		queue = queue[1:]
 	}
 
+	ctx.fillAttachments(currentSchema)
+
 	for i := ctx.argIndex; i < len(currentSchema.Arguments); i++ {
 		if currentSchema.Arguments[i].Required {
-			return fmt.Errorf("argument '%s' is required", currentSchema.Arguments[i].Name)
+			return ctx.parseError(fmt.Errorf("argument '%s' is required", currentSchema.Arguments[i].Name))
 		}
 	}
 
@@ -168,22 +209,57 @@ func (ctx *Context) processTokens(schema *Schema) error {
 			continue
 		}
 		if opt.Required {
-			return fmt.Errorf("option %s is required", opt.Name)
+			return ctx.parseError(fmt.Errorf("option %s is required", opt.Name))
 		}
 		if opt.Default != "" {
-			ctx.Options[opt.Name] = opt.Default
+			if err := ctx.setOption(currentSchema, opt.Name, opt.Default); err != nil {
+				return ctx.parseError(err)
+			}
 		}
 	}
 
 	for k := range ctx.Options {
 		if _, ok := currentSchema.getOption(k); !ok {
-			return fmt.Errorf("option '%s' not found", k)
+			return ctx.parseError(fmt.Errorf("option '%s' not found", k))
 		}
 	}
 
 	return nil
 }
 
+// isNegativeNumber 判断以 "-" 开头的参数是否为负数，与短选项冲突时视为选项
+func isNegativeNumber(token string, schema *Schema) bool {
+	if len(token) < 2 || (token[1] != '.' && (token[1] < '0' || token[1] > '9')) {
+		return false
+	}
+	_, isOption := schema.getOption(token[1:2])
+	return !isOption
+}
+
+// setOption 校验选项值并保存原始文本和转换后的值
+func (ctx *Context) setOption(schema *Schema, name string, value string) error {
+	opt, ok := schema.getOption(name)
+	if !ok {
+		return fmt.Errorf("option '%s' not found", name)
+	}
+	v, err := convert(ctx, value, opt.Type)
+	if err != nil {
+		return fmt.Errorf("option '%s' %v", name, err)
+	}
+	ctx.Options[opt.Name] = value
+	ctx.values[opt.Name] = v
+	return nil
+}
+
+// parseError 将解析错误与当前匹配到的命令关联，便于回复对应的用法
+func (ctx *Context) parseError(err error) error {
+	return &ParseError{
+		Commands: append([]string(nil), ctx.Commands...),
+		Schema:   ctx.schema,
+		Err:      err,
+	}
+}
+
 func parseLongOption(token string, argQueue *[]string, schema *Schema) (string, string, error) {
 	key := token
 	value := ""
@@ -196,7 +272,7 @@ func parseLongOption(token string, argQueue *[]string, schema *Schema) (string,
 
 	opt, ok := schema.getOption(key)
 	if !ok {
-		return "", "", fmt.Errorf("option '%s' not found", key)
+		return "", "", unknownOption(key, schema)
 	}
 
 	if opt.Type == Boolean {
@@ -211,10 +287,6 @@ func parseLongOption(token string, argQueue *[]string, schema *Schema) (string,
 		*argQueue = (*argQueue)[1:]
 	}
 
-	if !CheckInputType(value, opt.Type) {
-		return "", "", fmt.Errorf("option '%s' expected type '%s', received '%s'", key, opt.Type, value)
-	}
-
 	return opt.Name, value, nil
 }
 
@@ -228,7 +300,7 @@ func parseShortOption(token string, argQueue *[]string, schema *Schema) (map[str
 
 		opt, ok := schema.getOption(key)
 		if !ok {
-			return nil, fmt.Errorf("option '%s' not found", key)
+			return nil, unknownOption(key, schema)
 		}
 
 		if opt.Type == Boolean {
@@ -240,12 +312,7 @@ func parseShortOption(token string, argQueue *[]string, schema *Schema) (map[str
 			return nil, fmt.Errorf("option '%s' expected at least one argument", key)
 		}
 
-		value := (*argQueue)[0]
-		if !CheckInputType(value, opt.Type) {
-			return nil, fmt.Errorf("option '%s' expected type '%s', received '%s'", key, opt.Type, value)
-		}
-
-		options[opt.Name] = value
+		options[opt.Name] = (*argQueue)[0]
 		*argQueue = (*argQueue)[1:]
 	}
 
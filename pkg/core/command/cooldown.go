@@ -0,0 +1,164 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CooldownScope int
+
+const (
+	PerUser  CooldownScope = iota // 每个用户单独计算
+	PerGroup                      // 每个群组单独计算，私聊按用户计算
+	Global                        // 所有会话共用
+)
+
+func (s CooldownScope) String() string {
+	switch s {
+	case PerUser:
+		return "user"
+	case PerGroup:
+		return "group"
+	case Global:
+		return "global"
+	default:
+		return "unknown"
+	}
+}
+
+// CooldownError 表示命令仍在冷却中
+type CooldownError struct {
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("命令冷却中，请在 %d 秒后重试", int(math.Ceil(e.Remaining.Seconds())))
+}
+
+// CooldownStatus 是一条正在冷却的记录
+type CooldownStatus struct {
+	Command   string
+	Scope     CooldownScope
+	Key       string
+	Remaining time.Duration
+}
+
+type cooldown struct {
+	scope    CooldownScope
+	duration time.Duration
+	mu       sync.Mutex
+	last     map[string]time.Time
+}
+
+func newCooldown(scope CooldownScope, duration time.Duration) *cooldown {
+	return &cooldown{
+		scope:    scope,
+		duration: duration,
+		last:     make(map[string]time.Time),
+	}
+}
+
+func (c *cooldown) key(ctx *Context) string {
+	switch c.scope {
+	case PerUser:
+		return ctx.SenderID()
+	case PerGroup:
+		if msg := ctx.Message(); msg != nil {
+			return msg.ChatID()
+		}
+		return ctx.SenderID()
+	default:
+		return ""
+	}
+}
+
+// remaining 返回 key 剩余的冷却时间，需在持有 mu 时调用
+func (c *cooldown) remaining(key string, now time.Time) time.Duration {
+	last, ok := c.last[key]
+	if !ok {
+		return 0
+	}
+	return max(c.duration-now.Sub(last), 0)
+}
+
+// prune 删除已结束的冷却记录，需在持有 mu 时调用
+func (c *cooldown) prune(now time.Time) {
+	for key, last := range c.last {
+		if now.Sub(last) >= c.duration {
+			delete(c.last, key)
+		}
+	}
+}
+
+// takeCooldowns 检查路径上的所有冷却，全部通过后才记录本次使用
+func takeCooldowns(ctx *Context, path []*Registry) error {
+	now := time.Now()
+	for _, reg := range path {
+		for _, c := range reg.Cooldowns {
+			c.mu.Lock()
+			remaining := c.remaining(c.key(ctx), now)
+			c.mu.Unlock()
+			if remaining > 0 {
+				return &CooldownError{Remaining: remaining}
+			}
+		}
+	}
+	for _, reg := range path {
+		for _, c := range reg.Cooldowns {
+			c.mu.Lock()
+			c.prune(now)
+			c.last[c.key(ctx)] = now
+			c.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Cooldowns 返回所有命令中正在冷却的记录
+func (s *System) Cooldowns() []CooldownStatus {
+	s.mu.RLock()
+	registries := make([]*Registry, 0, len(s.commands))
+	for _, reg := range s.commands {
+		registries = append(registries, reg)
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	var statuses []CooldownStatus
+	for _, reg := range registries {
+		statuses = reg.appendCooldowns(statuses, nil, now)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Command != statuses[j].Command {
+			return statuses[i].Command < statuses[j].Command
+		}
+		return statuses[i].Key < statuses[j].Key
+	})
+	return statuses
+}
+
+func (r *Registry) appendCooldowns(statuses []CooldownStatus, parents []string, now time.Time) []CooldownStatus {
+	path := append(append([]string(nil), parents...), r.Schema.Name)
+	for _, c := range r.Cooldowns {
+		c.mu.Lock()
+		for key := range c.last {
+			if remaining := c.remaining(key, now); remaining > 0 {
+				statuses = append(statuses, CooldownStatus{
+					Command:   strings.Join(path, " "),
+					Scope:     c.scope,
+					Key:       key,
+					Remaining: remaining,
+				})
+			}
+		}
+		c.mu.Unlock()
+	}
+	for i := range r.SubRegistries {
+		statuses = r.SubRegistries[i].appendCooldowns(statuses, path, now)
+	}
+	return statuses
+}
@@ -0,0 +1,78 @@
+package command_test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestCooldownPerUser(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	var calls atomic.Int32
+	if _, err := grb.Command("ping").
+		Cooldown(command.PerUser, 100*time.Millisecond).
+		Action(func(ctx *command.Context) error {
+			calls.Add(1)
+			_, _ = ctx.ReplyText("pong")
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(user string) string {
+		t.Helper()
+		return bot.RunCommand(t, bot.NewTextMessage("/ping").From(testkit.UserID(user)), "ping")
+	}
+
+	if got := send("alice"); got != "pong" {
+		t.Fatalf("first call: got %q", got)
+	}
+	if got := send("alice"); !strings.Contains(got, "冷却中") {
+		t.Fatalf("second call within cooldown: got %q", got)
+	}
+	// 其他用户不受影响
+	if got := send("bob"); got != "pong" {
+		t.Fatalf("other user: got %q", got)
+	}
+
+	time.Sleep(120 * time.Millisecond)
+	if got := send("alice"); got != "pong" {
+		t.Fatalf("call after cooldown: got %q", got)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("handler called %d times, want 3", n)
+	}
+}
+
+func TestCooldownAfterChecks(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := grb.Command("admin").
+		Permission(entity.Admin).
+		Cooldown(command.Global, time.Minute).
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("ok")
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 权限不足的调用不会开始冷却
+	if got := bot.RunCommand(t, bot.NewTextMessage("/admin"), "admin"); !strings.Contains(got, "permission denied") {
+		t.Fatalf("member call: got %q", got)
+	}
+	if got := bot.RunCommand(t, bot.NewTextMessage("/admin").WithAuthority(entity.Admin), "admin"); got != "ok" {
+		t.Fatalf("admin call: got %q", got)
+	}
+	if got := bot.RunCommand(t, bot.NewTextMessage("/admin").WithAuthority(entity.Admin), "admin"); !strings.Contains(got, "冷却中") {
+		t.Fatalf("second admin call: got %q", got)
+	}
+}
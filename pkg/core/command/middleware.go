@@ -0,0 +1,81 @@
+package command
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Invocation 是一次已经通过解析、检查和冷却，即将执行处理函数的命令调用
+type Invocation struct {
+	Context *Context
+	Path    []*Registry // 从顶级命令到目标命令的 Registry
+}
+
+// Registry 返回目标命令的 Registry
+func (inv *Invocation) Registry() *Registry {
+	return inv.Path[len(inv.Path)-1]
+}
+
+// Middleware 包装命令处理函数的执行。调用 next 进入下一个中间件或处理函数，
+// 不调用时处理函数不会执行；返回的错误会代替处理函数的错误回复给发送者，返回 nil 时不回复。
+// 冷却在中间件之前计入，被中间件拦截的调用同样需要等待冷却
+type Middleware = func(inv *Invocation, next func() error) error
+
+// ErrNextCalled 是中间件重复调用 next 时返回的错误
+var ErrNextCalled = errors.New("middleware called next more than once")
+
+type middlewareEntry struct {
+	id string
+	fn Middleware
+}
+
+// Use 注册命令中间件，按注册顺序执行，返回注销函数
+func (s *System) Use(middleware Middleware) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := uuid.New().String()
+	s.middlewares = append(s.middlewares, middlewareEntry{id: id, fn: middleware})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, entry := range s.middlewares {
+			if entry.id == id {
+				s.middlewares = append(s.middlewares[:i:i], s.middlewares[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// invoke 依次经过所有中间件后执行 path 末尾命令的处理函数
+func invoke(ctx *Context, path []*Registry, handler func() error) error {
+	inv := &Invocation{Context: ctx, Path: path}
+	middlewares := ctx.middlewares
+
+	// 每一层中间件拿到各自的 next，重复调用时不会跳过后面的中间件
+	var next func(index int) func() error
+	next = func(index int) func() error {
+		called := false
+		return func() error {
+			if called {
+				return ErrNextCalled
+			}
+			called = true
+			if index >= len(middlewares) {
+				return handler()
+			}
+			return middlewares[index](inv, next(index+1))
+		}
+	}
+	return next(0)()
+}
+
+// snapshot 返回当前注册的中间件，需在持有 s.mu 时调用
+func (s *System) snapshot() []Middleware {
+	middlewares := make([]Middleware, 0, len(s.middlewares))
+	for _, entry := range s.middlewares {
+		middlewares = append(middlewares, entry.fn)
+	}
+	return middlewares
+}
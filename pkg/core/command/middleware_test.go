@@ -0,0 +1,169 @@
+package command_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+	if _, err := grb.Command("secret").
+		Cooldown(command.PerUser, time.Hour).
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("handler")
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	remove := grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		if inv.Registry().Schema.Name == "secret" {
+			_, _ = inv.Context.ReplyText("blocked")
+			return nil
+		}
+		return next()
+	})
+
+	if got := bot.RunCommand(t, bot.NewTextMessage("/secret"), "secret"); got != "blocked" {
+		t.Fatalf("got %q", got)
+	}
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 1 {
+		t.Fatalf("handler ran after the middleware returned: %d replies", len(out))
+	}
+
+	// 冷却在中间件之前计入，被拦截的调用同样进入冷却
+	remove()
+	if got := bot.RunCommand(t, bot.NewTextMessage("/secret"), "secret"); !strings.Contains(got, "冷却") {
+		t.Fatalf("got %q, want a cooldown error", got)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+	if _, err := grb.Command("fail").
+		Action(func(ctx *command.Context) error {
+			return errors.New("boom")
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	remove := grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		if err := next(); err != nil {
+			return fmt.Errorf("%s failed: %v", inv.Registry().Schema.Name, err)
+		}
+		return nil
+	})
+	if got := bot.RunCommand(t, bot.NewTextMessage("/fail"), "fail"); got != "fail failed: boom" {
+		t.Fatalf("got %q", got)
+	}
+	remove()
+
+	// 中间件返回 nil 时不回复
+	grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		_ = next()
+		return nil
+	})
+	bot.Reset()
+	bot.EmitCommand(bot.NewTextMessage("/fail"), "fail")
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected reply %q", out[0].Text())
+	}
+}
+
+func TestMiddlewareNextCalledTwice(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	called := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, name)
+	}
+	if _, err := grb.Command("once").
+		Action(func(ctx *command.Context) error {
+			called("handler")
+			_, _ = ctx.ReplyText("done")
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	again := make(chan error, 1)
+	grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		called("outer")
+		err := next()
+		again <- next()
+		return err
+	})
+	grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		called("inner")
+		return next()
+	})
+
+	if got := bot.RunCommand(t, bot.NewTextMessage("/once"), "once"); got != "done" {
+		t.Fatalf("got %q", got)
+	}
+	if err := <-again; !errors.Is(err, command.ErrNextCalled) {
+		t.Fatalf("second next returned %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := fmt.Sprint(calls); got != "[outer inner handler]" {
+		t.Fatalf("got calls %s", got)
+	}
+}
+
+func TestMiddlewareAlias(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+	cmd := grb.Command("team")
+	cmd.SubCommand("roll").
+		Alias(`^掷骰子$`, nil).
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("rolled")
+			return nil
+		})
+	if _, err := cmd.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := make(chan string, 1)
+	grb.CommandMiddleware(func(inv *command.Invocation, next func() error) error {
+		names := make([]string, len(inv.Path))
+		for n, reg := range inv.Path {
+			names[n] = reg.Schema.Name
+		}
+		paths <- strings.Join(names, " ")
+		return next()
+	})
+
+	if err := bot.Emit(bot.NewTextMessage("掷骰子")); err != nil {
+		t.Fatal(err)
+	}
+	out, err := bot.WaitOutbound(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out[0].Text(); got != "rolled" {
+		t.Fatalf("got %q", got)
+	}
+	if got := <-paths; got != "team roll" {
+		t.Fatalf("middleware saw path %q", got)
+	}
+}
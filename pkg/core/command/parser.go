@@ -1,14 +1,29 @@
 package command
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 )
 
-var inputTypes map[InputType]*regexp.Regexp
+// Parser 将参数文本转换为 Go 值，params 为类型参数，例如 IntRange(1, 6) 的 ["1", "6"]。
+// ctx 在 CheckInputType 中为 nil，依赖消息内容的类型此时应返回错误
+type Parser = func(ctx *Context, value string, params []string) (any, error)
+
+type inputType struct {
+	checker *regexp.Regexp
+	parser  Parser
+}
+
+var (
+	inputTypes   map[InputType]inputType
+	inputTypesMu sync.RWMutex
+)
 
 const (
-	Number  InputType = "number"
+	Number  InputType = "number" // 非负整数，值为 int
 	String  InputType = "string"
 	Boolean InputType = "bool"
 )
@@ -40,20 +55,72 @@ func GetBool(value string) bool {
 }
 
 func CheckInputType(value string, inputType InputType) bool {
-	if inputTypes[inputType] != nil {
-		return inputTypes[inputType].MatchString(value)
-	}
-	return false
+	_, err := convert(nil, value, inputType)
+	return err == nil
 }
 
+// RegisterInputType 注册只用正则表达式校验的类型，值为原始文本
 func RegisterInputType(typeName InputType, checker *regexp.Regexp) {
-	inputTypes[typeName] = checker
+	inputTypesMu.Lock()
+	defer inputTypesMu.Unlock()
+	inputTypes[typeName] = inputType{checker: checker}
+}
+
+// RegisterParser 注册带转换函数的类型，checker 可以为 nil。
+// 带参数的类型写作 "name(a,b)"，注册时只使用 name
+func RegisterParser(typeName InputType, checker *regexp.Regexp, parser Parser) {
+	inputTypesMu.Lock()
+	defer inputTypesMu.Unlock()
+	inputTypes[typeName] = inputType{checker: checker, parser: parser}
+}
+
+// splitType 将 "name(a,b)" 拆分为类型名和参数，枚举使用 "|" 分隔参数
+func splitType(t InputType) (InputType, []string) {
+	s := string(t)
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return t, nil
+	}
+	name, params := s[:open], s[open+1:len(s)-1]
+	sep := ","
+	if strings.Contains(params, "|") {
+		sep = "|"
+	}
+	return InputType(name), strings.Split(params, sep)
+}
+
+// convert 校验参数文本并转换为 Go 值
+func convert(ctx *Context, value string, t InputType) (any, error) {
+	name, params := splitType(t)
+
+	inputTypesMu.RLock()
+	it, ok := inputTypes[name]
+	inputTypesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown type '%s'", t)
+	}
+
+	if it.checker != nil && !it.checker.MatchString(value) {
+		return nil, fmt.Errorf("expected type '%s', received '%s'", t, value)
+	}
+	if it.parser == nil {
+		return value, nil
+	}
+	result, err := it.parser(ctx, value, params)
+	if err != nil {
+		return nil, fmt.Errorf("expected type '%s', received '%s': %v", t, value, err)
+	}
+	return result, nil
 }
 
 func init() {
-	inputTypes = make(map[InputType]*regexp.Regexp)
+	inputTypes = make(map[InputType]inputType)
 
-	RegisterInputType(Number, regexp.MustCompile("^[0-9]+$"))
+	RegisterParser(Number, regexp.MustCompile("^[0-9]+$"), parseInt)
 	RegisterInputType(String, regexp.MustCompile("^.+$"))
-	RegisterInputType(Boolean, regexp.MustCompile("^(?:[TFtfYNyn]|on|ON|off|OFF|Yes|YES|No|NO|(?:T|t)rue|TRUE|(?:F|f)alse|FALSE)$"))
+	RegisterParser(Boolean, regexp.MustCompile("^(?:[TFtfYNyn]|on|ON|off|OFF|Yes|YES|No|NO|(?:T|t)rue|TRUE|(?:F|f)alse|FALSE)$"), func(_ *Context, value string, _ []string) (any, error) {
+		return GetBool(value), nil
+	})
+
+	registerBuiltinTypes()
 }
@@ -0,0 +1,46 @@
+package command_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestParseOptions(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := grb.Command("opt").
+		Option("count", "c", command.Number, false, "1", "").
+		Option("verbose", "v", command.Boolean, false, "", "").
+		Argument("text", command.String, false, "").
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText(fmt.Sprintf("%s %s %s", ctx.Options["count"], ctx.Options["verbose"], ctx.KvArgs["text"]))
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"opt", "1  "},
+		{"opt --count 3", "3  "},
+		{"opt --count=3 hi", "3  hi"},
+		{"opt --COUNT 3", "3  "},
+		{"opt -c 3", "3  "},
+		{"opt --verbose hi", "1 true hi"},
+		{"opt -vc 3 hi", "3 true hi"},
+		{"opt --cnt 3", "option '--cnt' not found"},
+		{"opt -x", "option '-x' not found"},
+	}
+	for _, tt := range tests {
+		if got := bot.RunCommand(t, bot.NewTextMessage("/"+tt.input), tt.input); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
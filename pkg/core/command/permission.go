@@ -0,0 +1,61 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// CheckFunc 在命令处理函数执行前调用，返回错误时命令不会执行，错误会回复给发送者
+type CheckFunc = func(ctx *Context) error
+
+// AuthorityResolver 返回发送者在当前会话中的实际权限
+type AuthorityResolver = func(ctx *Context) entity.Authority
+
+// RequireAuthority 返回要求发送者权限不低于 level 的 CheckFunc
+func RequireAuthority(level entity.Authority) CheckFunc {
+	return func(ctx *Context) error {
+		if authority := ctx.Authority(); authority < level {
+			return fmt.Errorf("%w: requires %s, got %s", ErrPermissionDenied, level, authority)
+		}
+		return nil
+	}
+}
+
+// Authority 返回发送者的权限，未设置 AuthorityResolver 时使用消息中的发送者权限，
+// 适配器没有设置时为 Member
+func (ctx *Context) Authority() entity.Authority {
+	if ctx.resolver != nil {
+		return ctx.resolver(ctx)
+	}
+	if msg := ctx.Message(); msg != nil && msg.Sender != nil && msg.Sender.User != nil {
+		return max(msg.Sender.Authority, entity.Member)
+	}
+	return entity.Member
+}
+
+// SetAuthorityResolver 设置权限检查使用的 AuthorityResolver
+func (s *System) SetAuthorityResolver(resolver AuthorityResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolver = resolver
+}
+
+// check 依次执行从顶级命令到目标命令路径上的所有检查。
+// Banned 的发送者不能使用任何命令，包括没有设置 Permission 的命令
+func check(ctx *Context, path []*Registry) error {
+	if ctx.Authority() == entity.Banned {
+		return fmt.Errorf("%w: banned", ErrPermissionDenied)
+	}
+	for _, reg := range path {
+		for _, fn := range reg.Checks {
+			if err := fn(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"errors"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+// Prompter 等待发送者在同一会话中的下一条消息，由 GoroBot 实例提供
+type Prompter = func(ctx botc.MessageContext, timeout time.Duration, filter func(msg botc.MessageContext) bool) (botc.MessageContext, error)
+
+// Prompt 等待发送者在同一会话中发送的下一条消息，该消息不会再触发其他命令。
+// timeout 不大于 0 时不超时，filter 为 nil 时接受任何消息
+func (ctx *Context) Prompt(timeout time.Duration, filter func(msg botc.MessageContext) bool) (botc.MessageContext, error) {
+	if ctx.prompter == nil {
+		return nil, errors.New("prompt is not available")
+	}
+	return ctx.prompter(ctx.MessageContext, timeout, filter)
+}
+
+// SetPrompter 设置 Context.Prompt 使用的 Prompter
+func (s *System) SetPrompter(prompter Prompter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompter = prompter
+}
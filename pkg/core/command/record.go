@@ -0,0 +1,59 @@
+package command
+
+import (
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// Record 是一次命令调用的记录，Err 为 nil 表示执行成功
+type Record struct {
+	Commands   []string
+	BotID      string
+	Protocol   string
+	ChatID     string
+	SenderID   string
+	Privileged bool // 命令路径上设置了高于 Member 的权限要求
+	Time       time.Time
+	Duration   time.Duration
+	Err        error
+}
+
+// Recorder 在每次命令调用结束后执行，包括解析失败、检查不通过和冷却中的调用
+type Recorder = func(record Record)
+
+// SetRecorder 设置命令调用的 Recorder
+func (s *System) SetRecorder(recorder Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = recorder
+}
+
+// record 记录一次对 path 的调用，start 为开始处理的时间
+func (ctx *Context) record(path []*Registry, start time.Time, err error) {
+	if ctx.recorder == nil || len(path) == 0 {
+		return
+	}
+
+	record := Record{
+		Time:     start,
+		Duration: time.Since(start),
+		Err:      err,
+		SenderID: ctx.SenderID(),
+	}
+	for _, reg := range path {
+		record.Commands = append(record.Commands, reg.Schema.Name)
+		if reg.Authority > entity.Member {
+			record.Privileged = true
+		}
+	}
+	if bot := ctx.BotContext(); bot != nil {
+		record.BotID = bot.ID()
+		record.Protocol = bot.Protocol()
+	}
+	if msg := ctx.Message(); msg != nil {
+		record.ChatID = msg.ChatID()
+	}
+
+	ctx.recorder(record)
+}
@@ -1,10 +1,16 @@
 package command
 
+import "github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+
 type Registry struct {
 	Name          string
 	Schema        Schema
 	Handler       Handler
 	Aliases       []alias
+	Checks        []CheckFunc
+	Authority     entity.Authority // Permission 设置的最高权限要求
+	Plugin        string           // 注册命令的插件或服务名
+	Cooldowns     []*cooldown
 	SubRegistries []Registry
 }
 
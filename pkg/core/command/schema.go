@@ -59,9 +59,11 @@ func (s *Schema) AddSubCommandSchema(schema Schema) *Schema {
 	return s
 }
 
+// getOption 根据选项名查找选项，"--name" / "-n" 形式的前缀会被忽略
 func (s *Schema) getOption(key string) (*SchemaOption, bool) {
+	key = strings.TrimLeft(key, "-")
 	for _, option := range s.Options {
-		if strings.ToLower(key) == strings.ToLower(option.Name) || strings.ToLower(key) == strings.ToLower(option.ShortName) {
+		if strings.EqualFold(key, strings.TrimLeft(option.Name, "-")) || strings.EqualFold(key, strings.TrimLeft(option.ShortName, "-")) {
 			return &option, true
 		}
 	}
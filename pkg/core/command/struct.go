@@ -0,0 +1,310 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// structField 记录结构体字段与参数或选项的对应关系
+type structField struct {
+	index []int
+	name  string
+	rest  bool // []string 类型的参数，接收从该位置开始的所有参数
+	arg   int  // 参数位置，选项为 -1
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	userType     = reflect.TypeOf(entity.User{})
+	elementType  = reflect.TypeOf(&botc.MessageElement{})
+)
+
+// Struct 根据结构体标签为 f 声明参数和选项，并将 handler 设为处理函数。
+// T 必须是结构体，args 的字段值作为每次调用的初始值，为 nil 时使用零值；
+// 调用 handler 前会将解析结果填入新的 *T。
+//
+// 支持的标签：
+//   - arg:"0,required"    第 0 个参数，required 表示必填
+//   - opt:"--count,-c"    长选项和短选项，可追加 required
+//   - name:"count"        参数名，默认为字段名的 snake_case 形式，选项默认使用长选项名
+//   - type:"int(1,6)"     参数类型，默认根据字段类型推断
+//   - default:"1"         选项的默认值
+//   - help:"..."          帮助文本
+//
+// 最后一个参数可以是 []string，接收剩余的所有参数
+func Struct[T any](f *FormatBuilder, args *T, handler func(ctx *Context, args *T) error) *FormatBuilder {
+	if f.err != nil {
+		return f
+	}
+
+	structType := reflect.TypeFor[T]()
+	if structType.Kind() != reflect.Struct {
+		f.err = fmt.Errorf("command %s: args must be a pointer to struct, got *%s", f.registry.Schema.Name, structType)
+		return f
+	}
+	if handler == nil {
+		f.err = fmt.Errorf("command %s: handler is nil", f.registry.Schema.Name)
+		return f
+	}
+
+	fields, arguments, options, err := parseStruct(structType)
+	if err != nil {
+		f.err = fmt.Errorf("command %s: %w", f.registry.Schema.Name, err)
+		return f
+	}
+	f.registry.Schema.Arguments = append(f.registry.Schema.Arguments, arguments...)
+	f.registry.Schema.Options = append(f.registry.Schema.Options, options...)
+
+	var initial T
+	if args != nil {
+		initial = *args
+	}
+	return f.Action(func(ctx *Context) error {
+		target := initial
+		if err := ctx.fillStruct(reflect.ValueOf(&target).Elem(), fields); err != nil {
+			return err
+		}
+		return handler(ctx, &target)
+	})
+}
+
+func parseStruct(t reflect.Type) ([]structField, []SchemaArgument, []SchemaOption, error) {
+	var (
+		fields    []structField
+		arguments []SchemaArgument
+		options   []SchemaOption
+		positions = make(map[int]*structField)
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		argTag, isArg := field.Tag.Lookup("arg")
+		optTag, isOpt := field.Tag.Lookup("opt")
+		if !isArg && !isOpt {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, nil, nil, fmt.Errorf("field %s must be exported", field.Name)
+		}
+		if isArg && isOpt {
+			return nil, nil, nil, fmt.Errorf("field %s cannot be both argument and option", field.Name)
+		}
+
+		rest := isArg && field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String
+		inputType := InputType(field.Tag.Get("type"))
+		if inputType == "" {
+			elem := field.Type
+			if rest {
+				elem = elem.Elem()
+			}
+			var ok bool
+			if inputType, ok = inferType(elem); !ok {
+				return nil, nil, nil, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+			}
+		}
+
+		help := field.Tag.Get("help")
+		name := field.Tag.Get("name")
+
+		if isArg {
+			parts := strings.Split(argTag, ",")
+			pos, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+			if err != nil || pos < 0 {
+				return nil, nil, nil, fmt.Errorf("field %s: invalid argument position '%s'", field.Name, parts[0])
+			}
+			if name == "" {
+				name = snakeCase(field.Name)
+			}
+			if _, ok := positions[pos]; ok {
+				return nil, nil, nil, fmt.Errorf("field %s: argument position %d already used", field.Name, pos)
+			}
+			positions[pos] = &structField{index: field.Index, name: name, rest: rest, arg: pos}
+			arguments = append(arguments, SchemaArgument{
+				Name:     name,
+				Type:     inputType,
+				Help:     help,
+				Required: hasFlag(parts[1:], "required"),
+			})
+			continue
+		}
+
+		var long, short string
+		parts := strings.Split(optTag, ",")
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			switch {
+			case strings.HasPrefix(part, "--"):
+				long = strings.TrimPrefix(part, "--")
+			case strings.HasPrefix(part, "-"):
+				short = strings.TrimPrefix(part, "-")
+			}
+		}
+		if name == "" {
+			name = long
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		options = append(options, SchemaOption{
+			Name:      name,
+			ShortName: short,
+			Type:      inputType,
+			Help:      help,
+			Default:   field.Tag.Get("default"),
+			Required:  hasFlag(parts, "required"),
+		})
+		fields = append(fields, structField{index: field.Index, name: name, arg: -1})
+	}
+
+	// 参数按位置排序，位置必须从 0 开始连续
+	sort.SliceStable(arguments, func(a, b int) bool {
+		return argPosition(positions, arguments[a].Name) < argPosition(positions, arguments[b].Name)
+	})
+	for i := range arguments {
+		field, ok := positions[i]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("argument positions must start at 0 and be continuous, missing %d", i)
+		}
+		if field.rest && i != len(arguments)-1 {
+			return nil, nil, nil, fmt.Errorf("argument %s: []string argument must be the last one", field.name)
+		}
+		fields = append(fields, *field)
+	}
+
+	return fields, arguments, options, nil
+}
+
+// inferType 根据字段类型推断参数类型
+func inferType(t reflect.Type) (InputType, bool) {
+	switch t {
+	case durationType:
+		return Duration, true
+	case timeType:
+		return Date, true
+	case userType:
+		return User, true
+	case elementType:
+		return Image, true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Number, true
+	case reflect.Float32, reflect.Float64:
+		return Float, true
+	case reflect.Bool:
+		return Boolean, true
+	case reflect.String:
+		return String, true
+	}
+	return "", false
+}
+
+// fillStruct 将解析后的参数和选项写入结构体字段，未提供的字段保持初始值
+func (ctx *Context) fillStruct(target reflect.Value, fields []structField) error {
+	for _, field := range fields {
+		dst := target.FieldByIndex(field.index)
+
+		if field.rest {
+			if field.arg < len(ctx.Arguments) {
+				dst.Set(reflect.ValueOf(append([]string(nil), ctx.Arguments[field.arg:]...)).Convert(dst.Type()))
+			}
+			continue
+		}
+
+		v, ok := ctx.values[field.name]
+		if !ok {
+			continue
+		}
+		src := reflect.ValueOf(v)
+		// 避免整数被当作字符编码转换为字符串
+		if !src.Type().ConvertibleTo(dst.Type()) || (dst.Kind() == reflect.String) != (src.Kind() == reflect.String) {
+			return fmt.Errorf("%s: cannot assign %s to %s", field.name, src.Type(), dst.Type())
+		}
+		if overflows(src, dst) {
+			kind := "option"
+			if field.arg >= 0 {
+				kind = "argument"
+			}
+			return ctx.parseError(fmt.Errorf("%s '%s' out of range for %s", kind, field.name, dst.Type()))
+		}
+		dst.Set(src.Convert(dst.Type()))
+	}
+	return nil
+}
+
+// overflows 报告 src 转换为 dst 的类型时是否超出范围
+func overflows(src reflect.Value, dst reflect.Value) bool {
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return dst.OverflowInt(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return src.Uint() > math.MaxInt64 || dst.OverflowInt(int64(src.Uint()))
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			return f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return src.Int() < 0 || dst.OverflowUint(uint64(src.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return dst.OverflowUint(src.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			return f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f))
+		}
+	case reflect.Float32, reflect.Float64:
+		if src.Kind() == reflect.Float32 || src.Kind() == reflect.Float64 {
+			return dst.OverflowFloat(src.Float())
+		}
+	}
+	return false
+}
+
+func argPosition(positions map[int]*structField, name string) int {
+	for pos, field := range positions {
+		if field.name == name {
+			return pos
+		}
+	}
+	return -1
+}
+
+func hasFlag(parts []string, flag string) bool {
+	for _, part := range parts {
+		if strings.TrimSpace(part) == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// snakeCase 将 UpperBound 转换为 upper_bound
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package command_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+type rollArgs struct {
+	UpperBound int           `arg:"0" type:"int(1,100)" help:"骰子点数上限"`
+	Count      int8          `opt:"--count,-c" default:"1"`
+	Verbose    bool          `opt:"--verbose,-v"`
+	Wait       time.Duration `opt:"--wait"`
+	Rest       []string      `arg:"1"`
+}
+
+func TestCommandFromStruct(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := GoroBot.CommandFromStruct(grb, "roll", &rollArgs{UpperBound: 6}, func(ctx *command.Context, args *rollArgs) error {
+		_, _ = ctx.ReplyText(fmt.Sprintf("%d %d %t %v %s", args.UpperBound, args.Count, args.Verbose, args.Wait, strings.Join(args.Rest, ",")))
+		return nil
+	}).Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"roll", "6 1 false 0s "},
+		{"roll 20 -c 3 -v --wait 1m a b", "20 3 true 1m0s a,b"},
+		{"roll 20", "20 1 false 0s "},
+	}
+	for _, tt := range tests {
+		if got := bot.RunCommand(t, bot.NewTextMessage("/"+tt.input), tt.input); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCommandFromStructOverflow(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	called := false
+	if _, err := GoroBot.CommandFromStruct(grb, "roll", &rollArgs{}, func(ctx *command.Context, args *rollArgs) error {
+		called = true
+		return nil
+	}).Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	text := bot.RunCommand(t, bot.NewTextMessage("/roll 1 -c 300"), "roll 1 -c 300")
+	if called {
+		t.Fatal("handler called with an out of range value")
+	}
+	if !strings.Contains(text, "out of range") || !strings.Contains(text, "用法") {
+		t.Fatalf("expected a parse error with usage, got %q", text)
+	}
+}
+
+func TestCommandFromStructInvalid(t *testing.T) {
+	grb, _ := testkit.NewInstant(t)
+
+	type badPosition struct {
+		A string `arg:"1"`
+	}
+	if _, err := GoroBot.CommandFromStruct(grb, "bad", &badPosition{}, func(*command.Context, *badPosition) error {
+		return nil
+	}).Build(); err == nil {
+		t.Fatal("expected an error for a missing argument position")
+	}
+
+	if _, err := GoroBot.CommandFromStruct(grb, "scalar", new(int), func(*command.Context, *int) error {
+		return nil
+	}).Build(); err == nil {
+		t.Fatal("expected an error for a non-struct argument")
+	}
+}
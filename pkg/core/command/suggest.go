@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SuggestPolicy 决定是否回复未知命令的拼写建议，返回 false 时未知命令不会有任何回复
+type SuggestPolicy = func(ctx *Context) bool
+
+// SetSuggestPolicy 设置未知命令的 SuggestPolicy，未设置时不回复未知命令
+func (s *System) SetSuggestPolicy(policy SuggestPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suggest = policy
+}
+
+// Suggest 返回 candidates 中与 input 编辑距离最近的一个，不区分大小写，距离相同时返回字典序最小的一个。
+// 距离超过输入长度的三分之一（至少为 1）时返回 false
+func Suggest(input string, candidates []string) (string, bool) {
+	candidates = append([]string(nil), candidates...)
+	sort.Strings(candidates)
+	input = strings.ToLower(input)
+	limit := max(len([]rune(input))/3, 1)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		d := editDistance(input, strings.ToLower(candidate))
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance 返回两个字符串之间的 Damerau-Levenshtein 编辑距离（相邻字符交换计为一次）
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// suggestCommand 在没有命令匹配时回复拼写建议
+func suggestCommand(ctx *Context, registries []*Registry, policy SuggestPolicy) {
+	if len(ctx.argQueue) == 0 || ctx.Implicit || policy == nil || !policy(ctx) {
+		return
+	}
+	names := make([]string, 0, len(registries))
+	for _, reg := range registries {
+		names = append(names, reg.Schema.Name)
+	}
+	if name, ok := Suggest(ctx.argQueue[0], names); ok {
+		_, _ = ctx.ReplyText(fmt.Sprintf("未知命令 %s，你是不是想输入 %s？", ctx.argQueue[0], name))
+	}
+}
+
+// unknownOption 返回选项不存在的错误，存在相近的选项时附带建议
+func unknownOption(key string, schema *Schema) error {
+	if !strings.HasPrefix(key, "--") {
+		return fmt.Errorf("option '%s' not found", key)
+	}
+	names := make([]string, 0, len(schema.Options))
+	for _, option := range schema.Options {
+		names = append(names, strings.TrimLeft(option.Name, "-"))
+	}
+	if name, ok := Suggest(strings.TrimLeft(key, "-"), names); ok {
+		return fmt.Errorf("option '%s' not found, did you mean '--%s'?", key, name)
+	}
+	return fmt.Errorf("option '%s' not found", key)
+}
+
+// unknownSubCommand 返回子命令不存在的错误，存在相近的子命令时附带建议
+func unknownSubCommand(token string, schema *Schema) error {
+	names := make([]string, 0, len(schema.SubCommandSchemas))
+	for _, sub := range schema.SubCommandSchemas {
+		names = append(names, sub.Name)
+	}
+	if name, ok := Suggest(token, names); ok {
+		return fmt.Errorf("unknown subcommand '%s', did you mean '%s'?", token, name)
+	}
+	return fmt.Errorf("unknown subcommand '%s'", token)
+}
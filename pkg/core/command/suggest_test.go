@@ -0,0 +1,112 @@
+package command_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"help", "history", "role", "ratelimit", "channel"}
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"help", "help", true},
+		{"hlep", "help", true}, // 相邻字符交换
+		{"HELP", "help", true}, // 不区分大小写
+		{"rol", "role", true},
+		{"histroy", "history", true},
+		{"chanel", "channel", true},
+		{"ratelimt", "ratelimit", true},
+		{"xyz", "", false},
+		{"hello", "", false}, // 与 help 的距离为 2，超过长度的三分之一
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := command.Suggest(tt.input, candidates)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Suggest(%q) = %q, %t, want %q, %t", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, ok := command.Suggest("help", nil); ok {
+		t.Error("Suggest with no candidates should return false")
+	}
+
+	// 距离相同时结果与候选顺序无关
+	for _, candidates := range [][]string{{"cat", "bat", "hat"}, {"hat", "cat", "bat"}} {
+		if got, _ := command.Suggest("at", candidates); got != "bat" {
+			t.Errorf("Suggest(%q, %v) = %q, want %q", "at", candidates, got, "bat")
+		}
+	}
+}
+
+func TestSuggestReplies(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	cmd := grb.Command("team")
+	cmd.SubCommand("grant").
+		Option("group", "g", command.String, false, "", "").
+		Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("granted")
+			return nil
+		})
+	cmd.SubCommand("revoke").
+		Action(func(ctx *command.Context) error { return nil })
+	if _, err := cmd.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"taem grant", "你是不是想输入 team"},
+		{"team gratn", "did you mean 'grant'"},
+		{"team grant --grop g1", "did you mean '--group'"},
+	}
+	for _, tt := range tests {
+		if got := bot.RunCommand(t, bot.NewTextMessage("/"+tt.input), tt.input); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: got %q, want it to contain %q", tt.input, got, tt.want)
+		}
+	}
+
+	// 没有相近的命令时不回复
+	bot.Reset()
+	bot.EmitCommand(bot.NewTextMessage("/something"), "something")
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected reply %q", out[0].Text())
+	}
+
+	// 只因空前缀被视为命令的普通聊天不回复
+	bot.Reset()
+	implicit := command.NewCommandContext(bot.NewTextMessage("taem"), "taem")
+	implicit.Implicit = true
+	grb.CommandEmit(implicit)
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected reply to an implicit command %q", out[0].Text())
+	}
+
+	// 不回复被封禁的用户
+	user := testkit.UserID("mallory")
+	if err := grb.GrantRole(user, "", entity.Banned); err != nil {
+		t.Fatal(err)
+	}
+	bot.Reset()
+	bot.EmitCommand(bot.NewTextMessage("/taem").From(user), "taem")
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected reply to a banned sender %q", out[0].Text())
+	}
+}
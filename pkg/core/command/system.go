@@ -1,15 +1,25 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 	"github.com/google/uuid"
 )
 
 type System struct {
 	commands map[string]*Registry
-	mu       sync.RWMutex
+	resolver AuthorityResolver
+	prompter Prompter
+	recorder Recorder
+	filter   Filter
+	suggest  SuggestPolicy
+
+	middlewares []middlewareEntry
+	mu          sync.RWMutex
 }
 
 func NewCommandSystem() *System {
@@ -31,19 +41,58 @@ func (s *System) Register(registry Registry) func() {
 	}
 }
 
-func (s *System) Emit(cmdCtx *Context) {
-	s.mu.RLock()
+// Filter 决定顶级命令在当前会话中是否可用，不可用的命令视为不存在
+type Filter = func(ctx *Context, reg *Registry) bool
+
+// SetFilter 设置命令可用性的 Filter
+func (s *System) SetFilter(filter Filter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filter = filter
+}
+
+// available 返回在 ctx 所在会话中可用的顶级命令，需在持有 s.mu 时调用
+func (s *System) available(ctx *Context) []*Registry {
 	registries := make([]*Registry, 0, len(s.commands))
 	for _, registry := range s.commands {
-		registries = append(registries, registry)
+		if s.filter == nil || s.filter(ctx, registry) {
+			registries = append(registries, registry)
+		}
 	}
+	return registries
+}
+
+func (s *System) Emit(cmdCtx *Context) {
+	s.mu.RLock()
+	registries := s.available(cmdCtx)
+	cmdCtx.resolver = s.resolver
+	cmdCtx.prompter = s.prompter
+	cmdCtx.recorder = s.recorder
+	cmdCtx.middlewares = s.snapshot()
+	suggest := s.suggest
 	s.mu.RUnlock()
 
+	matched := false
 	for _, registry := range registries {
 		ctx := cmdCtx.Clone()
-		if err := registry.handle(ctx); err != nil && err.Error() != "unmatched command" {
-			_, _ = ctx.ReplyText(err.Error())
+		err := registry.handle(ctx)
+		if errors.Is(err, ErrUnmatchedCommand) {
+			continue
+		}
+		matched = true
+		if err == nil {
+			continue
+		}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			_, _ = ctx.ReplyText(fmt.Sprintf("%s\n用法：%s", parseErr.Err, parseErr.Usage()))
+			continue
 		}
+		_, _ = ctx.ReplyText(err.Error())
+	}
+
+	if !matched && cmdCtx.Authority() != entity.Banned {
+		suggestCommand(cmdCtx, registries, suggest)
 	}
 }
 
@@ -58,52 +107,102 @@ func (s *System) GetSchemas() []Schema {
 	return schemas
 }
 
+// AvailableSchemas 返回在 ctx 所在会话中可用的顶级命令 Schema
+func (s *System) AvailableSchemas(ctx *Context) []Schema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	registries := s.available(ctx)
+	schemas := make([]Schema, 0, len(registries))
+	for _, reg := range registries {
+		schemas = append(schemas, reg.Schema)
+	}
+	return schemas
+}
+
 // CheckAliases 遍历所有已注册命令检查别名匹配
 func (s *System) CheckAliases(ctx *Context) {
 	s.mu.RLock()
-	registries := make([]*Registry, 0, len(s.commands))
-	for _, reg := range s.commands {
-		registries = append(registries, reg)
-	}
+	registries := s.available(ctx)
+	ctx.resolver = s.resolver
+	ctx.prompter = s.prompter
+	ctx.recorder = s.recorder
+	ctx.middlewares = s.snapshot()
 	s.mu.RUnlock()
 
 	for _, reg := range registries {
-		reg.CheckAlias(ctx.Clone())
+		reg.CheckAlias(ctx.Clone(), nil)
 	}
 }
 
 func (r *Registry) Emit(cmdCtx *Context) error { // 触发指令Reg
 	if r.Handler == nil {
-		return fmt.Errorf("unmatched command")
+		if len(r.SubRegistries) > 0 {
+			err := fmt.Errorf("subcommand required")
+			if len(cmdCtx.Arguments) > 0 {
+				err = unknownSubCommand(cmdCtx.Arguments[0], &r.Schema)
+			}
+			return &ParseError{
+				Commands: cmdCtx.Commands,
+				Schema:   &r.Schema,
+				Err:      err,
+			}
+		}
+		return ErrUnmatchedCommand
 	}
 	return r.Handler(cmdCtx.setSchema(&r.Schema))
 }
 
-func (r *Registry) handle(cmdCtx *Context) error {
-	if err := cmdCtx.processTokens(&r.Schema); err != nil {
+func (r *Registry) handle(cmdCtx *Context) (err error) {
+	start := time.Now()
+	var path []*Registry
+	defer func() {
+		cmdCtx.record(path, start, err)
+	}()
+
+	if err = cmdCtx.processTokens(&r.Schema); err != nil {
+		// 没有权限时不展示用法
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			if path = r.findPath(parseErr.Commands); path != nil {
+				if checkErr := check(cmdCtx, path); checkErr != nil {
+					return checkErr
+				}
+			}
+		}
+		return err
+	}
+
+	path = r.findPath(cmdCtx.Commands)
+	if path == nil {
+		return ErrUnmatchedCommand
+	}
+
+	if err = check(cmdCtx.setSchema(&path[len(path)-1].Schema), path); err != nil {
 		return err
 	}
 
-	target := r.findRegistry(cmdCtx.Commands)
-	if target == nil {
-		return fmt.Errorf("unmatched command")
+	if err = takeCooldowns(cmdCtx, path); err != nil {
+		return err
 	}
 
-	return target.Emit(cmdCtx)
+	return invoke(cmdCtx, path, func() error {
+		return path[len(path)-1].Emit(cmdCtx)
+	})
 }
 
-func (r *Registry) findRegistry(commands []string) *Registry {
+// findPath 返回从当前命令到目标子命令路径上的所有 Registry，未匹配时返回 nil
+func (r *Registry) findPath(commands []string) []*Registry {
 	if len(commands) == 0 || !r.Schema.Match(commands[0]) {
 		return nil
 	}
 
 	if len(commands) == 1 {
-		return r
+		return []*Registry{r}
 	}
 
 	for i := range r.SubRegistries {
-		if sub := r.SubRegistries[i].findRegistry(commands[1:]); sub != nil {
-			return sub
+		if sub := r.SubRegistries[i].findPath(commands[1:]); sub != nil {
+			return append([]*Registry{r}, sub...)
 		}
 	}
 
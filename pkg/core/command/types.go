@@ -0,0 +1,289 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+const (
+	Int      InputType = "int"      // 整数，值为 int，可用 IntRange 限制范围
+	Float    InputType = "float"    // 浮点数，值为 float64，可用 FloatRange 限制范围
+	Duration InputType = "duration" // 时长，如 90s、1h30m、7d，值为 time.Duration
+	Date     InputType = "date"     // 日期，如 2006-01-02、2006-01-02T15:04，值为本地时区的 time.Time
+	User     InputType = "user"     // @提及或用户 ID，值为 entity.User
+	Image    InputType = "image"    // 消息中附带的图片，值为 *botc.MessageElement
+	File     InputType = "file"     // 消息中附带的文件，值为 *botc.MessageElement
+)
+
+// IntRange 返回限制在 [min, max] 之间的整数类型
+func IntRange(min int, max int) InputType {
+	return InputType(fmt.Sprintf("%s(%d,%d)", Int, min, max))
+}
+
+// FloatRange 返回限制在 [min, max] 之间的浮点数类型
+func FloatRange(min float64, max float64) InputType {
+	return InputType(fmt.Sprintf("%s(%g,%g)", Float, min, max))
+}
+
+// Enum 返回只能从 choices 中选择的类型，不区分大小写，值为 choices 中对应的字符串
+func Enum(choices ...string) InputType {
+	return InputType(fmt.Sprintf("enum(%s)", strings.Join(choices, "|")))
+}
+
+var dateLayouts = []string{"2006-01-02", "2006/01/02", "2006-01-02T15:04", "2006-01-02T15:04:05"}
+
+func registerBuiltinTypes() {
+	RegisterParser(Int, regexp.MustCompile(`^[+-]?[0-9]+$`), parseInt)
+	RegisterParser(Float, regexp.MustCompile(`^[+-]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)$`), parseFloat)
+	RegisterParser("enum", nil, parseEnum)
+	RegisterParser(Duration, nil, func(_ *Context, value string, _ []string) (any, error) {
+		return parseDuration(value)
+	})
+	RegisterParser(Date, nil, func(_ *Context, value string, _ []string) (any, error) {
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("expected %s", strings.Join(dateLayouts, ", "))
+	})
+	RegisterParser(User, nil, parseUser)
+	RegisterParser(Image, nil, attachmentParser(botc.ImageElement))
+	RegisterParser(File, nil, attachmentParser(botc.FileElement))
+}
+
+func parseInt(_ *Context, value string, params []string) (any, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	low, high, err := bounds(params)
+	if err != nil {
+		return nil, err
+	}
+	if float64(n) < low || float64(n) > high {
+		return nil, fmt.Errorf("out of range")
+	}
+	return n, nil
+}
+
+func parseFloat(_ *Context, value string, params []string) (any, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	low, high, err := bounds(params)
+	if err != nil {
+		return nil, err
+	}
+	if f < low || f > high {
+		return nil, fmt.Errorf("out of range")
+	}
+	return f, nil
+}
+
+// bounds 解析范围参数，空参数表示不限制
+func bounds(params []string) (float64, float64, error) {
+	low, high := math.Inf(-1), math.Inf(1)
+	if len(params) > 0 && params[0] != "" {
+		v, err := strconv.ParseFloat(params[0], 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range %v", params)
+		}
+		low = v
+	}
+	if len(params) > 1 && params[1] != "" {
+		v, err := strconv.ParseFloat(params[1], 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range %v", params)
+		}
+		high = v
+	}
+	return low, high, nil
+}
+
+func parseEnum(_ *Context, value string, choices []string) (any, error) {
+	for _, choice := range choices {
+		if strings.EqualFold(value, choice) {
+			return choice, nil
+		}
+	}
+	return nil, fmt.Errorf("expected one of %s", strings.Join(choices, ", "))
+}
+
+var daysPattern = regexp.MustCompile(`^([0-9]+)d(.*)$`)
+
+// parseDuration 在 time.ParseDuration 的基础上支持以天为单位，如 7d、1d12h
+func parseDuration(value string) (time.Duration, error) {
+	days := time.Duration(0)
+	if m := daysPattern.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		if value = m[2]; value == "" {
+			return days, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return days + d, nil
+}
+
+// parseUser 将 @提及 解析为对应的用户，也接受带协议前缀的用户 ID
+func parseUser(ctx *Context, value string, _ []string) (any, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("no message context")
+	}
+	protocol := ""
+	if bot := ctx.BotContext(); bot != nil {
+		protocol = bot.Protocol()
+	}
+
+	if msg := ctx.Message(); msg != nil {
+		for _, elem := range msg.Elements {
+			if elem.Type != botc.MentionElement || strings.TrimSpace(elem.Content) != value || ctx.consumed[elem] {
+				continue
+			}
+			ctx.consume(elem)
+			id := elem.Source
+			if _, ok := entity.ParseInfo(id); !ok && protocol != "" {
+				id = protocol + ":" + id
+			}
+			return entity.User{Base: &entity.Base{ID: id, Name: strings.TrimPrefix(elem.Content, "@")}}, nil
+		}
+	}
+
+	if info, ok := entity.ParseInfo(value); ok && (protocol == "" || info.Protocol == protocol) {
+		return entity.User{Base: &entity.Base{ID: value}}, nil
+	}
+	return nil, fmt.Errorf("expected a mention or user id")
+}
+
+// attachmentParser 返回从消息中取出下一个未使用的图片或文件元素的 Parser
+func attachmentParser(elementType botc.ElementType) Parser {
+	return func(ctx *Context, value string, _ []string) (any, error) {
+		if ctx == nil {
+			return nil, fmt.Errorf("no message context")
+		}
+		if elem := ctx.attachment(elementType, value); elem != nil {
+			return elem, nil
+		}
+		return nil, fmt.Errorf("no attachment found")
+	}
+}
+
+// attachment 返回下一个未使用的指定类型元素，content 不为空时要求内容一致
+func (ctx *Context) attachment(elementType botc.ElementType, content string) *botc.MessageElement {
+	msg := ctx.Message()
+	if msg == nil {
+		return nil
+	}
+	for _, elem := range msg.Elements {
+		if elem.Type != elementType || ctx.consumed[elem] {
+			continue
+		}
+		if content != "" && strings.TrimSpace(elem.Content) != content {
+			continue
+		}
+		ctx.consume(elem)
+		return elem
+	}
+	return nil
+}
+
+func (ctx *Context) consume(elem *botc.MessageElement) {
+	if ctx.consumed == nil {
+		ctx.consumed = make(map[*botc.MessageElement]bool)
+	}
+	ctx.consumed[elem] = true
+}
+
+// fillAttachments 用消息中附带但没有出现在命令文本里的图片和文件填充剩余的参数
+func (ctx *Context) fillAttachments(schema *Schema) {
+	for ctx.argIndex < len(schema.Arguments) {
+		arg := schema.Arguments[ctx.argIndex]
+		var elementType botc.ElementType
+		switch name, _ := splitType(arg.Type); name {
+		case Image:
+			elementType = botc.ImageElement
+		case File:
+			elementType = botc.FileElement
+		default:
+			return
+		}
+		elem := ctx.attachment(elementType, "")
+		if elem == nil {
+			return
+		}
+		ctx.KvArgs[arg.Name] = elem.Source
+		ctx.values[arg.Name] = elem
+		ctx.argIndex++
+	}
+}
+
+// Value 返回参数或选项转换后的值，未提供时返回 false
+func (ctx *Context) Value(name string) (any, bool) {
+	v, ok := ctx.values[name]
+	return v, ok
+}
+
+func valueAs[T any](ctx *Context, name string) (T, bool) {
+	v, ok := ctx.values[name].(T)
+	return v, ok
+}
+
+// Int 返回 Number、Int 类型参数或选项的值
+func (ctx *Context) Int(name string) (int, bool) {
+	return valueAs[int](ctx, name)
+}
+
+// Float 返回 Float 类型参数或选项的值
+func (ctx *Context) Float(name string) (float64, bool) {
+	return valueAs[float64](ctx, name)
+}
+
+// Bool 返回 Boolean 类型参数或选项的值
+func (ctx *Context) Bool(name string) (bool, bool) {
+	return valueAs[bool](ctx, name)
+}
+
+// Choice 返回 Enum 类型参数或选项的值
+func (ctx *Context) Choice(name string) (string, bool) {
+	return valueAs[string](ctx, name)
+}
+
+// Duration 返回 Duration 类型参数或选项的值
+func (ctx *Context) Duration(name string) (time.Duration, bool) {
+	return valueAs[time.Duration](ctx, name)
+}
+
+// Date 返回 Date 类型参数或选项的值
+func (ctx *Context) Date(name string) (time.Time, bool) {
+	return valueAs[time.Time](ctx, name)
+}
+
+// User 返回 User 类型参数或选项的值
+func (ctx *Context) User(name string) (entity.User, bool) {
+	return valueAs[entity.User](ctx, name)
+}
+
+// Image 返回 Image 类型参数的图片元素，Source 为资源 ID
+func (ctx *Context) Image(name string) (*botc.MessageElement, bool) {
+	return valueAs[*botc.MessageElement](ctx, name)
+}
+
+// File 返回 File 类型参数的文件元素，Source 为资源 ID
+func (ctx *Context) File(name string) (*botc.MessageElement, bool) {
+	return valueAs[*botc.MessageElement](ctx, name)
+}
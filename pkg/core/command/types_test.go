@@ -0,0 +1,133 @@
+package command_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func newTypedCommand(t *testing.T) *testkit.Bot {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := grb.Command("t").
+		Argument("n", command.IntRange(-5, 10), true, "").
+		Argument("ratio", command.FloatRange(0, 1), false, "").
+		Option("mode", "m", command.Enum("rock", "paper"), false, "rock", "").
+		Option("wait", "w", command.Duration, false, "", "").
+		Option("date", "d", command.Date, false, "", "").
+		Option("user", "u", command.User, false, "", "").
+		Option("count", "c", command.Number, false, "", "").
+		Action(func(ctx *command.Context) error {
+			n, _ := ctx.Int("n")
+			ratio, hasRatio := ctx.Float("ratio")
+			mode, _ := ctx.Choice("mode")
+			parts := []string{fmt.Sprint(n), fmt.Sprint(ratio, hasRatio), mode}
+			if wait, ok := ctx.Duration("wait"); ok {
+				parts = append(parts, wait.String())
+			}
+			if date, ok := ctx.Date("date"); ok {
+				parts = append(parts, date.Format(time.DateTime))
+			}
+			if user, ok := ctx.User("user"); ok {
+				parts = append(parts, user.ID)
+			}
+			if count, ok := ctx.Int("count"); ok {
+				parts = append(parts, fmt.Sprint(count))
+			}
+			_, _ = ctx.ReplyText(strings.Join(parts, " "))
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+	return bot
+}
+
+func TestTypedArguments(t *testing.T) {
+	bot := newTypedCommand(t)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"t 3", "3 0 false rock"},
+		{"t -3 0.25", "-3 0.25 true rock"},
+		{"t 3 -m PAPER", "3 0 false paper"},
+		{"t 3 --wait 1d2h", "3 0 false rock 26h0m0s"},
+		{"t 3 --date 2024-02-03T20:00", "3 0 false rock 2024-02-03 20:00:00"},
+		{"t 3 -u " + testkit.UserID("alice"), "3 0 false rock " + testkit.UserID("alice")},
+		{"t 3 -c 7", "3 0 false rock 7"},
+	}
+	for _, tt := range tests {
+		if got := bot.RunCommand(t, bot.NewTextMessage("/"+tt.input), tt.input); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTypedArgumentErrors(t *testing.T) {
+	bot := newTypedCommand(t)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"t", "argument 'n' is required"},
+		{"t abc", "argument 'n'"},
+		{"t 11", "out of range"},
+		{"t 3 1.5", "out of range"},
+		{"t 3 -m scissors", "expected one of rock, paper"},
+		{"t 3 --wait soon", "option 'wait'"},
+		{"t 3 --date tomorrow", "option 'date'"},
+		{"t 3 -u alice", "expected a mention or user id"},
+		{"t 3 -c -1", "option 'count'"},
+		{"t 3 --unknown 1", "option"},
+	}
+	for _, tt := range tests {
+		got := bot.RunCommand(t, bot.NewTextMessage("/"+tt.input), tt.input)
+		if !strings.Contains(got, tt.want) || !strings.Contains(got, "用法：t") {
+			t.Errorf("%s: got %q, want an error containing %q with usage", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMentionAndAttachmentArguments(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := grb.Command("poke").
+		Argument("user", command.User, true, "").
+		Argument("image", command.Image, true, "").
+		Action(func(ctx *command.Context) error {
+			user, _ := ctx.User("user")
+			img, _ := ctx.Image("image")
+			_, _ = ctx.ReplyText(user.ID + " " + img.Source)
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := bot.NewMessage(
+		&botc.MessageElement{Type: botc.TextElement, Content: "/poke "},
+		&botc.MessageElement{Type: botc.MentionElement, Content: "@alice", Source: testkit.UserID("alice")},
+		&botc.MessageElement{Type: botc.ImageElement, Content: "[图片]", Source: "image-1"},
+	)
+	// 图片参数没有出现在命令文本中，使用消息附带的图片
+	if got, want := bot.RunCommand(t, msg, "poke @alice"), testkit.UserID("alice")+" image-1"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	msg = bot.NewMessage(
+		&botc.MessageElement{Type: botc.TextElement, Content: "/poke "},
+		&botc.MessageElement{Type: botc.MentionElement, Content: "@alice", Source: testkit.UserID("alice")},
+	)
+	if got := bot.RunCommand(t, msg, "poke @alice"); !strings.Contains(got, "argument 'image' is required") {
+		t.Fatalf("missing attachment: got %q", got)
+	}
+}
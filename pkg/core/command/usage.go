@@ -0,0 +1,176 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnmatchedCommand = errors.New("unmatched command")
+
+// ParseError 表示命令已匹配但参数或选项解析失败，Commands 为匹配到的命令路径
+type ParseError struct {
+	Commands []string
+	Schema   *Schema
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Usage 返回出错命令的用法
+func (e *ParseError) Usage() string {
+	if e.Schema == nil {
+		return strings.Join(e.Commands, " ")
+	}
+	parents := e.Commands
+	if len(parents) > 0 {
+		parents = parents[:len(parents)-1]
+	}
+	return e.Schema.Usage(parents...)
+}
+
+// Usage 返回一行用法，例如 "dice [upper_bound:number]"。
+// parents 为上级命令路径，必填参数用 <> 表示，可选参数用 [] 表示
+func (s *Schema) Usage(parents ...string) string {
+	parts := append(append([]string(nil), parents...), s.Name)
+
+	if len(s.SubCommandSchemas) > 0 {
+		names := make([]string, 0, len(s.SubCommandSchemas))
+		for _, sub := range s.SubCommandSchemas {
+			names = append(names, sub.Name)
+		}
+		parts = append(parts, "<"+strings.Join(names, "|")+">")
+	}
+
+	for _, opt := range s.Options {
+		part := optionFlag(opt)
+		if opt.Type != Boolean {
+			part += fmt.Sprintf(" <%s>", opt.Type)
+		}
+		if !opt.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+
+	for _, arg := range s.Arguments {
+		part := fmt.Sprintf("%s:%s", arg.Name, arg.Type)
+		if arg.Required {
+			part = "<" + part + ">"
+		} else {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Help 返回完整的帮助文本，包含用法、描述、参数、选项和子命令树
+func (s *Schema) Help(parents ...string) string {
+	var b strings.Builder
+	b.WriteString("用法：" + s.Usage(parents...))
+	if s.Description != "" {
+		b.WriteString("\n" + s.Description)
+	}
+
+	if len(s.Arguments) > 0 {
+		b.WriteString("\n参数：")
+		for _, arg := range s.Arguments {
+			line := fmt.Sprintf("\n  %s:%s", arg.Name, arg.Type)
+			if arg.Help != "" {
+				line += "  " + arg.Help
+			}
+			if !arg.Required {
+				line += "（可选）"
+			}
+			b.WriteString(line)
+		}
+	}
+
+	if len(s.Options) > 0 {
+		b.WriteString("\n选项：")
+		for _, opt := range s.Options {
+			line := "\n  " + optionFlags(opt)
+			if opt.Type != Boolean {
+				line += fmt.Sprintf(" <%s>", opt.Type)
+			}
+			if opt.Help != "" {
+				line += "  " + opt.Help
+			}
+			if opt.Required {
+				line += "（必填）"
+			}
+			if opt.Default != "" {
+				line += fmt.Sprintf("（默认 %s）", opt.Default)
+			}
+			b.WriteString(line)
+		}
+	}
+
+	if len(s.SubCommandSchemas) > 0 {
+		b.WriteString("\n子命令：")
+		writeTree(&b, s.SubCommandSchemas, "  ")
+	}
+
+	return b.String()
+}
+
+func writeTree(b *strings.Builder, schemas []Schema, indent string) {
+	for _, sub := range schemas {
+		line := "\n" + indent + sub.Name
+		if sub.Description != "" {
+			line += "  " + sub.Description
+		}
+		b.WriteString(line)
+		writeTree(b, sub.SubCommandSchemas, indent+"  ")
+	}
+}
+
+// FindSchema 按命令路径查找 Schema，返回找到的 Schema 及实际匹配的路径。
+// 路径中无法匹配的部分会被忽略，顶级命令不存在时返回 false
+func FindSchema(schemas []Schema, path []string) (*Schema, []string, bool) {
+	if len(path) == 0 {
+		return nil, nil, false
+	}
+	current, ok := getSchemaFromSlice(&schemas, path[0])
+	if !ok {
+		return nil, nil, false
+	}
+	matched := []string{current.Name}
+	for _, token := range path[1:] {
+		sub, ok := getSchemaFromSlice(&current.SubCommandSchemas, token)
+		if !ok {
+			break
+		}
+		current = sub
+		matched = append(matched, sub.Name)
+	}
+	return current, matched, true
+}
+
+// optionFlag 返回用法中展示的选项写法，优先使用长选项
+func optionFlag(opt SchemaOption) string {
+	if name := strings.TrimLeft(opt.Name, "-"); name != "" {
+		return "--" + name
+	}
+	return "-" + strings.TrimLeft(opt.ShortName, "-")
+}
+
+// optionFlags 返回选项的所有写法，例如 "-n, --count"
+func optionFlags(opt SchemaOption) string {
+	var flags []string
+	if short := strings.TrimLeft(opt.ShortName, "-"); short != "" {
+		flags = append(flags, "-"+short)
+	}
+	if name := strings.TrimLeft(opt.Name, "-"); name != "" {
+		flags = append(flags, "--"+name)
+	}
+	return strings.Join(flags, ", ")
+}
@@ -6,9 +6,15 @@ import (
 )
 
 type Config struct {
-	Owner        map[string]string `json:"owner"`
-	LogLevel     logger.LogLevel   `json:"log_level"`
-	ResourcePath string            `json:"resource_path"`
+	Owner            map[string]string       `json:"owner"`
+	LogLevel         logger.LogLevel         `json:"log_level"`
+	ResourcePath     string                  `json:"resource_path"`
+	ResourceS3       *S3ResourceConfig       `json:"resource_s3,omitempty"`        // 设置后资源文件保存到 S3 兼容存储，resource_path 下只保留缓存
+	ResourceLimit    *ResourceLimitConfig    `json:"resource_limit,omitempty"`     // 资源文件的大小上限和过期时间，未设置时不限制
+	ResourceThumb    int                     `json:"resource_thumbnail,omitempty"` // 为图片生成缩略图的最大边长（像素），为 0 时不生成
+	ResourceDownload *ResourceDownloadConfig `json:"resource_download,omitempty"`  // 资源下载的并发和重试，未设置时使用默认值
+	SendLimit        *SendLimitConfig        `json:"send_limit,omitempty"`         // 每个账号的发送限流，未设置时为每秒 1 条、最多连续 5 条
+	PromptCancel     []string                `json:"prompt_cancel,omitempty"`      // 取消 Prompt 的关键词，未设置时为 "取消" 和 "cancel"
 }
 
 //go:embed config/default_conf.json
@@ -1,5 +1,7 @@
 package entity
 
+import "strings"
+
 type Sender struct {
 	*User
 	From *Base
@@ -22,3 +24,30 @@ const (
 	Admin
 	Owner
 )
+
+var authorityNames = map[Authority]string{
+	Banned:     "banned",
+	Member:     "member",
+	GroupAdmin: "group_admin",
+	GroupOwner: "group_owner",
+	Admin:      "admin",
+	Owner:      "owner",
+}
+
+func (a Authority) String() string {
+	if name, ok := authorityNames[a]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseAuthority 将权限名（如 "group_admin"）解析为 Authority
+func ParseAuthority(name string) (Authority, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for a, n := range authorityNames {
+		if n == name {
+			return a, true
+		}
+	}
+	return Member, false
+}
@@ -1,6 +1,8 @@
 package GoroBot
 
 import (
+	"fmt"
+
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
@@ -34,7 +36,20 @@ func (i *Instant) EventEmit(eventName string, args ...interface{}) error {
 	return i.event.Emit(eventName, args...)
 }
 
+// EventContextEmit 派发一个类型化事件，事件名取自 ctx.Event
+func (i *Instant) EventContextEmit(ctx *event.Context) error {
+	payload, ok := ctx.Event.(event.Payload)
+	if !ok {
+		return fmt.Errorf("event context carries no payload")
+	}
+	return i.event.Emit(payload.EventName(), ctx)
+}
+
 func (i *Instant) MessageEmit(msg botc.MessageContext) error {
+	i.storeIncoming(msg)
+	if i.deliverPrompt(msg) {
+		return nil
+	}
 	// 中间件
 	return i.middleware.dispatch(msg, func() error {
 		go i.commands.CheckAliases(command.NewCommandContext(msg, msg.String()))
@@ -43,6 +58,10 @@ func (i *Instant) MessageEmit(msg botc.MessageContext) error {
 }
 
 func (i *Instant) CommandEmit(cmd *command.Context) {
+	i.storeIncoming(cmd.MessageContext)
+	if i.deliverPrompt(cmd.MessageContext) {
+		return
+	}
 	// 中间件
 	_ = i.middleware.dispatch(cmd, func() error {
 		go i.event.Emit("message", cmd.MessageContext)
@@ -72,3 +91,60 @@ func CommandEvent(callback CommandEventCallback) EventHandler {
 		},
 	}
 }
+
+func typedEvent[T event.Payload](callback func(ctx *event.Context, e T)) EventHandler {
+	var zero T
+	return EventHandler{
+		Name: zero.EventName(),
+		Callback: func(args ...interface{}) {
+			ctx := args[0].(*event.Context)
+			if e, ok := ctx.Event.(T); ok {
+				callback(ctx, e)
+			}
+		},
+	}
+}
+
+func MemberJoinEvent(callback func(ctx *event.Context, e *event.MemberJoin)) EventHandler {
+	return typedEvent(callback)
+}
+
+func MemberLeaveEvent(callback func(ctx *event.Context, e *event.MemberLeave)) EventHandler {
+	return typedEvent(callback)
+}
+
+func FriendAddEvent(callback func(ctx *event.Context, e *event.FriendAdd)) EventHandler {
+	return typedEvent(callback)
+}
+
+func MessageRecallEvent(callback func(ctx *event.Context, e *event.MessageRecall)) EventHandler {
+	return typedEvent(callback)
+}
+
+func PokeEvent(callback func(ctx *event.Context, e *event.Poke)) EventHandler {
+	return typedEvent(callback)
+}
+
+func GroupMuteEvent(callback func(ctx *event.Context, e *event.GroupMute)) EventHandler {
+	return typedEvent(callback)
+}
+
+func FileUploadEvent(callback func(ctx *event.Context, e *event.FileUpload)) EventHandler {
+	return typedEvent(callback)
+}
+
+func FriendRequestEvent(callback func(ctx *event.Context, e *event.FriendRequest)) EventHandler {
+	return typedEvent(callback)
+}
+
+func GroupRequestEvent(callback func(ctx *event.Context, e *event.GroupRequest)) EventHandler {
+	return typedEvent(callback)
+}
+
+func BotOnlineEvent(callback func(ctx *event.Context, e *event.BotOnline)) EventHandler {
+	return typedEvent(callback)
+}
+
+func BotOfflineEvent(callback func(ctx *event.Context, e *event.BotOffline)) EventHandler {
+	return typedEvent(callback)
+}
@@ -0,0 +1,153 @@
+package event
+
+import (
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// Payload 是 Context.Event 中携带的具体事件数据
+type Payload interface {
+	EventName() string
+	EventType() Type
+}
+
+// Names 返回所有内置类型化事件的名称，Instant 创建时会逐一注册
+func Names() []string {
+	return []string{
+		(*MemberJoin)(nil).EventName(),
+		(*MemberLeave)(nil).EventName(),
+		(*FriendAdd)(nil).EventName(),
+		(*MessageRecall)(nil).EventName(),
+		(*Poke)(nil).EventName(),
+		(*GroupMute)(nil).EventName(),
+		(*FileUpload)(nil).EventName(),
+		(*FriendRequest)(nil).EventName(),
+		(*GroupRequest)(nil).EventName(),
+		(*BotOnline)(nil).EventName(),
+		(*BotOffline)(nil).EventName(),
+	}
+}
+
+// NewContext 创建一个携带 payload 的事件上下文，Sender 和 Group 由调用方按需填写
+func NewContext(bot botc.BotContext, payload Payload) *Context {
+	ctx := &Context{
+		BotContext: bot,
+		Time:       time.Now(),
+		Type:       payload.EventType(),
+		Event:      payload,
+		Content:    payload.EventName(),
+	}
+	if bot != nil {
+		ctx.Self = &entity.User{
+			Base: &entity.Base{
+				ID: bot.ID(),
+			},
+		}
+	}
+	return ctx
+}
+
+// MemberJoin 群成员增加
+type MemberJoin struct {
+	Member   *entity.User
+	Operator *entity.User // 审批人或邀请人，可能为空
+	Invited  bool
+}
+
+func (*MemberJoin) EventName() string { return "member_join" }
+func (*MemberJoin) EventType() Type   { return NotificationEvent }
+
+// MemberLeave 群成员减少
+type MemberLeave struct {
+	Member   *entity.User
+	Operator *entity.User // 被踢出时为操作者，主动退群时为空
+	Kicked   bool
+}
+
+func (*MemberLeave) EventName() string { return "member_leave" }
+func (*MemberLeave) EventType() Type   { return NotificationEvent }
+
+// FriendAdd 新增好友
+type FriendAdd struct {
+	User *entity.User
+}
+
+func (*FriendAdd) EventName() string { return "friend_add" }
+func (*FriendAdd) EventType() Type   { return NotificationEvent }
+
+// MessageRecall 消息撤回，私聊撤回时 Context.Group 为空
+type MessageRecall struct {
+	MessageID string
+	Author    *entity.User
+	Operator  *entity.User
+}
+
+func (*MessageRecall) EventName() string { return "message_recall" }
+func (*MessageRecall) EventType() Type   { return NotificationEvent }
+
+// Poke 戳一戳
+type Poke struct {
+	Operator *entity.User
+	Target   *entity.User
+}
+
+func (*Poke) EventName() string { return "poke" }
+func (*Poke) EventType() Type   { return NotificationEvent }
+
+// GroupMute 群禁言
+type GroupMute struct {
+	Target   *entity.User // 为空表示全员禁言
+	Operator *entity.User
+	Duration time.Duration // 为 0 表示解除禁言
+}
+
+func (*GroupMute) EventName() string { return "group_mute" }
+func (*GroupMute) EventType() Type   { return NotificationEvent }
+
+// FileUpload 群文件上传
+type FileUpload struct {
+	Uploader   *entity.User
+	Name       string
+	Size       int64
+	ResourceID string // 可以下载时为资源 ID，否则为空
+}
+
+func (*FileUpload) EventName() string { return "file_upload" }
+func (*FileUpload) EventType() Type   { return NotificationEvent }
+
+// FriendRequest 好友申请，通过 Approve / Reject 处理
+type FriendRequest struct {
+	*Request
+	User    *entity.User
+	Comment string
+}
+
+func (*FriendRequest) EventName() string { return "friend_request" }
+func (*FriendRequest) EventType() Type   { return RequestEvent }
+
+// GroupRequest 加群申请，Invite 为 true 时表示邀请机器人入群，通过 Approve / Reject 处理
+type GroupRequest struct {
+	*Request
+	User    *entity.User
+	Comment string
+	Invite  bool
+}
+
+func (*GroupRequest) EventName() string { return "group_request" }
+func (*GroupRequest) EventType() Type   { return RequestEvent }
+
+// BotOnline 机器人上线
+type BotOnline struct{}
+
+func (*BotOnline) EventName() string { return "bot_online" }
+func (*BotOnline) EventType() Type   { return NotificationEvent }
+
+// BotOffline 机器人离线
+type BotOffline struct {
+	Reason string
+}
+
+func (*BotOffline) EventName() string { return "bot_offline" }
+func (*BotOffline) EventType() Type   { return NotificationEvent }
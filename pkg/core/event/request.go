@@ -0,0 +1,62 @@
+package event
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	ErrRequestUnsupported = fmt.Errorf("request handling is not supported by this adapter")
+	ErrRequestHandled     = fmt.Errorf("request already handled")
+)
+
+// Request 是好友申请、加群申请的处理句柄，由适配器在派发事件时创建。
+// 同一个请求只能处理一次
+type Request struct {
+	approve func(remark string) error
+	reject  func(reason string) error
+	handled atomic.Bool
+}
+
+// NewRequest 创建请求句柄，approve / reject 为适配器对应的平台调用
+func NewRequest(approve func(remark string) error, reject func(reason string) error) *Request {
+	return &Request{
+		approve: approve,
+		reject:  reject,
+	}
+}
+
+// Approve 同意请求，remark 为好友备注，加群请求时忽略
+func (r *Request) Approve(remark string) error {
+	if r == nil || r.approve == nil {
+		return ErrRequestUnsupported
+	}
+	if !r.handled.CompareAndSwap(false, true) {
+		return ErrRequestHandled
+	}
+	if err := r.approve(remark); err != nil {
+		r.handled.Store(false)
+		return err
+	}
+	return nil
+}
+
+// Reject 拒绝请求，reason 为拒绝理由，平台不支持时忽略
+func (r *Request) Reject(reason string) error {
+	if r == nil || r.reject == nil {
+		return ErrRequestUnsupported
+	}
+	if !r.handled.CompareAndSwap(false, true) {
+		return ErrRequestHandled
+	}
+	if err := r.reject(reason); err != nil {
+		r.handled.Store(false)
+		return err
+	}
+	return nil
+}
+
+// Handled 返回请求是否已被处理
+func (r *Request) Handled() bool {
+	return r != nil && r.handled.Load()
+}
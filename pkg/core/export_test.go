@@ -0,0 +1,42 @@
+package GoroBot
+
+import (
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+)
+
+// SetResourceAccessed 修改资源的最后访问时间，用于测试过期和淘汰
+func (i *Instant) SetResourceAccessed(id string, accessed time.Time) error {
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		defer i.resourceMu.Unlock()
+		if res, ok := i.resourceMap[id]; ok {
+			res.Accessed = accessed
+			i.resourceMap[id] = res
+		}
+		return nil
+	}
+	_, err := i.Database().Exec(`UPDATE RESOURCES SET ACCESSED = ? WHERE ID = ?`, accessed.Unix(), id)
+	return err
+}
+
+// PendingPrompts 返回正在等待回答的 Prompt 数量
+func (i *Instant) PendingPrompts() int {
+	i.promptsMu.Lock()
+	defer i.promptsMu.Unlock()
+	return len(i.prompts)
+}
+
+// MemoryCommandLogSize 是内存中最多保留的命令调用记录数
+const MemoryCommandLogSize = memoryCommandLogSize
+
+// RecordCommand 直接写入一条命令调用记录
+func (i *Instant) RecordCommand(r command.Record) {
+	i.recordCommand(r)
+}
+
+// SetResourcePath 修改配置中的资源目录
+func (i *Instant) SetResourcePath(path string) {
+	i.config.ResourcePath = path
+}
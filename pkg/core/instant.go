@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"syscall"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
 	"github.com/Jel1ySpot/GoroBot/pkg/util"
@@ -33,8 +35,47 @@ type Instant struct {
 	middleware *MiddlewareSystem
 	commands   *command.System
 
+	messageStore   MessageStore
+	messageStoreMu sync.RWMutex
+
+	roles       map[roleKey]entity.Authority
+	rolesMu     sync.RWMutex
+	rolesLoaded bool
+
+	sendLimiters       map[string]*util.TokenBucket
+	sendLimitOverrides map[string]SendLimitConfig
+	sendLimitersMu     sync.Mutex
+
+	prompts   map[promptKey]*promptSession
+	promptsMu sync.Mutex
+
+	channelPolicies       map[string]ChannelPolicy
+	channelPoliciesMu     sync.RWMutex
+	channelPoliciesLoaded bool
+
+	initializing   []string // 正在初始化的服务名，Init 中注册的命令归属于栈顶的服务
+	initializingMu sync.Mutex
+
+	commandLog      []CommandRecord // 没有连接数据库时使用的环形缓冲区
+	commandLogNext  int             // 缓冲区写满后下一条记录覆盖的位置，即最旧的记录
+	commandLogReady bool
+	commandLogMu    sync.Mutex
+
+	resourceStore    ResourceStore
+	resourceLimit    *ResourceLimitConfig
+	resourceThumb    *int
+	resourceDownload *ResourceDownloadConfig
+	resourcePool     *resourcePool
+	resourceStoreMu  sync.Mutex
+	resourceGCMu     sync.Mutex
+
+	// 正在进行的资源下载，按资源 ID 索引
+	resourceFlights  map[string]*resourceFlight
+	resourceFlightMu sync.Mutex
+
 	// 没有连接数据库时使用
 	resourceMap map[string]Resource
+	resourceMu  sync.Mutex
 }
 
 func Create() *Instant {
@@ -54,11 +95,40 @@ func Create() *Instant {
 			LogLevel: logger.Info,
 		},
 
-		resourceMap: make(map[string]Resource),
+		resourceMap:     make(map[string]Resource),
+		resourceFlights: make(map[string]*resourceFlight),
+		roles:           make(map[roleKey]entity.Authority),
+
+		sendLimiters:       make(map[string]*util.TokenBucket),
+		sendLimitOverrides: make(map[string]SendLimitConfig),
+
+		prompts: make(map[promptKey]*promptSession),
+
+		channelPolicies: make(map[string]ChannelPolicy),
 	}
 
 	inst.EventRegister("message")
 	inst.EventRegister("command")
+	for _, name := range event.Names() {
+		inst.EventRegister(name)
+	}
+
+	inst.commands.SetAuthorityResolver(func(ctx *command.Context) entity.Authority {
+		return inst.Authority(ctx)
+	})
+	inst.commands.SetPrompter(inst.Prompt)
+	inst.commands.SetRecorder(inst.recordCommand)
+	inst.commands.SetFilter(func(ctx *command.Context, reg *command.Registry) bool {
+		return inst.CommandAvailable(ctx, reg.Schema.Name, reg.Plugin)
+	})
+	inst.commands.SetSuggestPolicy(func(ctx *command.Context) bool {
+		return inst.SuggestEnabled(ctx)
+	})
+	inst.initRoleCmd()
+	inst.initRateLimitCmd()
+	inst.initStatsCmd()
+	inst.initChannelCmd()
+	inst.initResourceCmd()
 
 	return &inst
 }
@@ -108,7 +178,7 @@ func (i *Instant) initServices() error {
 
 	for _, service := range services {
 		i.logger.Debug("Initializing service %s", service.Name())
-		if err := service.Init(i); err != nil {
+		if err := i.InitService(service); err != nil {
 			i.logger.Failed("Failed to initialize service %s: %v", service.Name(), err)
 			continue
 		}
@@ -117,6 +187,32 @@ func (i *Instant) initServices() error {
 	return nil
 }
 
+// InitService 调用服务的 Init，期间注册的命令归属于该服务，可以在会话设置中按服务禁用。
+// 动态加载插件时应使用它代替直接调用 Init
+func (i *Instant) InitService(service Service) error {
+	i.initializingMu.Lock()
+	i.initializing = append(i.initializing, service.Name())
+	i.initializingMu.Unlock()
+
+	defer func() {
+		i.initializingMu.Lock()
+		i.initializing = i.initializing[:len(i.initializing)-1]
+		i.initializingMu.Unlock()
+	}()
+
+	return service.Init(i)
+}
+
+// initializingService 返回正在初始化的服务名，没有时为空
+func (i *Instant) initializingService() string {
+	i.initializingMu.Lock()
+	defer i.initializingMu.Unlock()
+	if len(i.initializing) == 0 {
+		return ""
+	}
+	return i.initializing[len(i.initializing)-1]
+}
+
 func (i *Instant) releaseServices() {
 	i.servicesMu.RLock()
 	services := make([]Service, len(i.services))
@@ -146,20 +242,52 @@ func (i *Instant) AddContext(context botc.BotContext) bool {
 	return true
 }
 
-func (i *Instant) GetContext(protocol string) botc.BotContext {
+// GetContext 按 ID 获取 bot 上下文，找不到时退回到该协议下的第一个上下文
+func (i *Instant) GetContext(id string) botc.BotContext {
 	i.contextsMu.RLock()
 	defer i.contextsMu.RUnlock()
-	if context, ok := i.contexts[protocol]; ok {
+	if context, ok := i.contexts[id]; ok {
 		return context
 	}
+	if contexts := i.contextsByProtocol(id); len(contexts) > 0 {
+		return contexts[0]
+	}
 	return nil
 }
 
-func (i *Instant) RemoveContext(protocol string) bool {
+// Contexts 返回所有已注册的 bot 上下文，按 ID 排序
+func (i *Instant) Contexts() []botc.BotContext {
+	i.contextsMu.RLock()
+	defer i.contextsMu.RUnlock()
+	return i.contextsByProtocol("")
+}
+
+// ContextsByProtocol 返回指定协议下的所有 bot 上下文，按 ID 排序
+func (i *Instant) ContextsByProtocol(protocol string) []botc.BotContext {
+	i.contextsMu.RLock()
+	defer i.contextsMu.RUnlock()
+	return i.contextsByProtocol(protocol)
+}
+
+// contextsByProtocol 需在持有 contextsMu 时调用，protocol 为空时返回全部
+func (i *Instant) contextsByProtocol(protocol string) []botc.BotContext {
+	var contexts []botc.BotContext
+	for _, context := range i.contexts {
+		if protocol == "" || context.Protocol() == protocol {
+			contexts = append(contexts, context)
+		}
+	}
+	sort.Slice(contexts, func(a, b int) bool {
+		return contexts[a].ID() < contexts[b].ID()
+	})
+	return contexts
+}
+
+func (i *Instant) RemoveContext(id string) bool {
 	i.contextsMu.Lock()
 	defer i.contextsMu.Unlock()
-	if _, ok := i.contexts[protocol]; ok {
-		delete(i.contexts, protocol)
+	if _, ok := i.contexts[id]; ok {
+		delete(i.contexts, id)
 		return true
 	}
 	return false
@@ -192,6 +320,7 @@ func (i *Instant) Run() error {
 		return err
 	}
 	defer i.releaseServices()
+	defer i.startResourceGC()()
 
 	waitForInterrupt()
 
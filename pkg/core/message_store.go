@@ -0,0 +1,152 @@
+package GoroBot
+
+import (
+	"errors"
+	"sync"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// MessageStore 保存机器人收发的消息，供没有历史消息接口的平台查询。
+// botID 为 BotContext.ID()，chatID 为群组或用户 ID
+type MessageStore interface {
+	Save(botID string, chatID string, msg *botc.BaseMessage) error
+	Get(botID string, msgID string) (*botc.BaseMessage, error)
+	// History 返回 before 之前的最多 limit 条消息，按时间从早到晚排列，before 为空时从最新一条开始
+	History(botID string, chatID string, before string, limit int) ([]*botc.BaseMessage, error)
+}
+
+// UseMessageStore 启用消息存储，之后所有经过 MessageEmit / CommandEmit 的消息都会被保存
+func (i *Instant) UseMessageStore(store MessageStore) {
+	i.messageStoreMu.Lock()
+	defer i.messageStoreMu.Unlock()
+	i.messageStore = store
+}
+
+// MessageStore 返回当前使用的消息存储，未启用时为 nil
+func (i *Instant) MessageStore() MessageStore {
+	i.messageStoreMu.RLock()
+	defer i.messageStoreMu.RUnlock()
+	return i.messageStore
+}
+
+// StoreMessage 保存一条消息，供适配器记录机器人发出的消息，未启用消息存储时忽略
+func (i *Instant) StoreMessage(botID string, chatID string, msg *botc.BaseMessage) {
+	store := i.MessageStore()
+	if store == nil || msg == nil || chatID == "" {
+		return
+	}
+	if err := store.Save(botID, chatID, msg); err != nil {
+		i.logger.Error("save message %s failed: %v", msg.ID, err)
+	}
+}
+
+// StoredMessage 从消息存储中查找消息，未启用消息存储时返回 botc.ErrUnsupported
+func (i *Instant) StoredMessage(botID string, msgID string) (*botc.BaseMessage, error) {
+	store := i.MessageStore()
+	if store == nil {
+		return nil, botc.ErrUnsupported
+	}
+	return store.Get(botID, msgID)
+}
+
+// StoredHistory 从消息存储中获取历史消息，未启用消息存储时返回 botc.ErrUnsupported
+func (i *Instant) StoredHistory(botID string, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	store := i.MessageStore()
+	if store == nil {
+		return nil, botc.ErrUnsupported
+	}
+	return store.History(botID, chatID, before, limit)
+}
+
+func (i *Instant) storeIncoming(msg botc.MessageContext) {
+	bot := msg.BotContext()
+	base := msg.Message()
+	if bot == nil || base == nil {
+		return
+	}
+	i.StoreMessage(bot.ID(), base.ChatID(), base)
+}
+
+// MemoryMessageStore 是基于内存的消息存储，每个会话最多保留 perChat 条消息
+type MemoryMessageStore struct {
+	perChat int
+	chats   map[string][]*botc.BaseMessage
+	index   map[string]string // botID + msgID -> 会话键
+	mu      sync.RWMutex
+}
+
+func NewMemoryMessageStore(perChat int) *MemoryMessageStore {
+	return &MemoryMessageStore{
+		perChat: perChat,
+		chats:   make(map[string][]*botc.BaseMessage),
+		index:   make(map[string]string),
+	}
+}
+
+func (s *MemoryMessageStore) Save(botID string, chatID string, msg *botc.BaseMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := storeKey(botID, chatID)
+	if _, exists := s.index[storeKey(botID, msg.ID)]; exists {
+		return nil
+	}
+
+	list := append(s.chats[key], msg)
+	if s.perChat > 0 && len(list) > s.perChat {
+		for _, old := range list[:len(list)-s.perChat] {
+			delete(s.index, storeKey(botID, old.ID))
+		}
+		list = append([]*botc.BaseMessage(nil), list[len(list)-s.perChat:]...)
+	}
+	s.chats[key] = list
+	s.index[storeKey(botID, msg.ID)] = key
+	return nil
+}
+
+func (s *MemoryMessageStore) Get(botID string, msgID string) (*botc.BaseMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.index[storeKey(botID, msgID)]
+	if !ok {
+		return nil, ErrMessageNotFound
+	}
+	for _, msg := range s.chats[key] {
+		if msg.ID == msgID {
+			return msg, nil
+		}
+	}
+	return nil, ErrMessageNotFound
+}
+
+func (s *MemoryMessageStore) History(botID string, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.chats[storeKey(botID, chatID)]
+	if before != "" {
+		end := -1
+		for idx, msg := range list {
+			if msg.ID == before {
+				end = idx
+				break
+			}
+		}
+		if end < 0 {
+			return nil, ErrMessageNotFound
+		}
+		list = list[:end]
+	}
+	if limit > 0 && len(list) > limit {
+		list = list[len(list)-limit:]
+	}
+	return append([]*botc.BaseMessage(nil), list...), nil
+}
+
+func storeKey(botID string, id string) string {
+	return botID + "\x00" + id
+}
@@ -0,0 +1,145 @@
+package GoroBot_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func saveMessages(t *testing.T, store GoroBot.MessageStore, botID, chatID string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if err := store.Save(botID, chatID, &botc.BaseMessage{ID: id, Content: "content " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func messageIDs(messages []*botc.BaseMessage) string {
+	ids := make([]string, len(messages))
+	for n, msg := range messages {
+		ids[n] = msg.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestMemoryMessageStoreHistory(t *testing.T) {
+	store := GoroBot.NewMemoryMessageStore(0)
+	saveMessages(t, store, "bot", "chat", "1", "2", "3", "4", "5")
+
+	tests := []struct {
+		before string
+		limit  int
+		want   string
+	}{
+		{"", 0, "[1 2 3 4 5]"},
+		{"", 2, "[4 5]"},
+		{"4", 0, "[1 2 3]"},
+		{"4", 2, "[2 3]"},
+		{"1", 5, "[]"},
+	}
+	for _, tt := range tests {
+		got, err := store.History("bot", "chat", tt.before, tt.limit)
+		if err != nil {
+			t.Fatalf("before %q limit %d: %v", tt.before, tt.limit, err)
+		}
+		if ids := messageIDs(got); ids != tt.want {
+			t.Errorf("before %q limit %d: got %s, want %s", tt.before, tt.limit, ids, tt.want)
+		}
+	}
+
+	if _, err := store.History("bot", "chat", "missing", 0); !errors.Is(err, GoroBot.ErrMessageNotFound) {
+		t.Fatalf("unknown anchor: got %v", err)
+	}
+
+	// 返回的是副本，修改不影响存储
+	got, _ := store.History("bot", "chat", "", 0)
+	got[0] = nil
+	if again, _ := store.History("bot", "chat", "", 0); again[0] == nil {
+		t.Fatal("history shares its slice with the store")
+	}
+}
+
+func TestMemoryMessageStoreGet(t *testing.T) {
+	store := GoroBot.NewMemoryMessageStore(0)
+	saveMessages(t, store, "bot", "chat", "1")
+
+	msg, err := store.Get("bot", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "content 1" {
+		t.Fatalf("got %q", msg.Content)
+	}
+
+	// 消息 ID 只在同一个机器人内有效
+	if _, err := store.Get("other", "1"); !errors.Is(err, GoroBot.ErrMessageNotFound) {
+		t.Fatalf("other bot: got %v", err)
+	}
+
+	// 重复保存同一条消息时忽略
+	saveMessages(t, store, "bot", "chat", "1")
+	if got, _ := store.History("bot", "chat", "", 0); len(got) != 1 {
+		t.Fatalf("duplicate message saved: %s", messageIDs(got))
+	}
+}
+
+func TestMemoryMessageStorePerChat(t *testing.T) {
+	store := GoroBot.NewMemoryMessageStore(3)
+	saveMessages(t, store, "bot", "a", "a1", "a2", "a3", "a4", "a5")
+	saveMessages(t, store, "bot", "b", "b1")
+	saveMessages(t, store, "other", "a", "o1")
+
+	got, err := store.History("bot", "a", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := messageIDs(got); ids != "[a3 a4 a5]" {
+		t.Fatalf("got %s, want the newest 3 messages", ids)
+	}
+	if _, err := store.Get("bot", "a1"); !errors.Is(err, GoroBot.ErrMessageNotFound) {
+		t.Fatalf("dropped message still indexed: %v", err)
+	}
+
+	// 不同会话与不同机器人互不影响
+	if got, _ := store.History("bot", "b", "", 0); messageIDs(got) != "[b1]" {
+		t.Fatalf("chat b: got %s", messageIDs(got))
+	}
+	if got, _ := store.History("other", "a", "", 0); messageIDs(got) != "[o1]" {
+		t.Fatalf("other bot: got %s", messageIDs(got))
+	}
+}
+
+func TestStoredHistory(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+	group := testkit.GroupID("g")
+
+	if _, err := bot.GetHistory(botc.GroupMessage, group, "", 10); !errors.Is(err, botc.ErrUnsupported) {
+		t.Fatalf("without a message store: got %v", err)
+	}
+
+	grb.UseMessageStore(GoroBot.NewMemoryMessageStore(10))
+	in := bot.NewTextMessage("hello").InGroup(group)
+	if err := bot.Emit(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.NewMessageBuilder().Text("world").Send(group); err != nil {
+		t.Fatal(err)
+	}
+
+	// 收到与发出的消息都会保存
+	got, err := bot.GetHistory(botc.GroupMessage, group, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != in.Message().ID || got[1].Content != "world" {
+		t.Fatalf("unexpected history %s", messageIDs(got))
+	}
+	if msg, err := bot.GetMessage(in.Message().ID); err != nil || msg.Content != "hello" {
+		t.Fatalf("GetMessage: %v, %v", msg, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 	"github.com/google/uuid"
 )
 
@@ -90,3 +91,9 @@ func (sys *MiddlewareSystem) dispatch(msg botc.MessageContext, fn func() error)
 
 	return callback()
 }
+
+// CommandMiddleware 注册命令中间件，它在命令解析、权限检查和冷却之后、处理函数之前执行，
+// 可以读取解析结果、跳过处理函数或修改返回的错误，被跳过的调用同样会进入冷却。返回注销函数
+func (i *Instant) CommandMiddleware(middleware command.Middleware) func() {
+	return i.commands.Use(middleware)
+}
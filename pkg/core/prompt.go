@@ -0,0 +1,135 @@
+package GoroBot
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+var (
+	ErrPromptTimeout  = errors.New("prompt timed out")
+	ErrPromptCanceled = errors.New("prompt canceled")
+)
+
+var defaultPromptCancel = []string{"取消", "cancel"}
+
+// PromptFilter 决定一条消息是否作为回答，返回 false 的消息按普通消息处理
+type PromptFilter = func(msg botc.MessageContext) bool
+
+type promptKey struct {
+	botID    string
+	chatID   string
+	senderID string
+}
+
+type promptSession struct {
+	filter PromptFilter
+	answer chan botc.MessageContext
+	cancel chan struct{}
+}
+
+// Prompt 等待 ctx 的发送者在同一会话中发送的下一条消息，该消息不会再触发命令和消息事件。
+// timeout 不大于 0 时不超时，filter 为 nil 时接受任何消息。
+// 发送者回复取消关键词时返回 ErrPromptCanceled，超时返回 ErrPromptTimeout；
+// 同一发送者在同一会话中开始新的 Prompt 时，之前的 Prompt 也会返回 ErrPromptCanceled
+func (i *Instant) Prompt(ctx botc.MessageContext, timeout time.Duration, filter PromptFilter) (botc.MessageContext, error) {
+	key, ok := promptKeyOf(ctx)
+	if !ok {
+		return nil, errors.New("cannot prompt without sender")
+	}
+
+	session := &promptSession{
+		filter: filter,
+		answer: make(chan botc.MessageContext, 1),
+		cancel: make(chan struct{}),
+	}
+
+	i.promptsMu.Lock()
+	if previous, ok := i.prompts[key]; ok {
+		close(previous.cancel)
+	}
+	i.prompts[key] = session
+	i.promptsMu.Unlock()
+
+	defer func() {
+		i.promptsMu.Lock()
+		if i.prompts[key] == session {
+			delete(i.prompts, key)
+		}
+		i.promptsMu.Unlock()
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case msg := <-session.answer:
+		return msg, nil
+	case <-session.cancel:
+		return nil, ErrPromptCanceled
+	case <-expired:
+		return nil, ErrPromptTimeout
+	}
+}
+
+// deliverPrompt 将消息交给等待中的 Prompt，返回消息是否已被消费
+func (i *Instant) deliverPrompt(msg botc.MessageContext) bool {
+	key, ok := promptKeyOf(msg)
+	if !ok {
+		return false
+	}
+
+	cancelWords := i.config.PromptCancel
+	if cancelWords == nil {
+		cancelWords = defaultPromptCancel
+	}
+	canceled := slices.Contains(cancelWords, strings.TrimSpace(msg.String()))
+
+	for {
+		i.promptsMu.Lock()
+		session, ok := i.prompts[key]
+		i.promptsMu.Unlock()
+		if !ok {
+			return false
+		}
+
+		// filter 由插件提供，可能再次调用 Prompt 或派发消息，不能在持有 promptsMu 时执行
+		if !canceled && session.filter != nil && !session.filter(msg) {
+			return false
+		}
+
+		i.promptsMu.Lock()
+		if i.prompts[key] != session {
+			// 执行 filter 期间会话被新的 Prompt 取代或已经结束，重新查找
+			i.promptsMu.Unlock()
+			continue
+		}
+		delete(i.prompts, key)
+		if canceled {
+			close(session.cancel)
+		} else {
+			session.answer <- msg
+		}
+		i.promptsMu.Unlock()
+		return true
+	}
+}
+
+func promptKeyOf(msg botc.MessageContext) (promptKey, bool) {
+	base := msg.Message()
+	if base == nil || msg.BotContext() == nil || msg.SenderID() == "" {
+		return promptKey{}, false
+	}
+	return promptKey{
+		botID:    msg.BotContext().ID(),
+		chatID:   base.ChatID(),
+		senderID: msg.SenderID(),
+	}, true
+}
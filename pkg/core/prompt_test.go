@@ -0,0 +1,280 @@
+package GoroBot_test
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func isNumber(msg botc.MessageContext) bool {
+	text := msg.String()
+	return text != "" && strings.IndexFunc(text, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// newPromptBot 注册 ask（不过滤）、num（只接受数字）和 quick（50ms 超时）三个命令，
+// 返回的计数器记录没有被 Prompt 消费的非命令消息
+func newPromptBot(t *testing.T) (*GoroBot.Instant, *testkit.Bot, *atomic.Int32) {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+
+	for name, args := range map[string]struct {
+		timeout time.Duration
+		filter  GoroBot.PromptFilter
+	}{
+		"ask":   {time.Minute, nil},
+		"num":   {time.Minute, isNumber},
+		"quick": {50 * time.Millisecond, nil},
+	} {
+		if _, err := grb.Command(name).Action(func(ctx *command.Context) error {
+			msg, err := ctx.Prompt(args.timeout, args.filter)
+			if err != nil {
+				_, _ = ctx.ReplyText(err.Error())
+				return nil
+			}
+			_, _ = ctx.ReplyText("answer: " + msg.String())
+			return nil
+		}).Build(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var messages atomic.Int32
+	if _, err := grb.On(GoroBot.MessageEvent(func(ctx botc.MessageContext) {
+		if !strings.HasPrefix(ctx.String(), "/") {
+			messages.Add(1)
+		}
+	})); err != nil {
+		t.Fatal(err)
+	}
+	return grb, bot, &messages
+}
+
+// waitMessages 等待计数器达到 n，消息事件是异步派发的
+func waitMessages(t *testing.T, messages *atomic.Int32, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(testkit.DefaultWaitTimeout)
+	for messages.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d messages emitted, want %d", messages.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := messages.Load(); got != n {
+		t.Fatalf("%d messages emitted, want %d", got, n)
+	}
+}
+
+// startPrompt 触发命令并等待 Prompt 开始等待回答
+func startPrompt(t *testing.T, grb *GoroBot.Instant, bot *testkit.Bot, msg *testkit.MessageContext, name string) {
+	t.Helper()
+	want := grb.PendingPrompts() + 1
+	bot.EmitCommand(msg, name)
+	deadline := time.Now().Add(testkit.DefaultWaitTimeout)
+	for grb.PendingPrompts() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: prompt did not start", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// answer 发送一条普通消息并返回机器人的下一条回复
+func answer(t *testing.T, bot *testkit.Bot, msg *testkit.MessageContext) string {
+	t.Helper()
+	bot.Reset()
+	if err := bot.Emit(msg); err != nil {
+		t.Fatal(err)
+	}
+	out, err := bot.WaitOutbound(1)
+	if err != nil {
+		t.Fatalf("%s: %v", msg.String(), err)
+	}
+	return out[0].Text()
+}
+
+func TestPromptAnswer(t *testing.T) {
+	grb, bot, messages := newPromptBot(t)
+
+	startPrompt(t, grb, bot, bot.NewTextMessage("/ask"), "ask")
+	if got := answer(t, bot, bot.NewTextMessage("北京")); got != "answer: 北京" {
+		t.Fatalf("got %q", got)
+	}
+
+	// 回答作为命令派发时同样被消费，不会执行命令
+	startPrompt(t, grb, bot, bot.NewTextMessage("/ask"), "ask")
+	if got := bot.RunCommand(t, bot.NewTextMessage("/quick"), "quick"); got != "answer: /quick" {
+		t.Fatalf("command as answer: got %q", got)
+	}
+
+	waitMessages(t, messages, 0)
+	if n := grb.PendingPrompts(); n != 0 {
+		t.Fatalf("%d prompts left", n)
+	}
+}
+
+func TestPromptCancel(t *testing.T) {
+	grb, bot, messages := newPromptBot(t)
+
+	for _, word := range []string{"取消", " cancel "} {
+		startPrompt(t, grb, bot, bot.NewTextMessage("/num"), "num")
+		// 取消关键词不经过 filter
+		if got := answer(t, bot, bot.NewTextMessage(word)); got != GoroBot.ErrPromptCanceled.Error() {
+			t.Fatalf("%q: got %q", word, got)
+		}
+	}
+	waitMessages(t, messages, 0)
+}
+
+func TestPromptTimeout(t *testing.T) {
+	grb, bot, messages := newPromptBot(t)
+
+	if got := bot.RunCommand(t, bot.NewTextMessage("/quick"), "quick"); got != GoroBot.ErrPromptTimeout.Error() {
+		t.Fatalf("got %q", got)
+	}
+	if n := grb.PendingPrompts(); n != 0 {
+		t.Fatalf("%d prompts left after timeout", n)
+	}
+
+	// 超时后的消息按普通消息处理
+	if err := bot.Emit(bot.NewTextMessage("late")); err != nil {
+		t.Fatal(err)
+	}
+	waitMessages(t, messages, 1)
+}
+
+func TestPromptSupersede(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := grb.Prompt(bot.NewTextMessage("question"), time.Minute, nil)
+		first <- err
+	}()
+	deadline := time.Now().Add(testkit.DefaultWaitTimeout)
+	for grb.PendingPrompts() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("prompt did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 同一发送者在同一会话中开始新的 Prompt 时，之前的 Prompt 被取消
+	second := make(chan error, 1)
+	go func() {
+		_, err := grb.Prompt(bot.NewTextMessage("question"), time.Minute, nil)
+		second <- err
+	}()
+	if err := <-first; !errors.Is(err, GoroBot.ErrPromptCanceled) {
+		t.Fatalf("first prompt: got %v", err)
+	}
+
+	if err := bot.Emit(bot.NewTextMessage("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-second:
+		if err != nil {
+			t.Fatalf("second prompt: %v", err)
+		}
+	case <-time.After(testkit.DefaultWaitTimeout):
+		t.Fatal("second prompt did not receive the answer")
+	}
+}
+
+func TestPromptSessions(t *testing.T) {
+	grb, bot, messages := newPromptBot(t)
+	group := testkit.GroupID("g")
+	alice, bob := testkit.UserID("alice"), testkit.UserID("bob")
+
+	startPrompt(t, grb, bot, bot.NewTextMessage("/ask").From(alice).InGroup(group), "ask")
+	startPrompt(t, grb, bot, bot.NewTextMessage("/ask").From(bob).InGroup(group), "ask")
+
+	// 其他会话中的消息不会被当作回答
+	bot.Reset()
+	for _, msg := range []*testkit.MessageContext{
+		bot.NewTextMessage("other group").From(alice).InGroup(testkit.GroupID("other")),
+		bot.NewTextMessage("direct").From(alice),
+	} {
+		if err := bot.Emit(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := bot.WaitIdle(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("unexpected reply %q", out[0].Text())
+	}
+	waitMessages(t, messages, 2)
+
+	// 每个用户的回答交给自己的 Prompt
+	for _, tt := range []struct{ user, text string }{{bob, "from bob"}, {alice, "from alice"}} {
+		bot.Reset()
+		if err := bot.Emit(bot.NewTextMessage(tt.text).From(tt.user).InGroup(group)); err != nil {
+			t.Fatal(err)
+		}
+		out, err := bot.WaitOutbound(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := out[0].Text(); got != "answer: "+tt.text {
+			t.Fatalf("%s: got %q", tt.user, got)
+		}
+		if out[0].ReplyTo.SenderID() != tt.user {
+			t.Fatalf("%s's answer replied to %s", tt.user, out[0].ReplyTo.SenderID())
+		}
+	}
+}
+
+func TestPromptFilter(t *testing.T) {
+	grb, bot, messages := newPromptBot(t)
+
+	startPrompt(t, grb, bot, bot.NewTextMessage("/num"), "num")
+	if err := bot.Emit(bot.NewTextMessage("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if got := answer(t, bot, bot.NewTextMessage("42")); got != "answer: 42" {
+		t.Fatalf("got %q", got)
+	}
+	// 不符合 filter 的消息按普通消息处理
+	waitMessages(t, messages, 1)
+}
+
+func TestPromptFilterReentrant(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	// filter 中派发其他消息不会死锁
+	result := make(chan error, 1)
+	go func() {
+		_, err := grb.Prompt(bot.NewTextMessage("question"), time.Minute, func(msg botc.MessageContext) bool {
+			_ = bot.Emit(bot.NewTextMessage("side").From(testkit.UserID("other")))
+			return true
+		})
+		result <- err
+	}()
+	deadline := time.Now().Add(testkit.DefaultWaitTimeout)
+	for grb.PendingPrompts() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("prompt did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() { _ = bot.Emit(bot.NewTextMessage("answer")) }()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(testkit.DefaultWaitTimeout):
+		t.Fatal("prompt deadlocked in filter")
+	}
+}
@@ -0,0 +1,130 @@
+package GoroBot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+// SendLimitConfig 是发送消息的令牌桶配置
+type SendLimitConfig struct {
+	Rate  float64 `json:"rate"`  // 每秒可发送的消息数，不大于 0 表示不限制
+	Burst int     `json:"burst"` // 允许连续发送的消息数
+}
+
+var defaultSendLimit = SendLimitConfig{
+	Rate:  1,
+	Burst: 5,
+}
+
+// SendLimitStatus 是一个 bot 上下文当前的发送限流状态
+type SendLimitStatus struct {
+	ContextID string
+	Rate      float64
+	Burst     int
+	Tokens    float64
+	Waiting   int
+}
+
+// WaitSend 阻塞直到 contextID 对应的账号可以发送下一条消息，
+// 由适配器在每次向平台发送消息前调用
+func (i *Instant) WaitSend(contextID string) {
+	i.sendLimiter(contextID).Wait()
+}
+
+// SetSendLimit 为指定账号单独设置发送限流，覆盖配置文件中的 send_limit
+func (i *Instant) SetSendLimit(contextID string, limit SendLimitConfig) {
+	i.sendLimitersMu.Lock()
+	i.sendLimitOverrides[contextID] = limit
+	i.sendLimitersMu.Unlock()
+	i.sendLimiter(contextID)
+}
+
+// SendLimits 返回所有已发送过消息的账号的限流状态，按 ID 排序
+func (i *Instant) SendLimits() []SendLimitStatus {
+	i.sendLimitersMu.Lock()
+	defer i.sendLimitersMu.Unlock()
+	statuses := make([]SendLimitStatus, 0, len(i.sendLimiters))
+	for id, bucket := range i.sendLimiters {
+		rate, burst, tokens, waiting := bucket.Status()
+		statuses = append(statuses, SendLimitStatus{
+			ContextID: id,
+			Rate:      rate,
+			Burst:     burst,
+			Tokens:    tokens,
+			Waiting:   waiting,
+		})
+	}
+	sort.Slice(statuses, func(a, b int) bool {
+		return statuses[a].ContextID < statuses[b].ContextID
+	})
+	return statuses
+}
+
+// sendLimiter 返回账号的令牌桶，配置变化时同步更新
+func (i *Instant) sendLimiter(contextID string) *util.TokenBucket {
+	i.sendLimitersMu.Lock()
+	defer i.sendLimitersMu.Unlock()
+
+	limit, ok := i.sendLimitOverrides[contextID]
+	if !ok {
+		limit = defaultSendLimit
+		if i.config.SendLimit != nil {
+			limit = *i.config.SendLimit
+		}
+	}
+
+	bucket, ok := i.sendLimiters[contextID]
+	if !ok {
+		bucket = util.NewTokenBucket(limit.Rate, limit.Burst)
+		i.sendLimiters[contextID] = bucket
+		return bucket
+	}
+	if rate, burst, _, _ := bucket.Status(); rate != limit.Rate || burst != max(limit.Burst, 1) {
+		bucket.SetLimit(limit.Rate, limit.Burst)
+	}
+	return bucket
+}
+
+func (i *Instant) initRateLimitCmd() {
+	_, _ = i.Command("ratelimit").
+		Description("查看发送限流和命令冷却状态").
+		Permission(entity.Admin).
+		Action(func(ctx *command.Context) error {
+			var b strings.Builder
+			b.WriteString("发送限流：")
+			limits := i.SendLimits()
+			if len(limits) == 0 {
+				b.WriteString("\n  暂无发送记录")
+			}
+			for _, limit := range limits {
+				if limit.Rate <= 0 {
+					fmt.Fprintf(&b, "\n  %s  不限制", limit.ContextID)
+					continue
+				}
+				fmt.Fprintf(&b, "\n  %s  %.1f/%d 令牌，%.2g 条/秒，%d 条等待中",
+					limit.ContextID, limit.Tokens, limit.Burst, limit.Rate, limit.Waiting)
+			}
+
+			b.WriteString("\n命令冷却：")
+			cooldowns := i.commands.Cooldowns()
+			if len(cooldowns) == 0 {
+				b.WriteString("\n  没有正在冷却的命令")
+			}
+			for _, cd := range cooldowns {
+				key := cd.Key
+				if key == "" {
+					key = "全局"
+				}
+				fmt.Fprintf(&b, "\n  %s  [%s] %s  剩余 %.0f 秒", cd.Command, cd.Scope, key, cd.Remaining.Seconds())
+			}
+
+			_, _ = ctx.ReplyText(b.String())
+			return nil
+		}).
+		Build()
+}
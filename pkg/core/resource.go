@@ -1,14 +1,14 @@
 package GoroBot
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
 	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
-	"mime"
-	"net/http"
+	"io/fs"
 	urlpkg "net/url"
 	"os"
 	"path"
@@ -16,6 +16,8 @@ import (
 	"strings"
 	"time"
 
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
 	"github.com/google/uuid"
 )
 
@@ -23,12 +25,20 @@ type Resource struct {
 	ID         string    // 资源唯一标识符
 	Protocol   string    // 资源所属协议
 	RefLink    string    // 资源引用信息
-	FilePath   string    // 资源文件保存路径
+	Key        string    // 资源在 ResourceStore 中的 key，旧版本保存的资源为空
+	FilePath   string    // 资源文件的本地路径，使用远程存储时为空
 	Error      string    // 下载错误信息
 	Downloaded time.Time // 资源下载时间
+	Size       int64     // 文件大小，未下载或旧版本保存的资源为 0
+	Accessed   time.Time // 最后一次加载的时间，用于淘汰不常用的资源
+
+	ResourceMeta // 下载时检测的内容信息，未下载或旧版本保存的资源为空
 }
 
-// ResourceDownloader 由各协议适配器实现，用于根据 refLink 下载资源到本地
+// 查询 RESOURCES 表时使用的列，与 scanResource 对应
+const resourceColumns = `ID, PROTOCOL, REF_LINK, COALESCE(STORE_KEY, ''), PATH, ERROR, TIME, COALESCE(SIZE, 0), COALESCE(ACCESSED, TIME), ` +
+	`COALESCE(MIME, ''), COALESCE(SHA256, ''), COALESCE(WIDTH, 0), COALESCE(HEIGHT, 0), COALESCE(DURATION, 0), COALESCE(THUMBNAIL, '')`
+
 // SaveResourceLink 存储资源引用并返回生成的资源 ID
 func (i *Instant) SaveResourceLink(contextID string, refLink string) string {
 	id := uuid.NewString()
@@ -38,10 +48,13 @@ func (i *Instant) SaveResourceLink(contextID string, refLink string) string {
 		Protocol:   contextID,
 		RefLink:    refLink,
 		Downloaded: now,
+		Accessed:   now,
 	}
-	i.resourceMap[id] = res
 
 	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		i.resourceMap[id] = res
+		i.resourceMu.Unlock()
 		return id
 	}
 
@@ -51,105 +64,245 @@ func (i *Instant) SaveResourceLink(contextID string, refLink string) string {
 		return id
 	}
 
-	if _, err := db.Exec(`INSERT INTO RESOURCES (ID, PROTOCOL, REF_LINK, PATH, ERROR, TIME) VALUES (?, ?, ?, ?, ?, ?)`, res.ID, res.Protocol, res.RefLink, "", "", res.Downloaded.Unix()); err != nil {
+	if _, err := db.Exec(`INSERT INTO RESOURCES (ID, PROTOCOL, REF_LINK, STORE_KEY, PATH, ERROR, TIME, SIZE, ACCESSED) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, res.ID, res.Protocol, res.RefLink, "", "", "", res.Downloaded.Unix(), 0, res.Accessed.Unix()); err != nil {
 		i.logger.Error("insert resource link failed: %v", err)
 	}
 
 	return id
 }
 
-// LoadResourceFromID 使用资源 ID 加载本地文件路径，必要时通过协议适配器下载
+// LoadResourceFromID 使用资源 ID 加载本地文件路径，必要时通过协议适配器下载。
+// 适配器下载的文件会保存到 ResourceStore 中，使用远程存储时返回本地缓存的路径
 func (i *Instant) LoadResourceFromID(id string) (string, error) {
-	res, ok := i.resourceMap[id]
+	return i.LoadResourceContext(context.Background(), id, ResourceOptions{})
+}
 
-	if dbRes, err := i.loadResourceFromDB(id); err == nil {
-		res = dbRes
-		ok = true
+// LoadResourceContext 与 LoadResourceFromID 相同，下载可以通过 ctx 取消，并按 opts 限制大小和报告进度
+func (i *Instant) LoadResourceContext(ctx context.Context, id string, opts ResourceOptions) (string, error) {
+	res, err := i.lookupResource(id)
+	if err != nil {
+		return "", err
 	}
 
-	if !ok {
-		return "", fmt.Errorf("resource id %s not found", id)
+	if res.Key != "" {
+		filePath, err := i.resourceFile(res.Key)
+		// 内容被淘汰后，还有引用信息的资源可以重新下载
+		if err == nil || !errors.Is(err, fs.ErrNotExist) || res.RefLink == "" {
+			if err == nil {
+				i.touchResource(id)
+			}
+			return filePath, err
+		}
 	}
 
-	if res.FilePath != "" {
+	// 旧版本保存的资源直接记录了文件路径
+	if res.Key == "" && res.FilePath != "" {
 		if _, err := os.Stat(res.FilePath); err == nil {
+			i.touchResource(id)
 			return res.FilePath, nil
 		}
 	}
 
 	if res.Error != "" {
-		return "", fmt.Errorf(res.Error)
+		return "", errors.New(res.Error)
+	}
+
+	if res, err = i.loadRemoteResource(ctx, res, opts); err != nil {
+		return "", err
+	}
+	return i.resourceFile(res.Key)
+}
+
+// lookupResource 从内存或数据库中查找资源索引
+func (i *Instant) lookupResource(id string) (Resource, error) {
+	i.resourceMu.Lock()
+	res, ok := i.resourceMap[id]
+	i.resourceMu.Unlock()
+
+	if dbRes, err := i.loadResourceFromDB(id); err == nil {
+		res = dbRes
+		ok = true
 	}
 
-	i.contextsMu.RLock()
-	downloader, ok := i.contexts[res.Protocol]
-	i.contextsMu.RUnlock()
 	if !ok {
-		return "", fmt.Errorf("no downloader registered for protocol %s", res.Protocol)
+		return Resource{}, fmt.Errorf("resource id %s not found", id)
 	}
+	return res, nil
+}
 
-	targetPath := buildTargetPath(id, res.RefLink)
-	refLink := withTarget(res.RefLink, targetPath)
+// downloadResource 通过保存资源的适配器下载资源，保存到 ResourceStore 并更新索引。
+// 下载占用下载池中资源所属协议的名额，失败时按 resource_download 的设置重试，
+// 重试后仍然失败的错误会记录到索引中
+func (i *Instant) downloadResource(ctx context.Context, res Resource, transfer util.TransferOptions) (Resource, error) {
+	// Protocol 记录的是保存资源的上下文 ID，旧数据中可能是协议名
+	downloader := i.GetContext(res.Protocol)
+	if downloader == nil {
+		return res, fmt.Errorf("no downloader registered for context %s", res.Protocol)
+	}
+	protocol, _, _ := strings.Cut(res.Protocol, ":")
 
-	path, err := downloader.DownloadResourceFromRefLink(refLink)
+	var (
+		key  string
+		size int64
+	)
+	err := i.retryDownload(ctx, func() error {
+		release, err := i.downloadPool().acquire(ctx, protocol)
+		if err != nil {
+			return err
+		}
+		defer release()
+		key, size, err = i.fetchResourceContent(ctx, downloader, res, transfer)
+		return err
+	})
 	if err != nil {
-		_ = i.updateResourcePathOrError(id, targetPath, err.Error())
-		return "", err
+		if ctx.Err() == nil && !errors.Is(err, util.ErrTooLarge) {
+			_ = i.updateResource(Resource{ID: res.ID, Error: err.Error()})
+		}
+		return res, err
+	}
+
+	res.Key, res.Size, res.FilePath, res.Error = key, size, i.resourceStorePath(key), ""
+	meta, err := i.resourceMeta(res)
+	if err != nil {
+		i.logger.Warning("detect resource %s meta failed: %v", res.ID, err)
+	}
+	res.ResourceMeta = meta
+	if err := i.updateResource(res); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// fetchResourceContent 尝试一次下载并保存到 ResourceStore。
+// 适配器实现了 botc.ResourceOpener 时直接从内容流保存，否则先由适配器下载到磁盘
+func (i *Instant) fetchResourceContent(ctx context.Context, downloader botc.BotContext, res Resource, transfer util.TransferOptions) (string, int64, error) {
+	if opener, ok := downloader.(botc.ResourceOpener); ok {
+		body, total, err := opener.OpenResourceFromRefLink(ctx, res.RefLink)
+		if err != nil {
+			return "", 0, err
+		}
+		defer body.Close()
+		reader := util.NewTransferReader(body, total, transfer)
+		key, err := i.ResourceStore().Put(sniffResource(reader, resourceExt(res.RefLink)))
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to download resource %s: %w", res.ID, err)
+		}
+		return key, reader.Done(), nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+	targetPath := i.buildTargetPath(res.ID, res.RefLink)
+	path, err := downloader.DownloadResourceFromRefLink(withTarget(res.RefLink, targetPath))
+	if err != nil {
+		return "", 0, err
+	}
 	if path == "" {
 		path = targetPath
 	}
 
-	if err := i.updateResourcePathOrError(id, path, ""); err != nil {
-		return "", err
+	// 适配器写入 targetPath 的文件在保存到 ResourceStore 后删除，其他路径是适配器自己的文件
+	if info, err := os.Stat(path); err == nil && transfer.MaxSize > 0 && info.Size() > transfer.MaxSize {
+		if path == targetPath {
+			_ = os.Remove(path)
+		}
+		return "", 0, fmt.Errorf("failed to download resource %s: %w", res.ID, util.ErrTooLarge)
+	}
+	key, size, err := i.importResourceFile(path, path == targetPath)
+	if err != nil {
+		return "", 0, err
 	}
+	if transfer.Progress != nil {
+		transfer.Progress(size, size)
+	}
+	return key, size, nil
+}
 
-	return path, nil
+// importResourceFile 将本地文件保存到 ResourceStore，remove 为 true 时随后删除原文件
+func (i *Instant) importResourceFile(path string, remove bool) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	key, err := i.ResourceStore().Put(sniffResource(file, filepath.Ext(path)))
+	_ = file.Close()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to store resource file %s: %v", path, err)
+	}
+	if remove {
+		_ = os.Remove(path)
+	}
+	return key, size, nil
 }
 
-func (i *Instant) loadResourceFromDB(id string) (Resource, error) {
+// touchResource 更新资源的最后访问时间
+func (i *Instant) touchResource(id string) {
+	now := time.Now()
 	if !i.DatabaseExist() {
-		return Resource{}, fmt.Errorf("database not available")
+		i.resourceMu.Lock()
+		if res, ok := i.resourceMap[id]; ok {
+			res.Accessed = now
+			i.resourceMap[id] = res
+		}
+		i.resourceMu.Unlock()
+		return
 	}
-	db := i.Database()
-	if err := ensureResourceTable(db); err != nil {
-		return Resource{}, err
+	if _, err := i.Database().Exec(`UPDATE RESOURCES SET ACCESSED = ? WHERE ID = ?`, now.Unix(), id); err != nil {
+		i.logger.Error("update resource %s access time failed: %v", id, err)
 	}
+}
 
+// scanResource 读取一行按 resourceColumns 查询的结果
+func scanResource(row interface{ Scan(...any) error }) (Resource, error) {
 	var (
-		protocol string
-		refLink  string
-		path     string
-		errMsg   string
+		res      Resource
 		timeUnix int64
+		accessed int64
+		duration int64
 	)
-	err := db.QueryRow(`SELECT PROTOCOL, REF_LINK, PATH, ERROR, TIME FROM RESOURCES WHERE ID = ?`, id).
-		Scan(&protocol, &refLink, &path, &errMsg, &timeUnix)
-	if err != nil {
+	if err := row.Scan(&res.ID, &res.Protocol, &res.RefLink, &res.Key, &res.FilePath, &res.Error, &timeUnix, &res.Size, &accessed,
+		&res.Mime, &res.SHA256, &res.Width, &res.Height, &duration, &res.Thumbnail); err != nil {
 		return Resource{}, err
 	}
-
-	return Resource{
-		ID:         id,
-		Protocol:   protocol,
-		RefLink:    refLink,
-		FilePath:   path,
-		Error:      errMsg,
-		Downloaded: time.Unix(timeUnix, 0),
-	}, nil
+	res.Downloaded = time.Unix(timeUnix, 0)
+	res.Accessed = time.Unix(accessed, 0)
+	res.Duration = time.Duration(duration) * time.Millisecond
+	return res, nil
 }
 
-func (i *Instant) updateResourcePathOrError(id string, path string, errMsg string) error {
-	res, ok := i.resourceMap[id]
-	if ok {
-		res.FilePath = path
-		res.Error = errMsg
-		res.Downloaded = time.Now()
-		i.resourceMap[id] = res
+func (i *Instant) loadResourceFromDB(id string) (Resource, error) {
+	if !i.DatabaseExist() {
+		return Resource{}, fmt.Errorf("database not available")
 	}
+	db := i.Database()
+	if err := ensureResourceTable(db); err != nil {
+		return Resource{}, err
+	}
+
+	return scanResource(db.QueryRow(`SELECT `+resourceColumns+` FROM RESOURCES WHERE ID = ?`, id))
+}
 
+// updateResource 记录资源的下载结果，更新 res.ID 对应索引的文件和内容信息
+func (i *Instant) updateResource(res Resource) error {
+	now := time.Now()
 	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		defer i.resourceMu.Unlock()
+		if old, ok := i.resourceMap[res.ID]; ok {
+			old.Key = res.Key
+			old.Size = res.Size
+			old.FilePath = res.FilePath
+			old.Error = res.Error
+			old.ResourceMeta = res.ResourceMeta
+			old.Downloaded = now
+			old.Accessed = now
+			i.resourceMap[res.ID] = old
+		}
 		return nil
 	}
 	db := i.Database()
@@ -157,58 +310,20 @@ func (i *Instant) updateResourcePathOrError(id string, path string, errMsg strin
 		return err
 	}
 
-	_, err := db.Exec(`UPDATE RESOURCES SET PATH = ?, ERROR = ?, TIME = ? WHERE ID = ?`, path, errMsg, time.Now().Unix(), id)
+	_, err := db.Exec(`
+UPDATE RESOURCES SET STORE_KEY = ?, SIZE = ?, PATH = ?, ERROR = ?, TIME = ?, ACCESSED = ?,
+    MIME = ?, SHA256 = ?, WIDTH = ?, HEIGHT = ?, DURATION = ?, THUMBNAIL = ?
+WHERE ID = ?`, res.Key, res.Size, res.FilePath, res.Error, now.Unix(), now.Unix(),
+		res.Mime, res.SHA256, res.Width, res.Height, res.Duration.Milliseconds(), res.Thumbnail, res.ID)
 	return err
 }
 
-// SaveRemoteResource 保存资源文件，并更新资源索引
+// SaveRemoteResource 下载资源文件保存到 ResourceStore，并更新资源索引，失败时按 resource_download 的设置重试
 func (i *Instant) SaveRemoteResource(resourceURL string) (*Resource, error) {
-	if resourceURL == "" {
-		return nil, fmt.Errorf("resourceURL is empty")
-	}
-
-	currentTime := time.Now()
-	resourceDirPath := path.Join("resources", currentTime.Format("2006/01.02"))
-
-	data, fileName, err := downloadFileWithRetry(resourceURL)
-	if err != nil {
-		return nil, err
-	}
-
-	id := strings.TrimSuffix(fileName, filepath.Ext(fileName))
-
-	if i.ResourceExists(id) {
-		return nil, fmt.Errorf("resource %s already exists", resourceURL)
-	}
-
-	filePath := path.Join(resourceDirPath, fileName)
-
-	if _, err := os.Stat(resourceDirPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(resourceDirPath, os.ModePerm); err != nil {
-			return nil, fmt.Errorf("error creating resource dir %s: %v", resourceDirPath, err)
-		}
-	}
-
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write resource file %s: %v", filePath, err)
-	}
-
-	resource := Resource{
-		ID:         id,
-		Protocol:   "local",
-		RefLink:    "",
-		FilePath:   filePath,
-		Downloaded: currentTime,
-	}
-
-	if err := i.saveResourceIndex(resource); err != nil {
-		_ = os.Remove(filePath)
-		return nil, err
-	}
-
-	return &resource, nil
+	return i.SaveRemoteResourceContext(context.Background(), resourceURL, ResourceOptions{})
 }
 
+// SaveResourceData 将数据保存到 ResourceStore，返回以内容 md5 为 ID 的资源
 func (i *Instant) SaveResourceData(data []byte, ext string) (string, error) {
 	hash := md5.Sum(data)
 	id := hex.EncodeToString(hash[:])
@@ -216,59 +331,39 @@ func (i *Instant) SaveResourceData(data []byte, ext string) (string, error) {
 	if i.ResourceExists(id) {
 		return id, nil
 	}
-	currentTime := time.Now()
-	resourceDirPath := path.Join("resources", currentTime.Format("2006/01.02"))
-	resourceFilePath := path.Join(resourceDirPath, id+"."+ext)
-
-	if err := os.MkdirAll(resourceDirPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create resource directory %s: %v", resourceDirPath, err)
-	}
-
-	resource := Resource{
-		ID:         id,
-		Protocol:   "local",
-		FilePath:   resourceFilePath,
-		Downloaded: currentTime,
-	}
-
-	if err := os.WriteFile(resourceFilePath, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write resource file %s: %v", resourceFilePath, err)
-	}
-
-	if err := i.saveResourceIndex(resource); err != nil {
-		return "", err
-	}
 
-	return id, nil
+	return i.SaveResourceReader(bytes.NewReader(data), ext)
 }
 
-// saveResourceIndex 保存资源的元数据索引到内存和数据库
+// saveResourceIndex 保存资源的元数据索引到内存和数据库。
+// 同时保存相同内容时 ID 相同，已经存在的索引保持不变
 func (i *Instant) saveResourceIndex(resource Resource) error {
-	i.resourceMap[resource.ID] = resource
-
 	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		if _, ok := i.resourceMap[resource.ID]; !ok {
+			i.resourceMap[resource.ID] = resource
+		}
+		i.resourceMu.Unlock()
 		return nil
 	}
 
 	db := i.Database()
 	if err := ensureResourceTable(db); err != nil {
-		delete(i.resourceMap, resource.ID)
 		return err
 	}
 
 	_, err := db.Exec(`
-INSERT INTO RESOURCES (ID, PROTOCOL, REF_LINK, PATH, ERROR, TIME)
-VALUES (?, ?, ?, ?, ?, ?);`, resource.ID, resource.Protocol, resource.RefLink, resource.FilePath, resource.Error, resource.Downloaded.Unix())
-	if err != nil {
-		delete(i.resourceMap, resource.ID)
-		return err
-	}
-
-	return nil
+INSERT OR IGNORE INTO RESOURCES (ID, PROTOCOL, REF_LINK, STORE_KEY, PATH, ERROR, TIME, SIZE, ACCESSED, MIME, SHA256, WIDTH, HEIGHT, DURATION, THUMBNAIL)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`, resource.ID, resource.Protocol, resource.RefLink, resource.Key, resource.FilePath, resource.Error, resource.Downloaded.Unix(), resource.Size, resource.Accessed.Unix(),
+		resource.Mime, resource.SHA256, resource.Width, resource.Height, resource.Duration.Milliseconds(), resource.Thumbnail)
+	return err
 }
 
 func (i *Instant) ResourceExists(resourceID string) bool {
-	if _, ok := i.resourceMap[resourceID]; ok {
+	i.resourceMu.Lock()
+	_, ok := i.resourceMap[resourceID]
+	i.resourceMu.Unlock()
+	if ok {
 		return true
 	}
 
@@ -293,16 +388,7 @@ func (i *Instant) GetResource(resourceID string) (Resource, error) {
 		return Resource{}, err
 	}
 
-	var (
-		id       string
-		protocol string
-		refLink  string
-		filePath string
-		errMsg   string
-		timeUnix int64
-	)
-	err := db.QueryRow(`SELECT ID, PROTOCOL, REF_LINK, PATH, ERROR, TIME FROM RESOURCES WHERE ID = ?`, resourceID).
-		Scan(&id, &protocol, &refLink, &filePath, &errMsg, &timeUnix)
+	res, err := scanResource(db.QueryRow(`SELECT `+resourceColumns+` FROM RESOURCES WHERE ID = ?`, resourceID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Resource{}, fmt.Errorf("resource with ID %s not found in database", resourceID)
@@ -310,14 +396,7 @@ func (i *Instant) GetResource(resourceID string) (Resource, error) {
 		return Resource{}, fmt.Errorf("failed to query resource from database: %v", err)
 	}
 
-	return Resource{
-		ID:         id,
-		Protocol:   protocol,
-		RefLink:    refLink,
-		FilePath:   filePath,
-		Error:      errMsg,
-		Downloaded: time.Unix(timeUnix, 0),
-	}, nil
+	return res, nil
 }
 
 func (i *Instant) GetResourceData(resourceID string) ([]byte, error) {
@@ -325,54 +404,11 @@ func (i *Instant) GetResourceData(resourceID string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return os.ReadFile(resource.FilePath)
-}
-
-// downloadFileWithRetry 尝试下载文件，带有重试机制
-func downloadFileWithRetry(url string, retryCount ...int) (data []byte, fileName string, err error) {
-	maxRetries := 5
-	if len(retryCount) > 0 && retryCount[0] >= 0 {
-		maxRetries = retryCount[0]
-	}
-
-	for retries := 0; retries < maxRetries; retries++ {
-		data, fileName, err = downloadFile(url)
-		if err == nil {
-			return
-		}
-	}
-
-	return
-}
-
-func downloadFile(url string) (data []byte, fileName string, err error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	ext, err := mime.ExtensionsByType(resp.Header.Get("Content-Type"))
-	if name := resp.Header.Get("Content-Disposition"); name != "" {
-		Ext := strings.TrimLeft(path.Ext(name), ".")
-		ext = []string{
-			Ext,
-		}
+	i.touchResource(resourceID)
+	if resource.Key != "" {
+		return i.ResourceStore().Get(resource.Key)
 	}
-
-	data, err = io.ReadAll(resp.Body)
-	if err == nil && len(ext) > 0 {
-		fileName = calcMd5(data) + "." + ext[0]
-	}
-	return
-}
-
-func calcMd5(data []byte) string {
-	return fmt.Sprintf("%x", md5.Sum(data))
+	return os.ReadFile(resource.FilePath)
 }
 
 func ensureResourceTable(db *sql.DB) error {
@@ -381,9 +417,18 @@ CREATE TABLE IF NOT EXISTS RESOURCES (
     ID TEXT PRIMARY KEY NOT NULL,
     PROTOCOL TEXT,
     REF_LINK TEXT,
+    STORE_KEY TEXT,
     PATH TEXT,
     ERROR TEXT,
-    TIME NUMERIC NOT NULL
+    TIME NUMERIC NOT NULL,
+    SIZE INTEGER,
+    ACCESSED NUMERIC,
+    MIME TEXT,
+    SHA256 TEXT,
+    WIDTH INTEGER,
+    HEIGHT INTEGER,
+    DURATION INTEGER,
+    THUMBNAIL TEXT
 );`); err != nil {
 		return err
 	}
@@ -408,18 +453,40 @@ CREATE TABLE IF NOT EXISTS RESOURCES (
 		columns[strings.ToUpper(name)] = true
 	}
 
-	for _, col := range []string{"PROTOCOL", "REF_LINK", "PATH", "ERROR", "TIME"} {
-		if !columns[col] {
-			if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE RESOURCES ADD COLUMN %s TEXT;`, col)); err != nil {
+	for _, col := range []struct{ name, typ string }{
+		{"PROTOCOL", "TEXT"},
+		{"REF_LINK", "TEXT"},
+		{"STORE_KEY", "TEXT"},
+		{"PATH", "TEXT"},
+		{"ERROR", "TEXT"},
+		{"TIME", "TEXT"},
+		{"SIZE", "INTEGER"},
+		{"ACCESSED", "NUMERIC"},
+		{"MIME", "TEXT"},
+		{"SHA256", "TEXT"},
+		{"WIDTH", "INTEGER"},
+		{"HEIGHT", "INTEGER"},
+		{"DURATION", "INTEGER"},
+		{"THUMBNAIL", "TEXT"},
+	} {
+		if !columns[col.name] {
+			if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE RESOURCES ADD COLUMN %s %s;`, col.name, col.typ)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS RESOURCES_SHA256 ON RESOURCES (SHA256);`)
+	return err
+}
+
+// buildTargetPath 返回适配器下载资源时写入的临时文件路径，位于资源目录下，不会被当作存储中的内容
+func (i *Instant) buildTargetPath(id string, refLink string) string {
+	return filepath.Join(i.resourceRoot(), id+resourceExt(refLink))
 }
 
-func buildTargetPath(id string, refLink string) string {
+// resourceExt 从 refLink 的 ext 或 url 参数推断扩展名，包含开头的点，默认为 .dat
+func resourceExt(refLink string) string {
 	ext := ".dat"
 	if values, err := urlpkg.ParseQuery(refLink); err == nil {
 		if e := values.Get("ext"); e != "" {
@@ -436,8 +503,7 @@ func buildTargetPath(id string, refLink string) string {
 			}
 		}
 	}
-
-	return filepath.Join("resources", fmt.Sprintf("%s%s", id, ext))
+	return ext
 }
 
 func withTarget(refLink string, target string) string {
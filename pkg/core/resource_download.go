@@ -0,0 +1,265 @@
+package GoroBot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+// 重试间隔的上限
+const maxDownloadBackoff = 30 * time.Second
+
+// ResourceDownloadConfig 控制资源下载的并发和重试
+type ResourceDownloadConfig struct {
+	Concurrency int            `json:"concurrency"`         // 同时进行的下载数，默认为 8
+	PerProtocol int            `json:"per_protocol"`        // 每个协议同时进行的下载数，默认为 4
+	Protocols   map[string]int `json:"protocols,omitempty"` // 按协议名覆盖 PerProtocol，如 {"telegram": 2}
+	Retries     int            `json:"retries"`             // 失败后的重试次数，默认为 3，小于 0 表示不重试
+	Backoff     int            `json:"backoff"`             // 第一次重试前等待的毫秒数，之后每次翻倍，默认为 500
+}
+
+// SetResourceDownload 设置资源下载的并发和重试，覆盖配置文件中的 resource_download。
+// 新的并发限制只对之后开始排队的下载生效
+func (i *Instant) SetResourceDownload(conf ResourceDownloadConfig) {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	i.resourceDownload = &conf
+	i.resourcePool = nil
+}
+
+// ResourceDownload 返回当前的资源下载设置
+func (i *Instant) ResourceDownload() ResourceDownloadConfig {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	return i.resourceDownloadLocked()
+}
+
+func (i *Instant) resourceDownloadLocked() ResourceDownloadConfig {
+	var conf ResourceDownloadConfig
+	switch {
+	case i.resourceDownload != nil:
+		conf = *i.resourceDownload
+	case i.config.ResourceDownload != nil:
+		conf = *i.config.ResourceDownload
+	}
+	if conf.Concurrency <= 0 {
+		conf.Concurrency = 8
+	}
+	if conf.PerProtocol <= 0 {
+		conf.PerProtocol = 4
+	}
+	if conf.Retries == 0 {
+		conf.Retries = 3
+	}
+	if conf.Backoff <= 0 {
+		conf.Backoff = 500
+	}
+	return conf
+}
+
+// resourcePool 限制同时进行的下载数，每个协议另有独立的上限，避免一个协议占满全部名额
+type resourcePool struct {
+	conf      ResourceDownloadConfig
+	total     chan struct{}
+	mu        sync.Mutex
+	protocols map[string]chan struct{}
+}
+
+func (i *Instant) downloadPool() *resourcePool {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	if i.resourcePool == nil {
+		conf := i.resourceDownloadLocked()
+		i.resourcePool = &resourcePool{
+			conf:      conf,
+			total:     make(chan struct{}, conf.Concurrency),
+			protocols: make(map[string]chan struct{}),
+		}
+	}
+	return i.resourcePool
+}
+
+// acquire 等待 protocol 和总数各一个名额，返回释放函数。protocol 为空时只占用总数的名额
+func (p *resourcePool) acquire(ctx context.Context, protocol string) (func(), error) {
+	var slot chan struct{}
+	if protocol != "" {
+		p.mu.Lock()
+		slot = p.protocols[protocol]
+		if slot == nil {
+			limit := p.conf.PerProtocol
+			if n, ok := p.conf.Protocols[protocol]; ok && n > 0 {
+				limit = n
+			}
+			slot = make(chan struct{}, limit)
+			p.protocols[protocol] = slot
+		}
+		p.mu.Unlock()
+
+		// 先占用协议的名额，排队中的下载不会占用总数的名额
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case p.total <- struct{}{}:
+	case <-ctx.Done():
+		if slot != nil {
+			<-slot
+		}
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-p.total
+		if slot != nil {
+			<-slot
+		}
+	}, nil
+}
+
+// retryDownload 执行 fn，可以重试的错误按指数退避重试，直到成功、次数用完或 ctx 被取消
+func (i *Instant) retryDownload(ctx context.Context, fn func() error) error {
+	conf := i.ResourceDownload()
+	backoff := time.Duration(conf.Backoff) * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= conf.Retries || ctx.Err() != nil || !retryableDownload(err) {
+			return err
+		}
+
+		// 加入随机抖动，避免同时失败的下载同时重试
+		delay := min(backoff<<attempt, maxDownloadBackoff)
+		delay = delay/2 + rand.N(delay/2+1)
+		i.logger.Debug("Download failed, retrying in %v: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryableDownload 报告下载错误是否值得重试，超过大小限制、取消、内容不存在和客户端错误不会重试
+func retryableDownload(err error) bool {
+	if errors.Is(err, util.ErrTooLarge) || errors.Is(err, errResourceExists) || errors.Is(err, fs.ErrNotExist) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var status *util.StatusError
+	if errors.As(err, &status) {
+		return status.Temporary()
+	}
+	return true
+}
+
+// resourceFlight 是一个正在进行的资源下载，同时加载同一个资源的调用共享这次下载
+type resourceFlight struct {
+	done    chan struct{}
+	res     Resource
+	err     error
+	cancel  context.CancelFunc
+	waiters []*resourceWaiter
+}
+
+type resourceWaiter struct {
+	progress util.ProgressFunc
+}
+
+// loadRemoteResource 下载资源，同一个资源同时只会下载一次，其他调用等待并共享结果。
+// 每个调用的 ctx 只影响自己的等待，所有调用都取消后下载才会中止
+func (i *Instant) loadRemoteResource(ctx context.Context, res Resource, opts ResourceOptions) (Resource, error) {
+	transfer := i.transferOptions(opts)
+	waiter := &resourceWaiter{progress: opts.Progress}
+
+	i.resourceFlightMu.Lock()
+	flight, ok := i.resourceFlights[res.ID]
+	if !ok {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		flight = &resourceFlight{done: make(chan struct{}), cancel: cancel}
+		i.resourceFlights[res.ID] = flight
+		go i.runResourceFlight(flightCtx, flight, res, util.TransferOptions{
+			MaxSize: transfer.MaxSize,
+			Progress: func(done, total int64) {
+				i.reportResourceFlight(flight, done, total)
+			},
+		})
+	}
+	flight.waiters = append(flight.waiters, waiter)
+	i.resourceFlightMu.Unlock()
+
+	select {
+	case <-flight.done:
+		// 加入的下载可能使用了更宽松的大小限制
+		if flight.err == nil && transfer.MaxSize > 0 && flight.res.Size > transfer.MaxSize {
+			return flight.res, fmt.Errorf("failed to download resource %s: %w", res.ID, util.ErrTooLarge)
+		}
+		return flight.res, flight.err
+	case <-ctx.Done():
+		i.resourceFlightMu.Lock()
+		for n, w := range flight.waiters {
+			if w == waiter {
+				flight.waiters = append(flight.waiters[:n], flight.waiters[n+1:]...)
+				break
+			}
+		}
+		if len(flight.waiters) == 0 {
+			// 之后的调用会重新开始下载
+			if i.resourceFlights[res.ID] == flight {
+				delete(i.resourceFlights, res.ID)
+			}
+			flight.cancel()
+		}
+		i.resourceFlightMu.Unlock()
+		return res, ctx.Err()
+	}
+}
+
+func (i *Instant) runResourceFlight(ctx context.Context, flight *resourceFlight, res Resource, transfer util.TransferOptions) {
+	defer flight.cancel()
+
+	// 调用方查找资源之后，其他调用可能已经完成了下载
+	latest, err := i.lookupResource(res.ID)
+	switch {
+	case err != nil:
+		flight.err = err
+	case latest.Key != "" && i.resourceAvailable(latest.Key):
+		flight.res = latest
+	case latest.Key == "" && latest.Error != "":
+		flight.res, flight.err = latest, errors.New(latest.Error)
+	default:
+		flight.res, flight.err = i.downloadResource(ctx, latest, transfer)
+	}
+
+	i.resourceFlightMu.Lock()
+	if i.resourceFlights[res.ID] == flight {
+		delete(i.resourceFlights, res.ID)
+	}
+	i.resourceFlightMu.Unlock()
+	close(flight.done)
+}
+
+func (i *Instant) reportResourceFlight(flight *resourceFlight, done, total int64) {
+	i.resourceFlightMu.Lock()
+	waiters := append([]*resourceWaiter(nil), flight.waiters...)
+	i.resourceFlightMu.Unlock()
+	for _, w := range waiters {
+		if w.progress != nil {
+			w.progress(done, total)
+		}
+	}
+}
+
+// resourceAvailable 报告 key 对应的内容是否仍在存储中
+func (i *Instant) resourceAvailable(key string) bool {
+	_, err := i.ResourceStore().Stat(key)
+	return err == nil
+}
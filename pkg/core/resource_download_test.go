@@ -0,0 +1,214 @@
+package GoroBot_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+// openerBot 在 testkit 适配器上实现 botc.ResourceOpener，记录下载次数
+type openerBot struct {
+	*testkit.Bot
+	opens   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	fail    func(n int32) error // 返回第 n 次下载的错误，为 nil 时成功
+}
+
+func newOpenerBot(t *testing.T) (*GoroBot.Instant, *openerBot) {
+	t.Helper()
+	grb := GoroBot.Create()
+	grb.UseResourceStore(GoroBot.NewLocalResourceStore(t.TempDir()))
+	grb.SetResourceDownload(GoroBot.ResourceDownloadConfig{Backoff: 1})
+	bot := &openerBot{
+		Bot:     testkit.New(nil),
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+	close(bot.release)
+	grb.AddContext(bot)
+	return grb, bot
+}
+
+func (b *openerBot) OpenResourceFromRefLink(ctx context.Context, _ string) (io.ReadCloser, int64, error) {
+	n := b.opens.Add(1)
+	b.started <- struct{}{}
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+	if b.fail != nil {
+		if err := b.fail(n); err != nil {
+			return nil, 0, err
+		}
+	}
+	return io.NopCloser(strings.NewReader(resourceContent)), int64(len(resourceContent)), nil
+}
+
+func TestLoadResourceSingleFlight(t *testing.T) {
+	grb, bot := newOpenerBot(t)
+	bot.release = make(chan struct{})
+	id := grb.SaveResourceLink(bot.ID(), "file=a.txt")
+
+	const callers = 10
+	var (
+		wg       sync.WaitGroup
+		progress atomic.Int32
+		paths    = make([]string, callers)
+		errs     = make([]error, callers)
+	)
+	for n := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			paths[n], errs[n] = grb.LoadResourceContext(context.Background(), id, GoroBot.ResourceOptions{
+				Progress: func(done, total int64) {
+					if done == total {
+						progress.Add(1)
+					}
+				},
+			})
+		}()
+	}
+
+	<-bot.started
+	// 让其他调用加入正在进行的下载
+	time.Sleep(50 * time.Millisecond)
+	close(bot.release)
+	wg.Wait()
+
+	if n := bot.opens.Load(); n != 1 {
+		t.Fatalf("resource downloaded %d times, want 1", n)
+	}
+	for n := range callers {
+		if errs[n] != nil {
+			t.Fatalf("caller %d: %v", n, errs[n])
+		}
+		if paths[n] != paths[0] {
+			t.Fatalf("caller %d got %s, want %s", n, paths[n], paths[0])
+		}
+	}
+	checkResourceFile(t, paths[0])
+	if progress.Load() == 0 {
+		t.Fatal("no progress reported to waiters")
+	}
+}
+
+func TestLoadResourceCancelWaiter(t *testing.T) {
+	grb, bot := newOpenerBot(t)
+	bot.release = make(chan struct{})
+	id := grb.SaveResourceLink(bot.ID(), "file=a.txt")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := grb.LoadResourceContext(ctx, id, GoroBot.ResourceOptions{})
+		cancelled <- err
+	}()
+	<-bot.started
+
+	done := make(chan error, 1)
+	var path string
+	go func() {
+		var err error
+		path, err = grb.LoadResourceContext(context.Background(), id, GoroBot.ResourceOptions{})
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// 一个调用取消后，下载仍然为其他调用继续
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller got %v", err)
+	}
+	close(bot.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	checkResourceFile(t, path)
+	if n := bot.opens.Load(); n != 1 {
+		t.Fatalf("resource downloaded %d times, want 1", n)
+	}
+}
+
+func TestLoadResourceCancelAll(t *testing.T) {
+	grb, bot := newOpenerBot(t)
+	bot.release = make(chan struct{})
+	id := grb.SaveResourceLink(bot.ID(), "file=a.txt")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := grb.LoadResourceContext(ctx, id, GoroBot.ResourceOptions{})
+		cancelled <- err
+	}()
+	<-bot.started
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v", err)
+	}
+
+	// 所有调用都取消后下载中止，之后的调用重新下载
+	close(bot.release)
+	path, err := grb.LoadResourceFromID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResourceFile(t, path)
+	if n := bot.opens.Load(); n != 2 {
+		t.Fatalf("resource downloaded %d times, want 2", n)
+	}
+}
+
+func TestLoadResourceRetry(t *testing.T) {
+	grb, bot := newOpenerBot(t)
+	bot.fail = func(n int32) error {
+		if n < 3 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	id := grb.SaveResourceLink(bot.ID(), "file=a.txt")
+
+	path, err := grb.LoadResourceFromID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResourceFile(t, path)
+	if n := bot.opens.Load(); n != 3 {
+		t.Fatalf("resource downloaded %d times, want 3", n)
+	}
+}
+
+func TestLoadResourceNoRetry(t *testing.T) {
+	grb, bot := newOpenerBot(t)
+	bot.fail = func(int32) error {
+		return fs.ErrNotExist
+	}
+	id := grb.SaveResourceLink(bot.ID(), "file=a.txt")
+
+	if _, err := grb.LoadResourceFromID(id); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := bot.opens.Load(); n != 1 {
+		t.Fatalf("resource downloaded %d times, want 1", n)
+	}
+
+	// 失败的结果会被记录，之后的调用直接返回错误
+	if _, err := grb.LoadResourceFromID(id); err == nil {
+		t.Fatal("expected the recorded error")
+	}
+	if n := bot.opens.Load(); n != 1 {
+		t.Fatalf("resource downloaded %d times after the recorded failure, want 1", n)
+	}
+}
@@ -0,0 +1,462 @@
+package GoroBot
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// 新保存的内容在这段时间内不会被当作孤立文件回收，避免与正在写入索引的资源冲突
+const resourceOrphanGrace = 10 * time.Minute
+
+// ResourceLimitConfig 是资源文件的回收策略
+type ResourceLimitConfig struct {
+	MaxSize     int64 `json:"max_size"`                // 资源文件总大小上限（MB），超出时淘汰最久未访问的资源，不大于 0 表示不限制
+	MaxAge      int   `json:"max_age"`                 // 超过该天数未访问的资源会被删除，不大于 0 表示不限制
+	Interval    int   `json:"interval"`                // 自动回收的间隔（分钟），默认为 60
+	MaxFileSize int64 `json:"max_file_size,omitempty"` // 下载单个资源文件的大小上限（MB），不大于 0 表示不限制
+}
+
+// ResourceUsage 是一个协议保存的资源占用情况
+type ResourceUsage struct {
+	Protocol string
+	Count    int   // 资源索引条数
+	Files    int   // 已下载的资源数
+	Size     int64 // 已下载资源的大小（字节），多个资源引用同一份内容时分别计算
+}
+
+// ResourceGCResult 是一次资源回收的结果
+type ResourceGCResult struct {
+	Expired int   // 超过 MaxAge 未访问而回收的资源
+	Evicted int   // 超出 MaxSize 而淘汰的资源
+	Stale   int   // 文件已不存在的索引
+	Orphans int   // 没有索引引用而删除的文件
+	Freed   int64 // 释放的空间（字节）
+}
+
+// SetResourceLimit 设置资源回收策略，覆盖配置文件中的 resource_limit
+func (i *Instant) SetResourceLimit(limit ResourceLimitConfig) {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	i.resourceLimit = &limit
+}
+
+// ResourceLimit 返回当前的资源回收策略
+func (i *Instant) ResourceLimit() ResourceLimitConfig {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	var limit ResourceLimitConfig
+	switch {
+	case i.resourceLimit != nil:
+		limit = *i.resourceLimit
+	case i.config.ResourceLimit != nil:
+		limit = *i.config.ResourceLimit
+	}
+	if limit.Interval <= 0 {
+		limit.Interval = 60
+	}
+	return limit
+}
+
+// ResourceUsage 返回各协议保存的资源占用情况，按大小从大到小排序
+func (i *Instant) ResourceUsage() ([]ResourceUsage, error) {
+	resources, err := i.resources()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string]*ResourceUsage)
+	for _, res := range resources {
+		protocol, _, _ := strings.Cut(res.Protocol, ":")
+		usage, ok := usages[protocol]
+		if !ok {
+			usage = &ResourceUsage{Protocol: protocol}
+			usages[protocol] = usage
+		}
+		usage.Count++
+		if res.Key != "" || res.FilePath != "" && res.Error == "" {
+			usage.Files++
+			usage.Size += res.Size
+		}
+	}
+
+	result := make([]ResourceUsage, 0, len(usages))
+	for _, usage := range usages {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Size != result[b].Size {
+			return result[a].Size > result[b].Size
+		}
+		return result[a].Protocol < result[b].Protocol
+	})
+	return result, nil
+}
+
+// resourceFileGroup 是引用同一份内容的资源
+type resourceFileGroup struct {
+	id        string // 内容的 key，旧版本保存的资源为 "path:" 加文件路径
+	resources []Resource
+	size      int64
+	accessed  time.Time
+}
+
+// CollectResources 执行一次资源回收：回收过期和文件已不存在的资源，按最后访问时间淘汰超出大小上限的资源，
+// 并删除存储中没有被索引引用的内容。还有引用信息的资源只删除文件，之后可以重新下载。
+// 只有实现了 ResourceLister 的存储会检查孤立文件，S3 兼容存储可能被其他实例使用，不会被扫描
+func (i *Instant) CollectResources() (ResourceGCResult, error) {
+	i.resourceGCMu.Lock()
+	defer i.resourceGCMu.Unlock()
+
+	var result ResourceGCResult
+	limit := i.ResourceLimit()
+	store := i.ResourceStore()
+	now := time.Now()
+
+	resources, err := i.resources()
+	if err != nil {
+		return result, err
+	}
+
+	var existing map[string]ResourceInfo
+	lister, canList := store.(ResourceLister)
+	if canList {
+		existing = make(map[string]ResourceInfo)
+		if err := lister.List(func(info ResourceInfo) error {
+			existing[info.Key] = info
+			return nil
+		}); err != nil {
+			return result, fmt.Errorf("failed to list resource store: %v", err)
+		}
+	}
+
+	var (
+		removed []Resource // 删除索引
+		cleared []Resource // 保留索引，只删除文件
+		groups  = make(map[string]*resourceFileGroup)
+	)
+	release := func(res Resource) {
+		if res.RefLink == "" {
+			removed = append(removed, res)
+		} else {
+			cleared = append(cleared, res)
+		}
+	}
+
+	for _, res := range resources {
+		var (
+			id   string
+			size = res.Size
+		)
+		switch {
+		case res.Key != "":
+			id = res.Key
+			if canList {
+				info, ok := existing[res.Key]
+				if !ok {
+					result.Stale++
+					release(res)
+					continue
+				}
+				if size == 0 {
+					size = info.Size
+				}
+			}
+		case res.FilePath != "" && res.Error == "":
+			id = "path:" + res.FilePath
+			info, err := os.Stat(res.FilePath)
+			if err != nil {
+				result.Stale++
+				release(res)
+				continue
+			}
+			if size == 0 {
+				size = info.Size()
+			}
+		case res.RefLink == "":
+			// 既没有文件也无法下载
+			result.Stale++
+			removed = append(removed, res)
+			continue
+		}
+
+		if id == "" {
+			// 只有引用信息，没有需要回收的文件
+			continue
+		}
+		if limit.MaxAge > 0 && now.Sub(res.Accessed) > time.Duration(limit.MaxAge)*24*time.Hour {
+			result.Expired++
+			release(res)
+			continue
+		}
+
+		group, ok := groups[id]
+		if !ok {
+			group = &resourceFileGroup{id: id, size: size}
+			groups[id] = group
+		}
+		group.resources = append(group.resources, res)
+		if res.Accessed.After(group.accessed) {
+			group.accessed = res.Accessed
+		}
+	}
+
+	// 按最后访问时间从旧到新淘汰，直到总大小不超过上限
+	if limit.MaxSize > 0 {
+		var total int64
+		sorted := make([]*resourceFileGroup, 0, len(groups))
+		for _, group := range groups {
+			total += group.size
+			sorted = append(sorted, group)
+		}
+		sort.Slice(sorted, func(a, b int) bool {
+			return sorted[a].accessed.Before(sorted[b].accessed)
+		})
+		for _, group := range sorted {
+			if total <= limit.MaxSize<<20 {
+				break
+			}
+			total -= group.size
+			delete(groups, group.id)
+			for _, res := range group.resources {
+				result.Evicted++
+				release(res)
+			}
+		}
+	}
+
+	for _, res := range removed {
+		if err := i.deleteResource(res.ID); err != nil {
+			return result, err
+		}
+	}
+	for _, res := range cleared {
+		if err := i.clearResourceFile(res.ID); err != nil {
+			return result, err
+		}
+	}
+
+	// 仍被引用的内容的缩略图
+	thumbnails := make(map[string]bool)
+	for _, group := range groups {
+		for _, res := range group.resources {
+			if res.Thumbnail != "" {
+				thumbnails[res.Thumbnail] = true
+			}
+		}
+	}
+
+	// 删除不再被引用的内容和缩略图，groups 中剩下的是仍被引用的内容
+	deleted := make(map[string]bool)
+	free := func(id string, size int64) {
+		if id == "" || groups[id] != nil || thumbnails[id] || deleted[id] {
+			return
+		}
+		deleted[id] = true
+		if filePath, ok := strings.CutPrefix(id, "path:"); ok {
+			if info, err := os.Stat(filePath); err == nil && os.Remove(filePath) == nil {
+				result.Freed += info.Size()
+			}
+			return
+		}
+		if canList {
+			info, ok := existing[id]
+			if !ok {
+				return
+			}
+			size = info.Size
+		}
+		if err := store.Delete(id); err != nil {
+			i.logger.Error("delete resource %s failed: %v", id, err)
+			return
+		}
+		_ = i.resourceCache().Delete(id)
+		result.Freed += size
+	}
+	for _, res := range append(removed, cleared...) {
+		id := res.Key
+		if id == "" && res.FilePath != "" && res.Error == "" {
+			id = "path:" + res.FilePath
+		}
+		free(id, res.Size)
+		free(res.Thumbnail, 0)
+	}
+
+	for key, info := range existing {
+		if groups[key] != nil || thumbnails[key] || deleted[key] || now.Sub(info.ModTime) < resourceOrphanGrace {
+			continue
+		}
+		if err := store.Delete(key); err != nil {
+			i.logger.Error("delete orphaned resource %s failed: %v", key, err)
+			continue
+		}
+		result.Orphans++
+		result.Freed += info.Size
+	}
+
+	// 远程存储的本地缓存只保留仍被引用的内容
+	if _, ok := store.(ResourceFileStore); !ok {
+		cache := i.resourceCache()
+		_ = cache.List(func(info ResourceInfo) error {
+			if groups[info.Key] == nil && !thumbnails[info.Key] && now.Sub(info.ModTime) >= resourceOrphanGrace {
+				_ = cache.Delete(info.Key)
+			}
+			return nil
+		})
+	}
+
+	return result, nil
+}
+
+// resources 返回资源索引中的全部资源
+func (i *Instant) resources() ([]Resource, error) {
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		defer i.resourceMu.Unlock()
+		resources := make([]Resource, 0, len(i.resourceMap))
+		for _, res := range i.resourceMap {
+			resources = append(resources, res)
+		}
+		return resources, nil
+	}
+
+	db := i.Database()
+	if err := ensureResourceTable(db); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT ` + resourceColumns + ` FROM RESOURCES`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		res, err := scanResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
+}
+
+func (i *Instant) deleteResource(id string) error {
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		delete(i.resourceMap, id)
+		i.resourceMu.Unlock()
+		return nil
+	}
+	_, err := i.Database().Exec(`DELETE FROM RESOURCES WHERE ID = ?`, id)
+	return err
+}
+
+// clearResourceFile 删除资源的文件和缩略图记录，保留引用信息以便重新下载。
+// 其他内容信息仍然保留，ResourcesByHash 可以继续找到这个资源
+func (i *Instant) clearResourceFile(id string) error {
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		if res, ok := i.resourceMap[id]; ok {
+			res.Key, res.FilePath, res.Size, res.Thumbnail = "", "", 0, ""
+			i.resourceMap[id] = res
+		}
+		i.resourceMu.Unlock()
+		return nil
+	}
+	_, err := i.Database().Exec(`UPDATE RESOURCES SET STORE_KEY = '', PATH = '', SIZE = 0, THUMBNAIL = '' WHERE ID = ?`, id)
+	return err
+}
+
+// startResourceGC 按 resource_limit 中的间隔定期回收资源，返回停止函数
+func (i *Instant) startResourceGC() func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Duration(i.ResourceLimit().Interval) * time.Minute):
+			}
+			result, err := i.CollectResources()
+			if err != nil {
+				i.logger.Error("collect resources failed: %v", err)
+				continue
+			}
+			if result.Freed > 0 || result.Stale > 0 {
+				i.logger.Info("Collected resources: %d expired, %d evicted, %d stale, %d orphaned, %s freed",
+					result.Expired, result.Evicted, result.Stale, result.Orphans, formatSize(result.Freed))
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+func (i *Instant) initResourceCmd() {
+	cmd := i.Command("resource").
+		Description("管理资源文件").
+		Permission(entity.Admin)
+
+	cmd.SubCommand("usage").
+		Description("查看各协议的资源占用").
+		Action(func(ctx *command.Context) error {
+			usages, err := i.ResourceUsage()
+			if err != nil {
+				return err
+			}
+			if len(usages) == 0 {
+				_, _ = ctx.ReplyText("没有保存的资源")
+				return nil
+			}
+			var (
+				b     strings.Builder
+				total int64
+			)
+			b.WriteString("资源占用：")
+			for _, usage := range usages {
+				total += usage.Size
+				fmt.Fprintf(&b, "\n%s  %d 条  已下载 %d 个  %s", usage.Protocol, usage.Count, usage.Files, formatSize(usage.Size))
+			}
+			fmt.Fprintf(&b, "\n合计 %s", formatSize(total))
+			limit := i.ResourceLimit()
+			if limit.MaxSize > 0 {
+				fmt.Fprintf(&b, "，上限 %s", formatSize(limit.MaxSize<<20))
+			}
+			if limit.MaxAge > 0 {
+				fmt.Fprintf(&b, "，超过 %d 天未访问的资源会被删除", limit.MaxAge)
+			}
+			_, _ = ctx.ReplyText(b.String())
+			return nil
+		})
+
+	cmd.SubCommand("gc").
+		Description("立即回收过期和超出上限的资源").
+		Action(func(ctx *command.Context) error {
+			result, err := i.CollectResources()
+			if err != nil {
+				return err
+			}
+			_, _ = ctx.ReplyText(fmt.Sprintf("回收完成：过期 %d 个，淘汰 %d 个，失效索引 %d 条，孤立文件 %d 个，释放 %s",
+				result.Expired, result.Evicted, result.Stale, result.Orphans, formatSize(result.Freed)))
+			return nil
+		})
+
+	_, _ = cmd.Build()
+}
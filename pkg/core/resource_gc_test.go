@@ -0,0 +1,197 @@
+package GoroBot_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	day             = 24 * time.Hour
+	resourceContent = "resource content"
+)
+
+// newGCBot 创建连接了临时数据库的实例，资源索引保存在数据库中
+func newGCBot(t *testing.T) (*GoroBot.Instant, *testkit.Bot) {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+	if err := grb.OpenDatabase("sqlite3", filepath.Join(t.TempDir(), "bot.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = grb.Database().Close()
+	})
+	return grb, bot
+}
+
+// saveLinkedResource 登记一个由适配器下载的资源
+func saveLinkedResource(t *testing.T, bot *testkit.Bot) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(src, []byte(resourceContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return bot.SaveResource(src)
+}
+
+func checkResourceFile(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != resourceContent {
+		t.Fatalf("got content %q", data)
+	}
+}
+
+func setAccessed(t *testing.T, grb *GoroBot.Instant, id string, accessed time.Time) {
+	t.Helper()
+	if err := grb.SetResourceAccessed(id, accessed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectExpiredResources(t *testing.T) {
+	grb, bot := newGCBot(t)
+	grb.SetResourceLimit(GoroBot.ResourceLimitConfig{MaxAge: 30})
+
+	linked := saveLinkedResource(t, bot)
+	if _, err := grb.LoadResourceFromID(linked); err != nil {
+		t.Fatal(err)
+	}
+	local, err := grb.SaveResourceData([]byte("local content"), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := grb.SaveResourceData([]byte("fresh content"), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setAccessed(t, grb, linked, time.Now().Add(-40*day))
+	setAccessed(t, grb, local, time.Now().Add(-40*day))
+
+	result, err := grb.CollectResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expired != 2 || result.Freed == 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// 没有引用信息的资源删除索引，有引用信息的只删除文件
+	if grb.ResourceExists(local) {
+		t.Fatal("expired local resource still indexed")
+	}
+	if !grb.ResourceExists(fresh) {
+		t.Fatal("fresh resource removed")
+	}
+	res, err := grb.GetResource(linked)
+	if err != nil {
+		t.Fatalf("expired linked resource lost its index: %v", err)
+	}
+	if res.Key != "" {
+		t.Fatal("expired linked resource still has content")
+	}
+
+	// 只剩引用信息的资源不再计入过期
+	if result, err = grb.CollectResources(); err != nil {
+		t.Fatal(err)
+	} else if result.Expired != 0 {
+		t.Fatalf("resource without content expired again: %+v", result)
+	}
+
+	path, err := grb.LoadResourceFromID(linked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResourceFile(t, path)
+	if res, err := grb.GetResource(linked); err != nil || res.Key == "" {
+		t.Fatalf("expired linked resource not downloaded again: %+v, %v", res, err)
+	}
+}
+
+func TestCollectEvictsLeastRecentlyUsed(t *testing.T) {
+	grb, _ := newGCBot(t)
+	grb.SetResourceLimit(GoroBot.ResourceLimitConfig{MaxSize: 1})
+
+	// 三个 600KB 的资源超出 1MB，从最久未访问的开始淘汰
+	ids := make([]string, 3)
+	for n := range ids {
+		id, err := grb.SaveResourceData(bytes.Repeat([]byte{byte('a' + n)}, 600<<10), "bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[n] = id
+		setAccessed(t, grb, id, time.Now().Add(time.Duration(n-3)*time.Hour))
+	}
+
+	result, err := grb.CollectResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Evicted != 2 || result.Freed != 2*600<<10 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if grb.ResourceExists(ids[0]) || grb.ResourceExists(ids[1]) {
+		t.Fatal("least recently used resources not evicted")
+	}
+	if !grb.ResourceExists(ids[2]) {
+		t.Fatal("most recently used resource evicted")
+	}
+}
+
+func TestCollectStaleAndOrphans(t *testing.T) {
+	grb, _ := newGCBot(t)
+	store := grb.ResourceStore().(*GoroBot.LocalResourceStore)
+
+	stale, err := grb.SaveResourceData([]byte("stale content"), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := grb.GetResource(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(res.Key); err != nil {
+		t.Fatal(err)
+	}
+
+	orphan, err := store.Put(bytes.NewReader([]byte("orphan content")), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent, err := store.Put(bytes.NewReader([]byte("recent content")), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphanPath, _ := store.Path(orphan)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(orphanPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := grb.CollectResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stale != 1 || result.Orphans != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if grb.ResourceExists(stale) {
+		t.Fatal("stale resource still indexed")
+	}
+	if _, err := store.Stat(orphan); err == nil {
+		t.Fatal("orphaned content not deleted")
+	}
+	// 新写入的内容可能正在保存索引，不会被当作孤立文件
+	if _, err := store.Stat(recent); err != nil {
+		t.Fatalf("recent content deleted: %v", err)
+	}
+}
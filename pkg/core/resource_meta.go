@@ -0,0 +1,263 @@
+package GoroBot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// 像素数超过该值的图片不生成缩略图，避免解码时占用过多内存
+const maxThumbnailPixels = 64 << 20
+
+// ResourceMeta 是下载资源时从内容中检测到的信息
+type ResourceMeta struct {
+	Mime      string        // 根据内容检测的 MIME 类型，无法识别时根据扩展名推断
+	SHA256    string        // 内容的 sha256，相同内容的资源即使来自不同协议也相同
+	Width     int           // 图片的宽度（像素）
+	Height    int           // 图片的高度（像素）
+	Duration  time.Duration // 音视频的时长
+	Thumbnail string        // 图片缩略图在 ResourceStore 中的 key，未生成时为空
+}
+
+// 常见 MIME 类型对应的扩展名，mime.ExtensionsByType 返回的第一个扩展名不一定是常用的
+var mimeExts = map[string]string{
+	"image/png":                 "png",
+	"image/jpeg":                "jpg",
+	"image/gif":                 "gif",
+	"image/webp":                "webp",
+	"image/bmp":                 "bmp",
+	"audio/mpeg":                "mp3",
+	"audio/wave":                "wav",
+	"audio/aiff":                "aiff",
+	"application/ogg":           "ogg",
+	"video/mp4":                 "mp4",
+	"video/webm":                "webm",
+	"video/avi":                 "avi",
+	"application/pdf":           "pdf",
+	"application/zip":           "zip",
+	"text/plain; charset=utf-8": "txt",
+}
+
+// sniffResource 在扩展名未知（为空或 dat）时根据内容的前 512 字节推断，返回的 Reader 仍包含全部内容
+func sniffResource(r io.Reader, ext string) (io.Reader, string) {
+	ext = strings.TrimPrefix(ext, ".")
+	if ext != "" && ext != "dat" {
+		return r, ext
+	}
+	reader := bufio.NewReaderSize(r, 512)
+	head, _ := reader.Peek(512)
+	if e := mimeExt(http.DetectContentType(head)); e != "" {
+		ext = e
+	}
+	return reader, ext
+}
+
+func mimeExt(mimeType string) string {
+	if ext, ok := mimeExts[mimeType]; ok {
+		return ext
+	}
+	if mimeType == "application/octet-stream" {
+		return ""
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+	return ""
+}
+
+// resourceMeta 检测已下载资源的内容信息，配置了 resource_thumbnail 时为较大的图片生成缩略图
+func (i *Instant) resourceMeta(res Resource) (ResourceMeta, error) {
+	var meta ResourceMeta
+	filePath := res.FilePath
+	if res.Key != "" {
+		meta.SHA256, _, _ = strings.Cut(res.Key, ".")
+		path, err := i.resourceFile(res.Key)
+		if err != nil {
+			return meta, err
+		}
+		filePath = path
+	}
+	if filePath == "" {
+		return meta, fmt.Errorf("resource %s is not downloaded", res.ID)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return meta, err
+	}
+	defer file.Close()
+
+	if meta.SHA256 == "" {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return meta, err
+		}
+		meta.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	head := make([]byte, 512)
+	n, _ := file.ReadAt(head, 0)
+	meta.Mime = http.DetectContentType(head[:n])
+	if meta.Mime == "application/octet-stream" {
+		if t := mime.TypeByExtension(filepath.Ext(filePath)); t != "" {
+			meta.Mime = t
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(meta.Mime, "image/"):
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return meta, err
+		}
+		if config, _, err := image.DecodeConfig(file); err == nil {
+			meta.Width, meta.Height = config.Width, config.Height
+			meta.Thumbnail = i.createThumbnail(file, config)
+		}
+	case strings.HasPrefix(meta.Mime, "audio/"), strings.HasPrefix(meta.Mime, "video/"),
+		meta.Mime == "application/ogg", meta.Mime == "application/octet-stream":
+		// FLAC、M4A 等格式无法通过 http.DetectContentType 识别，由 MediaDuration 检查文件头
+		if duration, err := util.MediaDuration(file); err == nil {
+			meta.Duration = duration
+		}
+	}
+	return meta, nil
+}
+
+// createThumbnail 将图片缩小到 resource_thumbnail 以内，以 JPEG 保存到 ResourceStore 并返回 key。
+// 图片本身不超过该尺寸时不生成
+func (i *Instant) createThumbnail(file io.ReadSeeker, config image.Config) string {
+	size := i.ResourceThumbnailSize()
+	if size <= 0 || config.Width <= size && config.Height <= size || config.Width*config.Height > maxThumbnailPixels {
+		return ""
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return ""
+	}
+
+	width, height := size, size
+	if config.Width > config.Height {
+		height = max(1, config.Height*size/config.Width)
+	} else {
+		width = max(1, config.Width*size/config.Height)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// JPEG 不支持透明，透明部分以白色填充
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return ""
+	}
+	key, err := i.ResourceStore().Put(&buf, "jpg")
+	if err != nil {
+		i.logger.Warning("save thumbnail failed: %v", err)
+		return ""
+	}
+	return key
+}
+
+// SetResourceThumbnailSize 设置缩略图的最大边长（像素），覆盖配置文件中的 resource_thumbnail，为 0 时不生成
+func (i *Instant) SetResourceThumbnailSize(size int) {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	i.resourceThumb = &size
+}
+
+// ResourceThumbnailSize 返回当前缩略图的最大边长，为 0 时不生成
+func (i *Instant) ResourceThumbnailSize() int {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	if i.resourceThumb != nil {
+		return *i.resourceThumb
+	}
+	return i.config.ResourceThumb
+}
+
+// RefreshResourceMeta 重新检测已下载资源的内容信息并更新索引，可以用于补全旧版本保存的资源
+func (i *Instant) RefreshResourceMeta(id string) (Resource, error) {
+	res, err := i.lookupResource(id)
+	if err != nil {
+		return res, err
+	}
+	meta, err := i.resourceMeta(res)
+	if err != nil {
+		return res, err
+	}
+	res.ResourceMeta = meta
+
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		defer i.resourceMu.Unlock()
+		if _, ok := i.resourceMap[id]; ok {
+			i.resourceMap[id] = res
+		}
+		return res, nil
+	}
+	db := i.Database()
+	if err := ensureResourceTable(db); err != nil {
+		return res, err
+	}
+	_, err = db.Exec(`UPDATE RESOURCES SET MIME = ?, SHA256 = ?, WIDTH = ?, HEIGHT = ?, DURATION = ?, THUMBNAIL = ? WHERE ID = ?`,
+		meta.Mime, meta.SHA256, meta.Width, meta.Height, meta.Duration.Milliseconds(), meta.Thumbnail, id)
+	return res, err
+}
+
+// ResourcesByHash 返回内容 sha256 为 sum 的全部资源，可以用来识别不同协议、不同 ID 的重复资源。
+// 只包含下载时记录了内容信息的资源，旧版本保存的资源需要先调用 RefreshResourceMeta
+func (i *Instant) ResourcesByHash(sum string) ([]Resource, error) {
+	sum = strings.ToLower(sum)
+	if !i.DatabaseExist() {
+		i.resourceMu.Lock()
+		defer i.resourceMu.Unlock()
+		var resources []Resource
+		for _, res := range i.resourceMap {
+			if res.SHA256 == sum {
+				resources = append(resources, res)
+			}
+		}
+		return resources, nil
+	}
+
+	db := i.Database()
+	if err := ensureResourceTable(db); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT `+resourceColumns+` FROM RESOURCES WHERE SHA256 = ?`, sum)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		res, err := scanResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
+}
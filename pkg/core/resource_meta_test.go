@@ -0,0 +1,181 @@
+package GoroBot_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strings"
+	"testing"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func pngContent(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// savedResource 通过 SaveResourceReader 保存 content，返回记录的资源
+func savedResource(t *testing.T, grb *GoroBot.Instant, content []byte, ext string) GoroBot.Resource {
+	t.Helper()
+	id, err := grb.SaveResourceReader(bytes.NewReader(content), ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, err := grb.ResourcesByHash(contentHash(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range resources {
+		if res.ID == id {
+			return res
+		}
+	}
+	t.Fatalf("resource %s not found by its hash", id)
+	return GoroBot.Resource{}
+}
+
+func TestSniffResourceExt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		ext     string
+		want    string
+	}{
+		{"png without ext", pngContent(t, 1, 1), "", "png"},
+		{"png with dat", pngContent(t, 2, 1), "dat", "png"},
+		{"png with .dat", pngContent(t, 3, 1), ".dat", "png"},
+		{"explicit ext kept", pngContent(t, 4, 1), "bin", "bin"},
+		{"explicit ext with dot", pngContent(t, 5, 1), ".gif", "gif"},
+		{"text", []byte("plain text"), "", "txt"},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), "dat", "gif"},
+		{"unknown binary", []byte{0, 1, 2, 3, 0xfe, 0xff}, "", ""},
+		{"unknown binary with dat", []byte{0, 1, 2, 3, 0xfe}, "dat", "dat"},
+		{"empty", nil, "", "txt"},
+	}
+
+	grb, _ := testkit.NewInstant(t)
+	for _, tt := range tests {
+		res := savedResource(t, grb, tt.content, tt.ext)
+		if _, ext, _ := strings.Cut(res.Key, "."); ext != tt.want {
+			t.Errorf("%s: got key %s, want extension %q", tt.name, res.Key, tt.want)
+		}
+		// 推断扩展名时读取的内容仍然完整保存
+		if data, err := grb.ResourceStore().Get(res.Key); err != nil || !bytes.Equal(data, tt.content) {
+			t.Errorf("%s: stored %d bytes, %v, want %d bytes", tt.name, len(data), err, len(tt.content))
+		}
+	}
+}
+
+func TestResourceThumbnail(t *testing.T) {
+	grb, _ := testkit.NewInstant(t)
+	grb.SetResourceThumbnailSize(32)
+
+	tests := []struct {
+		width, height int
+		thumbWidth    int // 为 0 时不生成缩略图
+		thumbHeight   int
+	}{
+		{200, 100, 32, 16},
+		{50, 400, 4, 32},
+		{1000, 1, 32, 1},
+		{32, 32, 0, 0},
+		{20, 10, 0, 0},
+	}
+	for _, tt := range tests {
+		res := savedResource(t, grb, pngContent(t, tt.width, tt.height), "")
+		if res.Mime != "image/png" || res.Width != tt.width || res.Height != tt.height {
+			t.Errorf("%dx%d: got meta %+v", tt.width, tt.height, res.ResourceMeta)
+		}
+		if tt.thumbWidth == 0 {
+			if res.Thumbnail != "" {
+				t.Errorf("%dx%d: unexpected thumbnail %s", tt.width, tt.height, res.Thumbnail)
+			}
+			continue
+		}
+
+		if !strings.HasSuffix(res.Thumbnail, ".jpg") {
+			t.Fatalf("%dx%d: got thumbnail key %q", tt.width, tt.height, res.Thumbnail)
+		}
+		data, err := grb.ResourceStore().Get(res.Thumbnail)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Width != tt.thumbWidth || config.Height != tt.thumbHeight {
+			t.Errorf("%dx%d: got %dx%d thumbnail, want %dx%d", tt.width, tt.height,
+				config.Width, config.Height, tt.thumbWidth, tt.thumbHeight)
+		}
+	}
+
+	// 为 0 时不生成缩略图
+	grb.SetResourceThumbnailSize(0)
+	if res := savedResource(t, grb, pngContent(t, 300, 100), ""); res.Thumbnail != "" || res.Width != 300 {
+		t.Errorf("thumbnail disabled: got meta %+v", res.ResourceMeta)
+	}
+}
+
+func TestResourcesByHash(t *testing.T) {
+	for _, backend := range []string{"memory", "database"} {
+		grb, bot := testkit.NewInstant(t)
+		if backend == "database" {
+			grb, bot = newGCBot(t)
+		}
+		sum := contentHash([]byte(resourceContent))
+
+		local := savedResource(t, grb, []byte(resourceContent), "txt")
+		if local.SHA256 != sum {
+			t.Fatalf("%s: got sha256 %s", backend, local.SHA256)
+		}
+
+		// 未下载的资源没有内容信息，下载后才能按内容找到
+		linked := saveLinkedResource(t, bot)
+		if resources, err := grb.ResourcesByHash(sum); err != nil || len(resources) != 1 {
+			t.Fatalf("%s: before download got %d resources, %v", backend, len(resources), err)
+		}
+		if _, err := grb.LoadResourceFromID(linked); err != nil {
+			t.Fatal(err)
+		}
+
+		resources, err := grb.ResourcesByHash(strings.ToUpper(sum))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, res := range resources {
+			ids = append(ids, res.ID)
+		}
+		sort.Strings(ids)
+		want := []string{local.ID, linked}
+		sort.Strings(want)
+		if strings.Join(ids, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: got %v, want %v", backend, ids, want)
+		}
+
+		if resources, err := grb.ResourcesByHash(contentHash([]byte("other"))); err != nil || len(resources) != 0 {
+			t.Errorf("%s: unknown hash got %d resources, %v", backend, len(resources), err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package GoroBot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	urlpkg "net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3ResourceConfig 是 S3 兼容对象存储的配置，MinIO 等服务使用路径风格的地址
+type S3ResourceConfig struct {
+	Endpoint  string `json:"endpoint"`         // 服务地址，如 http://127.0.0.1:9000
+	Region    string `json:"region,omitempty"` // 默认为 us-east-1
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	Prefix    string `json:"prefix,omitempty"` // 对象名前缀，如 gorobot/
+}
+
+// S3ResourceStore 将资源保存在 S3 兼容的对象存储中，对象名为 <prefix><sha256 前两位>/<sha256 三四位>/<key>
+type S3ResourceStore struct {
+	config   S3ResourceConfig
+	endpoint *urlpkg.URL
+	client   *http.Client
+}
+
+// NewS3ResourceStore 创建 S3 兼容的资源存储，请求使用 AWS Signature V4 签名
+func NewS3ResourceStore(config S3ResourceConfig) (*S3ResourceStore, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 resource store requires endpoint and bucket")
+	}
+	endpoint, err := urlpkg.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3ResourceStore{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (s *S3ResourceStore) Put(r io.Reader, ext string) (string, error) {
+	// 先写入临时文件计算 sha256，同时作为请求的负载哈希
+	tmp, err := os.CreateTemp("", "gorobot-s3-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return "", err
+	}
+	sum := hash.Sum(nil)
+	key := resourceKey(sum, ext)
+
+	if _, err := s.Stat(key); err == nil {
+		return key, nil
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	resp, err := s.do(http.MethodPut, key, tmp, size, hex.EncodeToString(sum))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := s.checkResponse(resp, http.MethodPut, key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *S3ResourceStore) Get(key string) ([]byte, error) {
+	body, err := s.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (s *S3ResourceStore) Open(key string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, key, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkResponse(resp, http.MethodGet, key); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3ResourceStore) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s.checkResponse(resp, http.MethodDelete, key)
+}
+
+func (s *S3ResourceStore) Stat(key string) (ResourceInfo, error) {
+	resp, err := s.do(http.MethodHead, key, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return ResourceInfo{}, err
+	}
+	defer resp.Body.Close()
+	if err := s.checkResponse(resp, http.MethodHead, key); err != nil {
+		return ResourceInfo{}, err
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return ResourceInfo{Key: key, Size: resp.ContentLength, ModTime: modTime}, nil
+}
+
+var emptyPayloadHash = hex.EncodeToString(sha256.New().Sum(nil))
+
+func (s *S3ResourceStore) do(method string, key string, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	name, err := resourceKeyPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.config.Bucket + "/" + s.config.Prefix + name
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = ""
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.sign(req, payloadHash, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+func (s *S3ResourceStore) checkResponse(resp *http.Response, method string, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("s3 %s %s: %w", method, key, fs.ErrNotExist)
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// sign 为请求添加 AWS Signature V4 签名
+func (s *S3ResourceStore) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath 按 SigV4 的规则编码路径，只保留非保留字符和 /
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
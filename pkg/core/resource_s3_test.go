@@ -0,0 +1,273 @@
+package GoroBot_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+)
+
+const (
+	s3AccessKey = "minio"
+	s3SecretKey = "minio-secret"
+	s3Region    = "us-east-1"
+)
+
+// fakeS3 是一个路径风格的 S3 兼容服务，校验每个请求的 SigV4 签名
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	requests []string // "METHOD path"
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	s := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := verifySigV4(r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	object, ok := s.objects[r.URL.Path]
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[r.URL.Path] = body
+	case http.MethodGet, http.MethodHead:
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(object)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object)
+		}
+	case http.MethodDelete:
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *fakeS3) stored(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[path]
+	return ok
+}
+
+func (s *fakeS3) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, req := range s.requests {
+		if strings.HasPrefix(req, method+" ") {
+			n++
+		}
+	}
+	return n
+}
+
+// verifySigV4 按 AWS 文档重新计算签名并与 Authorization 头比较
+func verifySigV4(r *http.Request, body []byte) error {
+	auth := r.Header.Get("Authorization")
+	const algorithm = "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(auth, algorithm) {
+		return fmt.Errorf("unexpected authorization %q", auth)
+	}
+	fields := make(map[string]string)
+	for _, part := range strings.Split(strings.TrimPrefix(auth, algorithm), ", ") {
+		name, value, _ := strings.Cut(part, "=")
+		fields[name] = value
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil {
+		return fmt.Errorf("invalid x-amz-date %q", amzDate)
+	}
+	scope := amzDate[:8] + "/" + s3Region + "/s3/aws4_request"
+	if fields["Credential"] != s3AccessKey+"/"+scope {
+		return fmt.Errorf("unexpected credential %q", fields["Credential"])
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	sum := sha256.Sum256(body)
+	if payloadHash != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("payload hash %s does not match the body", payloadHash)
+	}
+
+	signed := strings.Split(fields["SignedHeaders"], ";")
+	var headers strings.Builder
+	for _, name := range signed {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	for _, required := range []string{"host", "x-amz-content-sha256", "x-amz-date"} {
+		if !strings.Contains(";"+fields["SignedHeaders"]+";", ";"+required+";") {
+			return fmt.Errorf("%s is not signed", required)
+		}
+	}
+
+	canonical := strings.Join([]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, headers.String(), fields["SignedHeaders"], payloadHash}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac([]byte("AWS4"+s3SecretKey), amzDate[:8])
+	key = mac(key, s3Region)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+	if want := hex.EncodeToString(mac(key, stringToSign)); fields["Signature"] != want {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func newS3Store(t *testing.T, endpoint string, secret string) *GoroBot.S3ResourceStore {
+	t.Helper()
+	store, err := GoroBot.NewS3ResourceStore(GoroBot.S3ResourceConfig{
+		Endpoint:  endpoint,
+		Bucket:    "bot",
+		AccessKey: s3AccessKey,
+		SecretKey: secret,
+		Prefix:    "gorobot/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3ResourceStore(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newS3Store(t, server.URL, s3SecretKey)
+
+	key, err := store.Put(strings.NewReader(resourceContent), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != contentKey(resourceContent, "txt") {
+		t.Fatalf("got key %s", key)
+	}
+	if !fake.stored("/bot/gorobot/" + key[:2] + "/" + key[2:4] + "/" + key) {
+		t.Fatal("object not stored under the prefix")
+	}
+
+	// 已存在的内容不会重复上传
+	if again, err := store.Put(strings.NewReader(resourceContent), "txt"); err != nil || again != key {
+		t.Fatalf("second put: %s, %v", again, err)
+	}
+	if n := fake.count(http.MethodPut); n != 1 {
+		t.Fatalf("%d uploads, want 1", n)
+	}
+
+	if data, err := store.Get(key); err != nil || string(data) != resourceContent {
+		t.Fatalf("Get: %q, %v", data, err)
+	}
+	body, err := store.Open(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(data) != resourceContent {
+		t.Fatalf("Open: %q", data)
+	}
+	info, err := store.Stat(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Key != key || info.Size != int64(len(resourceContent)) || info.ModTime.IsZero() {
+		t.Fatalf("Stat: %+v", info)
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	// 不存在的内容返回 fs.ErrNotExist，删除时不报错
+	if _, err := store.Stat(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat after delete: %v", err)
+	}
+	if _, err := store.Open(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Open after delete: %v", err)
+	}
+	if _, err := store.Get(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Get after delete: %v", err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("deleting a missing key: %v", err)
+	}
+
+	if _, err := store.Open("../" + key); err == nil {
+		t.Fatal("invalid key accepted")
+	}
+}
+
+func TestS3ResourceStoreSignature(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newS3Store(t, server.URL, "wrong-secret")
+
+	_, err := store.Put(strings.NewReader(resourceContent), "txt")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("got %v, want a 403 error", err)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("signature error reported as not found: %v", err)
+	}
+	if n := fake.count(http.MethodPut); n != 0 {
+		t.Fatal("object stored with an invalid signature")
+	}
+}
+
+func TestS3ResourceStoreAsInstantStore(t *testing.T) {
+	_, server := newFakeS3(t)
+	grb, bot := newGCBot(t)
+	grb.SetResourcePath(t.TempDir())
+	grb.UseResourceStore(newS3Store(t, server.URL, s3SecretKey))
+
+	id := saveLinkedResource(t, bot)
+	path, err := grb.LoadResourceFromID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 远程存储中的内容缓存在本地后使用
+	checkResourceFile(t, path)
+	if data, err := grb.GetResourceData(id); err != nil || string(data) != resourceContent {
+		t.Fatalf("GetResourceData: %q, %v", data, err)
+	}
+}
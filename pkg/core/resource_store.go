@@ -0,0 +1,284 @@
+package GoroBot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ResourceStore 保存资源文件的内容。key 由内容的 sha256 和扩展名组成，
+// 相同的内容总是得到相同的 key，多个实例可以共享同一个存储
+type ResourceStore interface {
+	// Put 保存 r 的全部内容并返回 key，ext 为不含点的扩展名，可以为空
+	Put(r io.Reader, ext string) (string, error)
+	Get(key string) ([]byte, error)
+	Open(key string) (io.ReadCloser, error)
+	// Delete 删除 key 对应的内容，内容不存在时不返回错误
+	Delete(key string) error
+	// Stat 返回 key 对应内容的信息，内容不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)
+	Stat(key string) (ResourceInfo, error)
+}
+
+// ResourceFileStore 是内容保存在本地磁盘上的 ResourceStore，可以直接提供文件路径
+type ResourceFileStore interface {
+	ResourceStore
+	Path(key string) (string, error)
+}
+
+// ResourceLister 是可以列出全部内容的 ResourceStore，资源回收时会删除其中没有被索引引用的内容
+type ResourceLister interface {
+	List(fn func(info ResourceInfo) error) error
+}
+
+// ResourceInfo 是 ResourceStore 中一份内容的信息
+type ResourceInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// LocalResourceStore 将资源按内容保存在本地目录中，
+// 文件路径为 <root>/<sha256 前两位>/<sha256 三四位>/<key>
+type LocalResourceStore struct {
+	root string
+}
+
+// NewLocalResourceStore 创建以 root 为根目录的本地资源存储
+func NewLocalResourceStore(root string) *LocalResourceStore {
+	return &LocalResourceStore{root: root}
+}
+
+func (s *LocalResourceStore) Put(r io.Reader, ext string) (string, error) {
+	if err := os.MkdirAll(s.root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create resource directory %s: %v", s.root, err)
+	}
+	tmp, err := os.CreateTemp(s.root, ".put-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	key := resourceKey(hash.Sum(nil), ext)
+	filePath, _ := s.Path(key)
+	if _, err := os.Stat(filePath); err == nil {
+		// 更新修改时间，避免刚被重新引用的内容被当作孤立文件回收
+		now := time.Now()
+		_ = os.Chtimes(filePath, now, now)
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create resource directory %s: %v", filepath.Dir(filePath), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", fmt.Errorf("failed to write resource file %s: %v", filePath, err)
+	}
+	return key, nil
+}
+
+func (s *LocalResourceStore) Get(key string) ([]byte, error) {
+	filePath, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filePath)
+}
+
+func (s *LocalResourceStore) Open(key string) (io.ReadCloser, error) {
+	filePath, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filePath)
+}
+
+func (s *LocalResourceStore) Delete(key string) error {
+	filePath, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalResourceStore) Stat(key string) (ResourceInfo, error) {
+	filePath, err := s.Path(key)
+	if err != nil {
+		return ResourceInfo{}, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return ResourceInfo{}, err
+	}
+	return ResourceInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// List 遍历根目录下的全部内容，不在分片目录中的文件会被忽略
+func (s *LocalResourceStore) List(fn func(info ResourceInfo) error) error {
+	err := filepath.WalkDir(s.root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return nil
+		}
+		if name, err := resourceKeyPath(entry.Name()); err != nil || name != filepath.ToSlash(rel) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		return fn(ResourceInfo{Key: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Path 返回 key 对应的文件路径，不检查文件是否存在
+func (s *LocalResourceStore) Path(key string) (string, error) {
+	name, err := resourceKeyPath(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(name)), nil
+}
+
+// UseResourceStore 设置保存资源文件的存储，多个实例使用同一个远程存储时可以共享资源文件
+func (i *Instant) UseResourceStore(store ResourceStore) {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	i.resourceStore = store
+}
+
+// ResourceStore 返回当前使用的资源存储。未设置时，配置了 resource_s3 则使用 S3 兼容存储，
+// 否则使用 resource_path（默认为 resources）下的本地存储
+func (i *Instant) ResourceStore() ResourceStore {
+	i.resourceStoreMu.Lock()
+	defer i.resourceStoreMu.Unlock()
+	if i.resourceStore != nil {
+		return i.resourceStore
+	}
+	if i.config.ResourceS3 != nil {
+		store, err := NewS3ResourceStore(*i.config.ResourceS3)
+		if err == nil {
+			i.resourceStore = store
+			return store
+		}
+		i.logger.Error("create s3 resource store failed, using local store: %v", err)
+	}
+	i.resourceStore = NewLocalResourceStore(i.resourceRoot())
+	return i.resourceStore
+}
+
+func (i *Instant) resourceRoot() string {
+	if i.config.ResourcePath != "" {
+		return i.config.ResourcePath
+	}
+	return "resources"
+}
+
+// resourceCache 是远程存储的内容在本地的缓存
+func (i *Instant) resourceCache() *LocalResourceStore {
+	return NewLocalResourceStore(filepath.Join(i.resourceRoot(), "cache"))
+}
+
+// resourceStorePath 返回 key 在本地存储中的文件路径，远程存储返回空字符串
+func (i *Instant) resourceStorePath(key string) string {
+	if store, ok := i.ResourceStore().(ResourceFileStore); ok {
+		if filePath, err := store.Path(key); err == nil {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// resourceFile 返回 key 对应内容的本地文件路径，远程存储中的内容会先缓存到 <resource_path>/cache 下
+func (i *Instant) resourceFile(key string) (string, error) {
+	store := i.ResourceStore()
+	if fileStore, ok := store.(ResourceFileStore); ok {
+		filePath, err := fileStore.Path(key)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			return "", err
+		}
+		return filePath, nil
+	}
+
+	cache := i.resourceCache()
+	filePath, err := cache.Path(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath, nil
+	}
+
+	body, err := store.Open(key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	_, ext, _ := strings.Cut(key, ".")
+	cached, err := cache.Put(body, ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to cache resource %s: %v", key, err)
+	}
+	if cached != key {
+		_ = cache.Delete(cached)
+		return "", fmt.Errorf("resource %s is corrupted in store", key)
+	}
+	return filePath, nil
+}
+
+// resourceKey 由内容的 sha256 和扩展名生成 key，扩展名含有字母和数字以外的字符时忽略
+func resourceKey(sum []byte, ext string) string {
+	key := hex.EncodeToString(sum)
+	if ext = strings.TrimLeft(ext, "."); ext != "" && validResourceExt(ext) {
+		key += "." + ext
+	}
+	return key
+}
+
+func validResourceExt(ext string) bool {
+	for _, r := range ext {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceKeyPath 校验 key 并返回分片后的相对路径，如 ab/cd/abcd....png
+func resourceKeyPath(key string) (string, error) {
+	hash, ext, _ := strings.Cut(key, ".")
+	if len(hash) != sha256.Size*2 || strings.Trim(hash, "0123456789abcdef") != "" || !validResourceExt(ext) {
+		return "", fmt.Errorf("invalid resource key %q", key)
+	}
+	return hash[:2] + "/" + hash[2:4] + "/" + key, nil
+}
@@ -0,0 +1,201 @@
+package GoroBot_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	urlpkg "net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func contentKey(content string, ext string) string {
+	sum := sha256.Sum256([]byte(content))
+	if ext == "" {
+		return hex.EncodeToString(sum[:])
+	}
+	return hex.EncodeToString(sum[:]) + "." + ext
+}
+
+func listKeys(t *testing.T, store GoroBot.ResourceLister) []string {
+	t.Helper()
+	var keys []string
+	if err := store.List(func(info GoroBot.ResourceInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLocalResourceStorePut(t *testing.T) {
+	root := t.TempDir()
+	store := GoroBot.NewLocalResourceStore(root)
+
+	key, err := store.Put(strings.NewReader("hello"), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != contentKey("hello", "txt") {
+		t.Fatalf("got key %s", key)
+	}
+	filePath, err := store.Path(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, key[:2], key[2:4], key); filePath != want {
+		t.Fatalf("got path %s, want %s", filePath, want)
+	}
+
+	// 相同内容得到相同的 key，只保存一份并刷新修改时间
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	again, err := store.Put(strings.NewReader("hello"), ".txt")
+	if err != nil || again != key {
+		t.Fatalf("second put: %s, %v", again, err)
+	}
+	if info, err := store.Stat(key); err != nil || !info.ModTime.After(old) || info.Size != 5 {
+		t.Fatalf("Stat: %+v, %v", info, err)
+	}
+	if keys := listKeys(t, store); len(keys) != 1 {
+		t.Fatalf("got %v after saving the same content twice", keys)
+	}
+
+	// 扩展名不同时是不同的内容，非法扩展名被忽略
+	if other, _ := store.Put(strings.NewReader("hello"), "md"); other == key {
+		t.Fatal("different extensions share a key")
+	}
+	if bare, _ := store.Put(strings.NewReader("hello"), "t/x"); bare != contentKey("hello", "") {
+		t.Fatalf("invalid extension kept: %s", bare)
+	}
+
+	if data, err := store.Get(key); err != nil || string(data) != "hello" {
+		t.Fatalf("Get: %q, %v", data, err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Stat(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat after delete: %v", err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("deleting a missing key: %v", err)
+	}
+}
+
+func TestLocalResourceStoreList(t *testing.T) {
+	root := t.TempDir()
+	store := GoroBot.NewLocalResourceStore(root)
+	if keys := listKeys(t, GoroBot.NewLocalResourceStore(filepath.Join(root, "missing"))); len(keys) != 0 {
+		t.Fatalf("missing root: got %v", keys)
+	}
+
+	a, _ := store.Put(strings.NewReader("a"), "png")
+	b, _ := store.Put(strings.NewReader("b"), "")
+
+	// 不在分片目录中或名称不是 key 的文件都会被忽略
+	c := contentKey("c", "txt")
+	for _, name := range []string{
+		c,                                        // 没有分片
+		filepath.Join("00", "00", c),             // 分片与 key 不符
+		filepath.Join(c[:2], c[2:4], "x"),        // 不是 key
+		".put-123",                               // 未完成的 Put
+		filepath.Join("cache", c[:2], c[2:4], c), // 嵌套的存储
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("c"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{a, b}
+	sort.Strings(want)
+	if keys := listKeys(t, store); strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", keys, want)
+	}
+}
+
+func TestResourceKeyValidation(t *testing.T) {
+	store := GoroBot.NewLocalResourceStore(t.TempDir())
+	hash := contentKey("x", "")
+	for _, key := range []string{
+		"",
+		hash[:63],
+		hash + "0",
+		strings.ToUpper(hash),
+		hash[:62] + "zz",
+		hash + ".p-g",
+		hash + "./../../etc",
+		"../" + hash[3:],
+	} {
+		if _, err := store.Path(key); err == nil {
+			t.Errorf("Path(%q) accepted an invalid key", key)
+		}
+		if _, err := store.Get(key); err == nil || errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Get(%q) = %v, want a validation error", key, err)
+		}
+	}
+	for _, key := range []string{hash, hash + ".png", hash + ".JPG"} {
+		if _, err := store.Path(key); err != nil {
+			t.Errorf("Path(%q): %v", key, err)
+		}
+	}
+}
+
+// stagingBot 将资源写入核心指定的 target 路径，记录收到的路径
+type stagingBot struct {
+	*testkit.Bot
+	targets chan string
+}
+
+func (b *stagingBot) DownloadResourceFromRefLink(refLink string) (string, error) {
+	values, err := urlpkg.ParseQuery(refLink)
+	if err != nil {
+		return "", err
+	}
+	target := values.Get("target")
+	b.targets <- target
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	return "", os.WriteFile(target, []byte(resourceContent), 0644)
+}
+
+func TestDownloadTargetInResourcePath(t *testing.T) {
+	root := t.TempDir()
+	grb := GoroBot.Create()
+	grb.SetResourcePath(root)
+	grb.UseResourceStore(GoroBot.NewLocalResourceStore(filepath.Join(root, "store")))
+	bot := &stagingBot{Bot: testkit.New(nil), targets: make(chan string, 1)}
+	grb.AddContext(bot)
+
+	id := grb.SaveResourceLink(bot.ID(), "ext=txt")
+	path, err := grb.LoadResourceFromID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResourceFile(t, path)
+
+	target := <-bot.targets
+	if filepath.Dir(target) != root || filepath.Base(target) != id+".txt" {
+		t.Fatalf("adapter asked to write %s, want %s", target, filepath.Join(root, id+".txt"))
+	}
+	if _, err := os.Stat(target); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("staged file left behind: %v", err)
+	}
+}
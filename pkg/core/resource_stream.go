@@ -0,0 +1,195 @@
+package GoroBot
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+var errResourceExists = errors.New("resource already exists")
+
+// ResourceOptions 控制资源的下载
+type ResourceOptions struct {
+	MaxSize  int64             // 单个文件的大小上限（字节），为 0 时使用 resource_limit 中的 max_file_size，小于 0 表示不限制
+	Progress util.ProgressFunc // 下载进度，可以为 nil
+}
+
+func (i *Instant) transferOptions(opts ResourceOptions) util.TransferOptions {
+	maxSize := opts.MaxSize
+	if maxSize == 0 {
+		maxSize = i.ResourceLimit().MaxFileSize << 20
+	}
+	return util.TransferOptions{MaxSize: maxSize, Progress: opts.Progress}
+}
+
+// OpenResource 打开资源内容的流，必要时先通过协议适配器下载，调用方负责关闭。
+// 与 LoadResourceFromID 不同，使用远程存储时不会缓存到本地
+func (i *Instant) OpenResource(id string) (io.ReadCloser, Resource, error) {
+	return i.OpenResourceContext(context.Background(), id, ResourceOptions{})
+}
+
+// OpenResourceContext 与 OpenResource 相同，下载可以通过 ctx 取消，并按 opts 限制大小和报告进度
+func (i *Instant) OpenResourceContext(ctx context.Context, id string, opts ResourceOptions) (io.ReadCloser, Resource, error) {
+	res, err := i.lookupResource(id)
+	if err != nil {
+		return nil, res, err
+	}
+
+	switch {
+	case res.Key != "":
+		body, err := i.openResourceKey(res.Key)
+		if err == nil {
+			i.touchResource(id)
+			return body, res, nil
+		}
+		// 内容被淘汰后，还有引用信息的资源可以重新下载
+		if !errors.Is(err, fs.ErrNotExist) || res.RefLink == "" {
+			return nil, res, err
+		}
+	case res.FilePath != "":
+		// 旧版本保存的资源直接记录了文件路径
+		if file, err := os.Open(res.FilePath); err == nil {
+			i.touchResource(id)
+			return file, res, nil
+		}
+	}
+
+	if res.Error != "" {
+		return nil, res, errors.New(res.Error)
+	}
+
+	if res, err = i.loadRemoteResource(ctx, res, opts); err != nil {
+		return nil, res, err
+	}
+	body, err := i.openResourceKey(res.Key)
+	return body, res, err
+}
+
+// openResourceKey 打开 key 对应的内容，远程存储的内容已经缓存在本地时直接读取缓存
+func (i *Instant) openResourceKey(key string) (io.ReadCloser, error) {
+	store := i.ResourceStore()
+	if _, ok := store.(ResourceFileStore); !ok {
+		if filePath, err := i.resourceCache().Path(key); err == nil {
+			if file, err := os.Open(filePath); err == nil {
+				return file, nil
+			}
+		}
+	}
+	return store.Open(key)
+}
+
+// SaveResourceReader 将 r 的全部内容保存到 ResourceStore，返回以内容 md5 为 ID 的资源 ID
+func (i *Instant) SaveResourceReader(r io.Reader, ext string) (string, error) {
+	hash := md5.New()
+	reader := util.NewTransferReader(io.TeeReader(r, hash), -1, util.TransferOptions{})
+	key, err := i.ResourceStore().Put(sniffResource(reader, ext))
+	if err != nil {
+		return "", fmt.Errorf("failed to store resource: %v", err)
+	}
+
+	id := hex.EncodeToString(hash.Sum(nil))
+	if i.ResourceExists(id) {
+		return id, nil
+	}
+
+	now := time.Now()
+	resource := Resource{
+		ID:         id,
+		Protocol:   "local",
+		Key:        key,
+		FilePath:   i.resourceStorePath(key),
+		Downloaded: now,
+		Size:       reader.Done(),
+		Accessed:   now,
+	}
+	if resource.ResourceMeta, err = i.resourceMeta(resource); err != nil {
+		i.logger.Warning("detect resource %s meta failed: %v", id, err)
+	}
+	if err := i.saveResourceIndex(resource); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// SaveRemoteResourceContext 以流的方式下载资源文件保存到 ResourceStore，并更新资源索引。
+// 资源 ID 为内容的 md5，已经保存过相同内容时返回错误。下载占用下载池的名额，失败时按 resource_download 的设置重试
+func (i *Instant) SaveRemoteResourceContext(ctx context.Context, resourceURL string, opts ResourceOptions) (*Resource, error) {
+	if resourceURL == "" {
+		return nil, fmt.Errorf("resourceURL is empty")
+	}
+
+	var res *Resource
+	err := i.retryDownload(ctx, func() error {
+		release, err := i.downloadPool().acquire(ctx, "")
+		if err != nil {
+			return err
+		}
+		defer release()
+		res, err = i.saveRemoteResource(ctx, resourceURL, i.transferOptions(opts))
+		return err
+	})
+	return res, err
+}
+
+func (i *Instant) saveRemoteResource(ctx context.Context, resourceURL string, transfer util.TransferOptions) (*Resource, error) {
+	resp, err := util.OpenURL(ctx, nil, resourceURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	hash := md5.New()
+	reader := util.NewTransferReader(io.TeeReader(resp.Body, hash), resp.ContentLength, transfer)
+	key, err := i.ResourceStore().Put(sniffResource(reader, remoteResourceExt(resp)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store resource %s: %w", resourceURL, err)
+	}
+
+	id := hex.EncodeToString(hash.Sum(nil))
+	if i.ResourceExists(id) {
+		return nil, fmt.Errorf("resource %s: %w", resourceURL, errResourceExists)
+	}
+
+	now := time.Now()
+	resource := Resource{
+		ID:         id,
+		Protocol:   "local",
+		Key:        key,
+		FilePath:   i.resourceStorePath(key),
+		Downloaded: now,
+		Size:       reader.Done(),
+		Accessed:   now,
+	}
+	if resource.ResourceMeta, err = i.resourceMeta(resource); err != nil {
+		i.logger.Warning("detect resource %s meta failed: %v", id, err)
+	}
+	if err := i.saveResourceIndex(resource); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// remoteResourceExt 根据 Content-Disposition 的 filename 参数或 Content-Type 推断扩展名
+func remoteResourceExt(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		// filename* 形式的参数会被解码后放入 filename
+		if ext := path.Ext(params["filename"]); ext != "" {
+			return ext
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		return mimeExt(mediaType)
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package GoroBot_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestSaveRemoteResourceExt(t *testing.T) {
+	tests := []struct {
+		disposition string
+		contentType string
+		ext         string
+	}{
+		{`attachment; filename="report.pdf"`, "", "pdf"},
+		{`attachment; filename="photo.png"; size=123`, "", "png"},
+		{`attachment; filename="a; b.csv"`, "", "csv"},
+		{`attachment; filename*=UTF-8''%E6%8A%A5%E5%91%8A.md`, "", "md"},
+		{`attachment; filename="../dir/notes.TXT"`, "", "TXT"},
+		// 没有 filename 参数或无法解析时使用 Content-Type
+		{"inline", "image/gif", "gif"},
+		{`attachment; filename="noext"`, "application/pdf; charset=binary", "pdf"},
+		{`attachment; filename=`, "image/webp", "webp"},
+		// 都无法推断时根据内容判断
+		{"", "application/octet-stream", "txt"},
+	}
+
+	mux := http.NewServeMux()
+	for n, tt := range tests {
+		mux.HandleFunc(fmt.Sprintf("/%d", n), func(w http.ResponseWriter, r *http.Request) {
+			if tt.disposition != "" {
+				w.Header().Set("Content-Disposition", tt.disposition)
+			}
+			w.Header().Set("Content-Type", tt.contentType)
+			// 每个响应的内容不同，避免资源 ID 重复
+			_, _ = fmt.Fprintf(w, "plain text %d", n)
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	grb, _ := testkit.NewInstant(t)
+	for n, tt := range tests {
+		res, err := grb.SaveRemoteResourceContext(context.Background(), fmt.Sprintf("%s/%d", server.URL, n), GoroBot.ResourceOptions{})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.disposition, err)
+		}
+		if _, ext, _ := strings.Cut(res.Key, "."); ext != tt.ext {
+			t.Errorf("%q %q: got key %s, want extension %q", tt.disposition, tt.contentType, res.Key, tt.ext)
+		}
+	}
+}
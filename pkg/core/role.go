@@ -0,0 +1,276 @@
+package GoroBot
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// Role 是角色表中的一条记录，Scope 为群组 ID，为空时全局生效
+type Role struct {
+	UserID    string
+	Scope     string
+	Authority entity.Authority
+}
+
+type roleKey struct {
+	userID string
+	scope  string
+}
+
+// GrantRole 授予用户权限，scope 为群组 ID，为空时全局生效。
+// 连接数据库时角色会被持久化
+func (i *Instant) GrantRole(userID string, scope string, authority entity.Authority) error {
+	if err := i.loadRoles(); err != nil {
+		return err
+	}
+
+	if i.DatabaseExist() {
+		tx, err := i.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM ROLES WHERE USER_ID = ? AND SCOPE = ?`, userID, scope); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO ROLES (USER_ID, SCOPE, AUTHORITY) VALUES (?, ?, ?)`, userID, scope, int(authority)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	i.rolesMu.Lock()
+	defer i.rolesMu.Unlock()
+	i.roles[roleKey{userID: userID, scope: scope}] = authority
+	return nil
+}
+
+// RevokeRole 撤销用户在 scope 中的角色，返回角色是否存在
+func (i *Instant) RevokeRole(userID string, scope string) (bool, error) {
+	if err := i.loadRoles(); err != nil {
+		return false, err
+	}
+
+	if i.DatabaseExist() {
+		if _, err := i.db.Exec(`DELETE FROM ROLES WHERE USER_ID = ? AND SCOPE = ?`, userID, scope); err != nil {
+			return false, err
+		}
+	}
+
+	i.rolesMu.Lock()
+	defer i.rolesMu.Unlock()
+	key := roleKey{userID: userID, scope: scope}
+	_, ok := i.roles[key]
+	delete(i.roles, key)
+	return ok, nil
+}
+
+// Roles 返回角色表中的所有记录，按 Scope 和 UserID 排序
+func (i *Instant) Roles() ([]Role, error) {
+	if err := i.loadRoles(); err != nil {
+		return nil, err
+	}
+
+	i.rolesMu.RLock()
+	defer i.rolesMu.RUnlock()
+	roles := make([]Role, 0, len(i.roles))
+	for key, authority := range i.roles {
+		roles = append(roles, Role{UserID: key.userID, Scope: key.scope, Authority: authority})
+	}
+	sort.Slice(roles, func(a, b int) bool {
+		if roles[a].Scope != roles[b].Scope {
+			return roles[a].Scope < roles[b].Scope
+		}
+		return roles[a].UserID < roles[b].UserID
+	})
+	return roles, nil
+}
+
+// Authority 返回消息发送者的实际权限：
+// 配置中的 owner 始终为 Owner；全局 Banned 在所有群组中生效，
+// 其余情况下群组内的角色优先于全局角色，角色为 Banned 时为 Banned，否则取角色与平台权限中较高的一个。
+// 平台权限只来自适配器，没有设置（零值 Banned）时视为 Member，封禁只能通过角色表设置
+func (i *Instant) Authority(msg botc.MessageContext) entity.Authority {
+	base := msg.Message()
+	if base == nil || base.Sender == nil || base.Sender.User == nil || base.Sender.Base == nil {
+		return entity.Member
+	}
+	sender := base.Sender
+	platform := max(sender.Authority, entity.Member)
+
+	if owner, ok := i.GetOwner(msg.BotContext().ID()); ok && owner == sender.ID {
+		return entity.Owner
+	}
+
+	if err := i.loadRoles(); err != nil {
+		i.logger.Error("load roles failed: %v", err)
+		return platform
+	}
+
+	i.rolesMu.RLock()
+	defer i.rolesMu.RUnlock()
+	role, ok := i.roles[roleKey{userID: sender.ID}]
+	if ok && role == entity.Banned {
+		return entity.Banned
+	}
+	if base.MessageType == botc.GroupMessage {
+		if groupRole, groupOk := i.roles[roleKey{userID: sender.ID, scope: base.ChatID()}]; groupOk {
+			role, ok = groupRole, true
+		}
+	}
+	switch {
+	case !ok:
+		return platform
+	case role == entity.Banned:
+		return entity.Banned
+	default:
+		return max(role, platform)
+	}
+}
+
+// loadRoles 在第一次使用时从数据库加载角色表，未连接数据库时只使用内存
+func (i *Instant) loadRoles() error {
+	i.rolesMu.Lock()
+	defer i.rolesMu.Unlock()
+
+	if i.rolesLoaded || !i.DatabaseExist() {
+		return nil
+	}
+
+	if err := ensureRoleTable(i.db); err != nil {
+		return fmt.Errorf("failed to create role table: %v", err)
+	}
+
+	rows, err := i.db.Query(`SELECT USER_ID, SCOPE, AUTHORITY FROM ROLES`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key       roleKey
+			authority int
+		)
+		if err := rows.Scan(&key.userID, &key.scope, &authority); err != nil {
+			return err
+		}
+		i.roles[key] = entity.Authority(authority)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	i.rolesLoaded = true
+	return nil
+}
+
+func ensureRoleTable(db *sql.DB) error {
+	_, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS ROLES (
+    USER_ID TEXT NOT NULL,
+    SCOPE TEXT NOT NULL,
+    AUTHORITY INTEGER NOT NULL,
+    PRIMARY KEY (USER_ID, SCOPE)
+);`)
+	return err
+}
+
+func (i *Instant) initRoleCmd() {
+	cmd := i.Command("role").
+		Description("管理用户权限").
+		Permission(entity.Admin)
+
+	cmd.SubCommand("grant").
+		Description("授予用户权限").
+		Argument("user", command.String, true, "用户 ID").
+		Argument("authority", command.String, true, "banned / member / group_admin / group_owner / admin / owner").
+		Option("group", "g", command.String, false, "", "只在该群组中生效").
+		Action(func(ctx *command.Context) error {
+			authority, ok := entity.ParseAuthority(ctx.KvArgs["authority"])
+			if !ok {
+				return fmt.Errorf("unknown authority %s", ctx.KvArgs["authority"])
+			}
+			if err := canManage(ctx, authority); err != nil {
+				return err
+			}
+			if err := i.GrantRole(ctx.KvArgs["user"], ctx.Options["group"], authority); err != nil {
+				return err
+			}
+			_, _ = ctx.ReplyText(fmt.Sprintf("已授予 %s %s 权限", ctx.KvArgs["user"], authority))
+			return nil
+		})
+
+	cmd.SubCommand("revoke").
+		Description("撤销用户权限").
+		Argument("user", command.String, true, "用户 ID").
+		Option("group", "g", command.String, false, "", "撤销该群组中的权限").
+		Action(func(ctx *command.Context) error {
+			userID, scope := ctx.KvArgs["user"], ctx.Options["group"]
+			if err := i.loadRoles(); err != nil {
+				return err
+			}
+			i.rolesMu.RLock()
+			authority, ok := i.roles[roleKey{userID: userID, scope: scope}]
+			i.rolesMu.RUnlock()
+			if ok {
+				if err := canManage(ctx, authority); err != nil {
+					return err
+				}
+			}
+			if ok, err := i.RevokeRole(userID, scope); err != nil {
+				return err
+			} else if !ok {
+				_, _ = ctx.ReplyText(fmt.Sprintf("%s 没有单独设置的权限", userID))
+				return nil
+			}
+			_, _ = ctx.ReplyText(fmt.Sprintf("已撤销 %s 的权限", userID))
+			return nil
+		})
+
+	cmd.SubCommand("list").
+		Description("列出所有单独设置的权限").
+		Option("group", "g", command.String, false, "", "只列出该群组中的权限").
+		Action(func(ctx *command.Context) error {
+			roles, err := i.Roles()
+			if err != nil {
+				return err
+			}
+			var b strings.Builder
+			for _, role := range roles {
+				if group := ctx.Options["group"]; group != "" && role.Scope != group {
+					continue
+				}
+				scope := "全局"
+				if role.Scope != "" {
+					scope = role.Scope
+				}
+				fmt.Fprintf(&b, "\n%s  %s  %s", role.UserID, role.Authority, scope)
+			}
+			if b.Len() == 0 {
+				_, _ = ctx.ReplyText("没有单独设置的权限")
+				return nil
+			}
+			_, _ = ctx.ReplyText("权限列表：" + b.String())
+			return nil
+		})
+
+	_, _ = cmd.Build()
+}
+
+// canManage 只允许授予或撤销低于自身的权限，Owner 不受限制
+func canManage(ctx *command.Context, authority entity.Authority) error {
+	if actor := ctx.Authority(); actor != entity.Owner && authority >= actor {
+		return fmt.Errorf("%w: cannot manage %s as %s", command.ErrPermissionDenied, authority, actor)
+	}
+	return nil
+}
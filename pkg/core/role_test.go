@@ -0,0 +1,119 @@
+package GoroBot_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newRoleBot(t *testing.T) (*GoroBot.Instant, *testkit.Bot) {
+	t.Helper()
+	grb, bot := testkit.NewInstant(t)
+	for _, cmd := range []*command.FormatBuilder{
+		grb.Command("open"),
+		grb.Command("member").Permission(entity.Member),
+	} {
+		if _, err := cmd.Action(func(ctx *command.Context) error {
+			_, _ = ctx.ReplyText("ok")
+			return nil
+		}).Build(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return grb, bot
+}
+
+func TestUnsetAuthorityIsMember(t *testing.T) {
+	grb, bot := newRoleBot(t)
+
+	// 适配器没有设置平台权限时为零值，视为 Member
+	msg := bot.NewTextMessage("/member").WithAuthority(0)
+	if got := bot.RunCommand(t, msg, "member"); got != "ok" {
+		t.Fatalf("got %q", got)
+	}
+	if got := grb.Authority(msg); got != entity.Member {
+		t.Fatalf("authority %s, want member", got)
+	}
+}
+
+func TestBannedRole(t *testing.T) {
+	grb, bot := newRoleBot(t)
+	user := testkit.UserID("mallory")
+	if err := grb.GrantRole(user, "", entity.Banned); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有设置 Permission 的命令同样拒绝
+	for _, name := range []string{"open", "member"} {
+		msg := bot.NewTextMessage("/" + name).From(user)
+		if got := bot.RunCommand(t, msg, name); !strings.Contains(got, "permission denied") {
+			t.Fatalf("%s: got %q", name, got)
+		}
+	}
+
+	if ok, err := grb.RevokeRole(user, ""); err != nil || !ok {
+		t.Fatalf("revoke: %t, %v", ok, err)
+	}
+	if got := bot.RunCommand(t, bot.NewTextMessage("/open").From(user), "open"); got != "ok" {
+		t.Fatalf("after revoke: got %q", got)
+	}
+}
+
+func TestGroupRole(t *testing.T) {
+	grb, bot := newRoleBot(t)
+	user, group := testkit.UserID("alice"), testkit.GroupID("g1")
+	if err := grb.GrantRole(user, group, entity.Admin); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := grb.Authority(bot.NewTextMessage("").From(user).InGroup(group)); got != entity.Admin {
+		t.Fatalf("in group: %s, want admin", got)
+	}
+	if got := grb.Authority(bot.NewTextMessage("").From(user).InGroup(testkit.GroupID("g2"))); got != entity.Member {
+		t.Fatalf("in other group: %s, want member", got)
+	}
+	// 平台权限较高时取平台权限
+	if got := grb.Authority(bot.NewTextMessage("").From(user).InGroup(group).WithAuthority(entity.Owner)); got != entity.Owner {
+		t.Fatalf("with platform owner: %s, want owner", got)
+	}
+}
+
+func TestRevokeLoadsRoles(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bot.db")
+	bob := testkit.UserID("bob")
+
+	first := GoroBot.Create()
+	if err := first.OpenDatabase("sqlite3", dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.GrantRole(bob, "", entity.Admin); err != nil {
+		t.Fatal(err)
+	}
+	_ = first.Database().Close()
+
+	// 重启后第一次撤销也要检查已保存的角色，Admin 不能撤销其他 Admin
+	grb := GoroBot.Create()
+	if err := grb.OpenDatabase("sqlite3", dbPath); err != nil {
+		t.Fatal(err)
+	}
+	defer grb.Database().Close()
+	bot := testkit.New(grb)
+
+	msg := bot.NewTextMessage("/role revoke " + bob).WithAuthority(entity.Admin)
+	if got := bot.RunCommand(t, msg, "role revoke "+bob); !strings.Contains(got, "permission denied") {
+		t.Fatalf("got %q", got)
+	}
+	roles, err := grb.Roles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].UserID != bob {
+		t.Fatalf("roles changed: %+v", roles)
+	}
+}
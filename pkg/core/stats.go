@@ -0,0 +1,318 @@
+package GoroBot
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// 未连接数据库时内存中最多保留的命令调用记录数
+const memoryCommandLogSize = 1000
+
+// CommandRecord 是命令调用日志中的一条记录，Command 为以空格分隔的命令路径，如 "plugin load"
+type CommandRecord struct {
+	Command    string
+	BotID      string
+	Protocol   string
+	ChatID     string
+	UserID     string
+	Privileged bool // 命令设置了高于 Member 的权限要求
+	Time       time.Time
+	Duration   time.Duration
+	Error      string // 执行成功时为空
+}
+
+// CommandRecordFilter 是查询命令调用日志的条件，零值字段表示不限制
+type CommandRecordFilter struct {
+	Command    string // 命令路径前缀，"plugin" 会匹配 "plugin load"
+	UserID     string
+	Since      time.Time
+	Privileged bool // 只返回特权命令的记录
+	Limit      int
+}
+
+// CommandStat 是一个命令在一段时间内的使用统计
+type CommandStat struct {
+	Command     string
+	Count       int
+	Users       int
+	Failures    int
+	AvgDuration time.Duration
+	LastUsed    time.Time
+}
+
+// CommandRecords 按时间从新到旧返回符合条件的命令调用记录。
+// 连接数据库时记录保存在 COMMAND_LOGS 表中，否则只保留内存中最近的记录
+func (i *Instant) CommandRecords(filter CommandRecordFilter) ([]CommandRecord, error) {
+	if err := i.ensureCommandLog(); err != nil {
+		return nil, err
+	}
+
+	if !i.DatabaseExist() {
+		i.commandLogMu.Lock()
+		defer i.commandLogMu.Unlock()
+		var records []CommandRecord
+		n := len(i.commandLog)
+		for k := 1; k <= n; k++ {
+			if filter.Limit > 0 && len(records) >= filter.Limit {
+				break
+			}
+			if record := i.commandLog[(i.commandLogNext-k+n)%n]; filter.match(record) {
+				records = append(records, record)
+			}
+		}
+		return records, nil
+	}
+
+	query := `SELECT COMMAND, BOT_ID, PROTOCOL, CHAT_ID, USER_ID, PRIVILEGED, TIME, DURATION, ERROR FROM COMMAND_LOGS WHERE 1 = 1`
+	var args []any
+	if filter.Command != "" {
+		query += ` AND (COMMAND = ? OR COMMAND LIKE ?)`
+		args = append(args, filter.Command, filter.Command+" %")
+	}
+	if filter.UserID != "" {
+		query += ` AND USER_ID = ?`
+		args = append(args, filter.UserID)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND TIME >= ?`
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if filter.Privileged {
+		query += ` AND PRIVILEGED = 1`
+	}
+	query += ` ORDER BY TIME DESC, ID DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	}
+
+	rows, err := i.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []CommandRecord
+	for rows.Next() {
+		var (
+			record     CommandRecord
+			privileged int
+			t          int64
+			duration   int64
+		)
+		if err := rows.Scan(&record.Command, &record.BotID, &record.Protocol, &record.ChatID, &record.UserID, &privileged, &t, &duration, &record.Error); err != nil {
+			return nil, err
+		}
+		record.Privileged = privileged != 0
+		record.Time = time.UnixMilli(t)
+		record.Duration = time.Duration(duration)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// CommandStats 返回 since 之后各命令的使用统计，按调用次数从多到少排序
+func (i *Instant) CommandStats(since time.Time) ([]CommandStat, error) {
+	records, err := i.CommandRecords(CommandRecordFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*CommandStat)
+	users := make(map[string]map[string]bool)
+	total := make(map[string]time.Duration)
+	for _, record := range records {
+		stat, ok := stats[record.Command]
+		if !ok {
+			stat = &CommandStat{Command: record.Command}
+			stats[record.Command] = stat
+			users[record.Command] = make(map[string]bool)
+		}
+		stat.Count++
+		if record.Error != "" {
+			stat.Failures++
+		}
+		if record.Time.After(stat.LastUsed) {
+			stat.LastUsed = record.Time
+		}
+		users[record.Command][record.UserID] = true
+		total[record.Command] += record.Duration
+	}
+
+	result := make([]CommandStat, 0, len(stats))
+	for name, stat := range stats {
+		stat.Users = len(users[name])
+		stat.AvgDuration = total[name] / time.Duration(stat.Count)
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Count != result[b].Count {
+			return result[a].Count > result[b].Count
+		}
+		return result[a].Command < result[b].Command
+	})
+	return result, nil
+}
+
+func (f CommandRecordFilter) match(record CommandRecord) bool {
+	switch {
+	case f.Command != "" && record.Command != f.Command && !strings.HasPrefix(record.Command, f.Command+" "):
+		return false
+	case f.UserID != "" && record.UserID != f.UserID:
+		return false
+	case !f.Since.IsZero() && record.Time.Before(f.Since):
+		return false
+	case f.Privileged && !record.Privileged:
+		return false
+	}
+	return true
+}
+
+// recordCommand 是命令系统的 Recorder，将调用记录写入数据库或内存
+func (i *Instant) recordCommand(r command.Record) {
+	record := CommandRecord{
+		Command:    strings.Join(r.Commands, " "),
+		BotID:      r.BotID,
+		Protocol:   r.Protocol,
+		ChatID:     r.ChatID,
+		UserID:     r.SenderID,
+		Privileged: r.Privileged,
+		Time:       r.Time,
+		Duration:   r.Duration,
+	}
+	if r.Err != nil {
+		record.Error = r.Err.Error()
+	}
+
+	if err := i.ensureCommandLog(); err != nil {
+		i.logger.Error("record command %s failed: %v", record.Command, err)
+		return
+	}
+
+	if !i.DatabaseExist() {
+		i.commandLogMu.Lock()
+		defer i.commandLogMu.Unlock()
+		if len(i.commandLog) < memoryCommandLogSize {
+			i.commandLog = append(i.commandLog, record)
+			return
+		}
+		i.commandLog[i.commandLogNext] = record
+		i.commandLogNext = (i.commandLogNext + 1) % memoryCommandLogSize
+		return
+	}
+
+	privileged := 0
+	if record.Privileged {
+		privileged = 1
+	}
+	if _, err := i.db.Exec(`INSERT INTO COMMAND_LOGS (COMMAND, BOT_ID, PROTOCOL, CHAT_ID, USER_ID, PRIVILEGED, TIME, DURATION, ERROR) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.Command, record.BotID, record.Protocol, record.ChatID, record.UserID, privileged, record.Time.UnixMilli(), int64(record.Duration), record.Error); err != nil {
+		i.logger.Error("record command %s failed: %v", record.Command, err)
+	}
+}
+
+// ensureCommandLog 在第一次使用时创建 COMMAND_LOGS 表，未连接数据库时只使用内存
+func (i *Instant) ensureCommandLog() error {
+	i.commandLogMu.Lock()
+	defer i.commandLogMu.Unlock()
+
+	if i.commandLogReady || !i.DatabaseExist() {
+		return nil
+	}
+	if err := ensureCommandLogTable(i.db); err != nil {
+		return fmt.Errorf("failed to create command log table: %v", err)
+	}
+	i.commandLogReady = true
+	return nil
+}
+
+func ensureCommandLogTable(db *sql.DB) error {
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS COMMAND_LOGS (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    COMMAND TEXT NOT NULL,
+    BOT_ID TEXT NOT NULL,
+    PROTOCOL TEXT NOT NULL,
+    CHAT_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    PRIVILEGED INTEGER NOT NULL,
+    TIME NUMERIC NOT NULL,
+    DURATION INTEGER NOT NULL,
+    ERROR TEXT NOT NULL
+);`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS COMMAND_LOGS_TIME ON COMMAND_LOGS (TIME);`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS COMMAND_LOGS_USER ON COMMAND_LOGS (USER_ID, TIME);`)
+	return err
+}
+
+func (i *Instant) initStatsCmd() {
+	_, _ = i.Command("stats").
+		Description("查看使用统计").
+		Permission(entity.Admin).
+		SubCommand("commands").
+		Description("查看命令的使用次数、人数和耗时").
+		Option("since", "s", command.Duration, false, "7d", "统计的时间范围，如 1h、7d").
+		Action(func(ctx *command.Context) error {
+			since, _ := ctx.Duration("since")
+			stats, err := i.CommandStats(time.Now().Add(-since))
+			if err != nil {
+				return err
+			}
+			if len(stats) == 0 {
+				_, _ = ctx.ReplyText(fmt.Sprintf("最近 %s 没有命令调用记录", ctx.Options["since"]))
+				return nil
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "最近 %s 的命令使用情况：", ctx.Options["since"])
+			for _, stat := range stats {
+				fmt.Fprintf(&b, "\n%s  %d 次  %d 人  失败 %d 次  平均 %s",
+					stat.Command, stat.Count, stat.Users, stat.Failures, stat.AvgDuration.Round(time.Millisecond))
+			}
+			_, _ = ctx.ReplyText(b.String())
+			return nil
+		}).
+		Build()
+
+	_, _ = i.Command("audit").
+		Description("查看特权命令的调用记录").
+		Permission(entity.Admin).
+		Option("user", "u", command.String, false, "", "只查看该用户的记录").
+		Option("limit", "n", command.IntRange(1, 100), false, "20", "记录条数").
+		Action(func(ctx *command.Context) error {
+			limit, _ := ctx.Int("limit")
+			records, err := i.CommandRecords(CommandRecordFilter{
+				UserID:     ctx.Options["user"],
+				Privileged: true,
+				Limit:      limit,
+			})
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				_, _ = ctx.ReplyText("没有特权命令调用记录")
+				return nil
+			}
+			var b strings.Builder
+			b.WriteString("特权命令调用记录：")
+			for _, record := range records {
+				result := "成功"
+				if record.Error != "" {
+					result = "失败：" + record.Error
+				}
+				fmt.Fprintf(&b, "\n%s  %s  %s  %s  %s",
+					record.Time.Format(time.DateTime), record.UserID, record.Command, record.ChatID, result)
+			}
+			_, _ = ctx.ReplyText(b.String())
+			return nil
+		}).
+		Build()
+}
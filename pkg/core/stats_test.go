@@ -0,0 +1,217 @@
+package GoroBot_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func recordedCommands(records []GoroBot.CommandRecord) string {
+	list := make([]string, len(records))
+	for n, record := range records {
+		list[n] = record.Command
+	}
+	return fmt.Sprint(list)
+}
+
+// waitRecords 等待至少 n 条调用记录，记录在命令回复之后才写入
+func waitRecords(t *testing.T, grb *GoroBot.Instant, n int) []GoroBot.CommandRecord {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		records, err := grb.CommandRecords(GoroBot.CommandRecordFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) >= n {
+			return records
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d command records, want %d", len(records), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCommandRecordFilter(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	records := []command.Record{
+		{Commands: []string{"plugin", "load"}, SenderID: "alice", Privileged: true, Time: now.Add(-3 * time.Hour)},
+		{Commands: []string{"plugins"}, SenderID: "bob", Time: now.Add(-2 * time.Hour)},
+		{Commands: []string{"plugin"}, SenderID: "bob", Privileged: true, Time: now.Add(-time.Hour), Err: errors.New("failed")},
+		{Commands: []string{"help"}, SenderID: "alice", Time: now},
+	}
+
+	tests := []struct {
+		name   string
+		filter GoroBot.CommandRecordFilter
+		want   string
+	}{
+		{"all", GoroBot.CommandRecordFilter{}, "[help plugin plugins plugin load]"},
+		{"command prefix", GoroBot.CommandRecordFilter{Command: "plugin"}, "[plugin plugin load]"},
+		{"full path", GoroBot.CommandRecordFilter{Command: "plugin load"}, "[plugin load]"},
+		{"partial word", GoroBot.CommandRecordFilter{Command: "plug"}, "[]"},
+		{"user", GoroBot.CommandRecordFilter{UserID: "alice"}, "[help plugin load]"},
+		{"since", GoroBot.CommandRecordFilter{Since: now.Add(-90 * time.Minute)}, "[help plugin]"},
+		{"privileged", GoroBot.CommandRecordFilter{Privileged: true}, "[plugin plugin load]"},
+		{"limit", GoroBot.CommandRecordFilter{Limit: 2}, "[help plugin]"},
+		{"combined", GoroBot.CommandRecordFilter{Command: "plugin", UserID: "bob", Privileged: true}, "[plugin]"},
+	}
+
+	for _, backend := range []string{"memory", "database"} {
+		grb, _ := testkit.NewInstant(t)
+		if backend == "database" {
+			if err := grb.OpenDatabase("sqlite3", filepath.Join(t.TempDir(), "bot.db")); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = grb.Database().Close() })
+		}
+		for _, record := range records {
+			grb.RecordCommand(record)
+		}
+
+		for _, tt := range tests {
+			got, err := grb.CommandRecords(tt.filter)
+			if err != nil {
+				t.Fatalf("%s %s: %v", backend, tt.name, err)
+			}
+			if list := recordedCommands(got); list != tt.want {
+				t.Errorf("%s %s: got %s, want %s", backend, tt.name, list, tt.want)
+			}
+		}
+
+		got, _ := grb.CommandRecords(GoroBot.CommandRecordFilter{Command: "plugin", Limit: 1})
+		if len(got) != 1 || got[0].Error != "failed" || !got[0].Time.Equal(now.Add(-time.Hour)) {
+			t.Fatalf("%s: record not restored: %+v", backend, got)
+		}
+	}
+}
+
+func TestCommandLogRing(t *testing.T) {
+	grb, _ := testkit.NewInstant(t)
+	now := time.Now()
+	total := GoroBot.MemoryCommandLogSize + 5
+	for n := range total {
+		grb.RecordCommand(command.Record{Commands: []string{fmt.Sprint(n)}, Time: now.Add(time.Duration(n) * time.Second)})
+	}
+
+	records, err := grb.CommandRecords(GoroBot.CommandRecordFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != GoroBot.MemoryCommandLogSize {
+		t.Fatalf("kept %d records, want %d", len(records), GoroBot.MemoryCommandLogSize)
+	}
+	// 最旧的记录被覆盖，结果仍然从新到旧
+	if first, last := records[0].Command, records[len(records)-1].Command; first != fmt.Sprint(total-1) || last != "5" {
+		t.Fatalf("got records %s ... %s, want %d ... 5", first, last, total-1)
+	}
+	if got, _ := grb.CommandRecords(GoroBot.CommandRecordFilter{Command: "4"}); len(got) != 0 {
+		t.Fatalf("overwritten record still returned: %+v", got)
+	}
+}
+
+func TestCommandStats(t *testing.T) {
+	grb, _ := testkit.NewInstant(t)
+	now := time.Now()
+	for _, record := range []command.Record{
+		{Commands: []string{"roll"}, SenderID: "alice", Time: now.Add(-2 * time.Hour), Duration: 10 * time.Millisecond},
+		{Commands: []string{"roll"}, SenderID: "bob", Time: now.Add(-time.Hour), Duration: 30 * time.Millisecond, Err: errors.New("failed")},
+		{Commands: []string{"roll"}, SenderID: "alice", Time: now, Duration: 20 * time.Millisecond},
+		{Commands: []string{"help"}, SenderID: "bob", Time: now},
+		{Commands: []string{"echo"}, SenderID: "bob", Time: now},
+		{Commands: []string{"old"}, SenderID: "bob", Time: now.AddDate(0, 0, -30)},
+	} {
+		grb.RecordCommand(record)
+	}
+
+	stats, err := grb.CommandStats(now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("got %+v, want 3 commands", stats)
+	}
+	// 调用次数相同时按命令名排序
+	if stats[0].Command != "roll" || stats[1].Command != "echo" || stats[2].Command != "help" {
+		t.Fatalf("unexpected order %+v", stats)
+	}
+	roll := stats[0]
+	if roll.Count != 3 || roll.Users != 2 || roll.Failures != 1 || roll.AvgDuration != 20*time.Millisecond || !roll.LastUsed.Equal(now) {
+		t.Fatalf("unexpected roll stat %+v", roll)
+	}
+}
+
+func TestCommandRecorded(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+	for _, cmd := range []*command.FormatBuilder{
+		grb.Command("echo").
+			Argument("text", command.String, true, "").
+			Action(func(ctx *command.Context) error {
+				_, _ = ctx.ReplyText(ctx.KvArgs["text"])
+				return nil
+			}),
+		grb.Command("admin").
+			Permission(entity.Admin).
+			Action(func(ctx *command.Context) error {
+				_, _ = ctx.ReplyText("ok")
+				return nil
+			}),
+	} {
+		if _, err := cmd.Build(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	group := testkit.GroupID("g")
+	alice := testkit.UserID("alice")
+	calls := 0
+	run := func(text string, authority entity.Authority) string {
+		t.Helper()
+		msg := bot.NewTextMessage("/"+text).From(alice, "alice").InGroup(group).WithAuthority(authority)
+		reply := bot.RunCommand(t, msg, text)
+		calls++
+		waitRecords(t, grb, calls)
+		return reply
+	}
+
+	run("echo hi", entity.Member)
+	run("echo", entity.Member)
+	if got := run("admin", entity.Member); strings.Contains(got, "ok") {
+		t.Fatalf("member ran the admin command: %q", got)
+	}
+	run("admin", entity.Admin)
+
+	records := waitRecords(t, grb, calls)
+	if list := recordedCommands(records); list != "[admin admin echo echo]" {
+		t.Fatalf("got %s", list)
+	}
+	// 记录顺序从新到旧：管理员调用成功、成员权限不足、缺少参数、成功
+	success, denied, parseFailed, echoed := records[0], records[1], records[2], records[3]
+	if success.Error != "" || !success.Privileged || success.UserID != alice || success.ChatID != group || success.BotID != bot.ID() {
+		t.Fatalf("unexpected admin record %+v", success)
+	}
+	if denied.Error == "" || !denied.Privileged {
+		t.Fatalf("permission failure not recorded: %+v", denied)
+	}
+	if parseFailed.Error == "" || parseFailed.Privileged {
+		t.Fatalf("parse failure not recorded: %+v", parseFailed)
+	}
+	if echoed.Error != "" {
+		t.Fatalf("unexpected echo record %+v", echoed)
+	}
+
+	audit, err := grb.CommandRecords(GoroBot.CommandRecordFilter{Privileged: true})
+	if err != nil || len(audit) != 2 {
+		t.Fatalf("privileged records: %d, %v", len(audit), err)
+	}
+}
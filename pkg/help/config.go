@@ -0,0 +1,56 @@
+package help
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
+)
+
+const DefaultConfigPath = "conf/help/"
+
+type Config struct {
+	PageSize       int      `json:"page_size"`       // 命令列表每页展示的命令数
+	FoldLines      int      `json:"fold_lines"`      // 帮助文本超过该行数时在 ImageProtocols 中以图片发送
+	ImageProtocols []string `json:"image_protocols"` // 会折叠长文本的协议
+	FontPath       string   `json:"font_path"`       // 渲染图片使用的字体（ttf/otf/ttc），为空时始终发送文本
+	FontSize       float64  `json:"font_size"`       // 渲染图片的字号
+}
+
+var defaultConfig = Config{
+	PageSize:       10,
+	FoldLines:      15,
+	ImageProtocols: []string{"lagrange", "onebot", "qbot"},
+	FontPath:       "",
+	FontSize:       16,
+}
+
+func (s *Service) initConfig() error {
+	c := s.conic
+	configPath := path.Join(s.configPath, "config.json")
+	c.SetConfigFile(configPath)
+	c.WatchConfig()
+	c.BindRef("", &s.config)
+	c.SetLogger(s.logger.Debug)
+
+	if !util.FileExists(configPath) {
+		if err := util.MkdirIfNotExists(s.configPath); err != nil {
+			return fmt.Errorf("failed to create config directory: %v", err)
+		}
+
+		s.config = defaultConfig
+
+		if err := c.WriteConfig(); err != nil {
+			return fmt.Errorf("failed to write default config: %v", err)
+		}
+
+		s.logger.Info("帮助配置文件已生成于 %s", configPath)
+		return nil
+	}
+
+	if err := c.ReadConfig(); err != nil {
+		return fmt.Errorf("failed to read help config: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package help
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+const imagePadding = 16
+
+// render 将文本逐行绘制为 PNG 图片
+func (s *Service) render(text string) ([]byte, error) {
+	f, err := s.loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	size := s.config.FontSize
+	if size <= 0 {
+		size = defaultConfig.FontSize
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	lines := strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+	metrics := face.Metrics()
+	lineHeight := (metrics.Height * 6 / 5).Ceil()
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line).Ceil())
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width+imagePadding*2, lineHeight*len(lines)+imagePadding*2))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+	}
+	for i, line := range lines {
+		d.Dot = fixed.P(imagePadding, imagePadding+i*lineHeight+metrics.Ascent.Ceil())
+		d.DrawString(line)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// loadFont 加载配置中的字体，配置未变化时复用已解析的字体
+func (s *Service) loadFont() (*sfnt.Font, error) {
+	s.fontMu.Lock()
+	defer s.fontMu.Unlock()
+
+	if s.font != nil && s.fontPath == s.config.FontPath {
+		return s.font, nil
+	}
+
+	data, err := os.ReadFile(s.config.FontPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font %s: %v", s.config.FontPath, err)
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		// 字体集合（ttc/otc）取第一个字体
+		collection, cErr := opentype.ParseCollection(data)
+		if cErr != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %v", s.config.FontPath, err)
+		}
+		if f, err = collection.Font(0); err != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %v", s.config.FontPath, err)
+		}
+	}
+
+	s.font = f
+	s.fontPath = s.config.FontPath
+	return f, nil
+}
@@ -0,0 +1,145 @@
+package help
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/conic"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Service 根据已注册命令的 Schema 自动生成 help 命令
+type Service struct {
+	grb        *GoroBot.Instant
+	logger     logger.Inst
+	conic      *conic.Conic
+	config     Config
+	configPath string
+
+	fontMu   sync.Mutex
+	fontPath string
+	font     *sfnt.Font
+
+	releaseFunc func()
+}
+
+func Create() *Service {
+	return &Service{
+		configPath: DefaultConfigPath,
+		conic:      conic.New(),
+	}
+}
+
+func (s *Service) Name() string {
+	return "Help"
+}
+
+func (s *Service) Init(grb *GoroBot.Instant) error {
+	s.grb = grb
+	s.logger = grb.GetLogger()
+
+	if err := s.initConfig(); err != nil {
+		return err
+	}
+
+	del, err := grb.Command("help").
+		Description("查看命令帮助").
+		Argument("command", command.String, false, "命令路径，如 history search").
+		Option("page", "p", command.Number, false, "1", "命令列表的页码").
+		Action(s.helpAction).
+		Build()
+	if err != nil {
+		return err
+	}
+	s.releaseFunc = del
+
+	return nil
+}
+
+func (s *Service) Release(grb *GoroBot.Instant) error {
+	if s.releaseFunc != nil {
+		s.releaseFunc()
+		s.releaseFunc = nil
+	}
+	return nil
+}
+
+func (s *Service) helpAction(ctx *command.Context) error {
+	var text string
+	if len(ctx.Arguments) == 0 {
+		page, _ := strconv.Atoi(ctx.Options["page"])
+		text = s.list(ctx, page)
+	} else {
+		schema, matched, ok := command.FindSchema(s.grb.AvailableCommandSchemas(ctx), ctx.Arguments)
+		if !ok {
+			return fmt.Errorf("未找到命令 %s", ctx.Arguments[0])
+		}
+		if len(matched) < len(ctx.Arguments) {
+			return fmt.Errorf("命令 %s 没有子命令 %s", strings.Join(matched, " "), ctx.Arguments[len(matched)])
+		}
+		text = schema.Help(matched[:len(matched)-1]...)
+	}
+
+	if s.shouldFold(ctx.BotContext().Protocol(), text) {
+		data, err := s.render(text)
+		if err == nil {
+			_, err = ctx.NewMessageBuilder().ImageFromData(data).ReplyTo(ctx)
+			if err == nil {
+				return nil
+			}
+		}
+		s.logger.Warning("以图片发送帮助失败，改为发送文本：%v", err)
+	}
+
+	_, _ = ctx.ReplyText(text)
+	return nil
+}
+
+// list 返回当前会话中可用的顶级命令列表中的一页，按名称排序
+func (s *Service) list(ctx *command.Context, page int) string {
+	schemas := s.grb.AvailableCommandSchemas(ctx)
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+
+	size := s.config.PageSize
+	if size <= 0 {
+		size = defaultConfig.PageSize
+	}
+	pages := max((len(schemas)+size-1)/size, 1)
+	page = min(max(page, 1), pages)
+
+	var b strings.Builder
+	b.WriteString("命令列表：")
+	for _, schema := range schemas[min((page-1)*size, len(schemas)):min(page*size, len(schemas))] {
+		line := "\n  " + schema.Usage()
+		if schema.Description != "" {
+			line += "  " + schema.Description
+		}
+		b.WriteString(line)
+	}
+	if pages > 1 {
+		fmt.Fprintf(&b, "\n第 %d/%d 页，使用 help -p <页码> 翻页", page, pages)
+	}
+	b.WriteString("\n使用 help <命令> 查看详细用法")
+	return b.String()
+}
+
+// shouldFold 判断文本在该协议下是否会被折叠，需要改为图片发送
+func (s *Service) shouldFold(protocol string, text string) bool {
+	if s.config.FontPath == "" || !slices.Contains(s.config.ImageProtocols, protocol) {
+		return false
+	}
+	lines := s.config.FoldLines
+	if lines <= 0 {
+		lines = defaultConfig.FoldLines
+	}
+	return strings.Count(text, "\n")+1 > lines
+}
@@ -1,7 +1,9 @@
 package lagrange
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
@@ -13,6 +15,10 @@ type Context struct {
 }
 
 func (ctx *Context) ID() string {
+	// 扫码登录时配置中的 uin 可能为空，以实际登录的账号为准
+	if c := ctx.service.qqClient; c != nil && c.Uin != 0 {
+		return GenUserID(c.Uin)
+	}
 	return GenUserID(ctx.service.config.Account.Uin)
 }
 
@@ -28,6 +34,10 @@ func (ctx *Context) DownloadResourceFromRefLink(refLink string) (string, error)
 	return ctx.service.DownloadResourceFromRefLink(refLink)
 }
 
+func (ctx *Context) OpenResourceFromRefLink(c context.Context, refLink string) (io.ReadCloser, int64, error) {
+	return ctx.service.OpenResourceFromRefLink(c, refLink)
+}
+
 func (ctx *Context) Status() botc.LoginStatus {
 	return ctx.service.status
 }
@@ -46,11 +56,7 @@ func (ctx *Context) SendDirectMessage(target entity.User, elements []*botc.Messa
 
 	elems := TranslateMessageElement(ctx.service, elements)
 
-	if msg, err := ctx.service.qqClient.SendPrivateMessage(uint32(uin), elems); err != nil {
-		return nil, err
-	} else {
-		return ParseMessageEvent(ctx.service, msg)
-	}
+	return ctx.service.sendPrivateMessage(uint32(uin), elems)
 }
 
 func (ctx *Context) SendGroupMessage(target entity.Group, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
@@ -61,11 +67,7 @@ func (ctx *Context) SendGroupMessage(target entity.Group, elements []*botc.Messa
 
 	elems := TranslateMessageElement(ctx.service, elements)
 
-	if msg, err := ctx.service.qqClient.SendGroupMessage(uin, elems); err != nil {
-		return nil, err
-	} else {
-		return ParseMessageEvent(ctx.service, msg)
-	}
+	return ctx.service.sendGroupMessage(uin, elems)
 }
 
 func (ctx *Context) Contacts() []entity.User {
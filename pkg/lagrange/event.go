@@ -1,9 +1,7 @@
 package lagrange
 
 import (
-	"strings"
-
-	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/LagrangeDev/LagrangeGo/client"
 	LgrMessage "github.com/LagrangeDev/LagrangeGo/message"
 )
@@ -13,6 +11,7 @@ func (s *Service) eventSubscribe() error {
 
 	qqClient.DisconnectedEvent.Subscribe(func(client *client.QQClient, event *client.DisconnectedEvent) {
 		s.logger.Error("连接已断开：%v", event.Message)
+		s.setStatus(botc.Offline, event.Message)
 	})
 
 	qqClient.GroupMessageEvent.Subscribe(func(client *client.QQClient, event *LgrMessage.GroupMessage) {
@@ -23,19 +22,16 @@ func (s *Service) eventSubscribe() error {
 		go s.messageEventHandler(event)
 	})
 
+	s.noticeSubscribe()
+
 	return nil
 }
 
 func (s *Service) messageEventHandler(event any) {
+	s.rememberMessage(event)
 	msg := NewMessageContext(event, s)
-	if strings.HasPrefix(msg.String(), s.config.CommandPrefix) {
-		text := msg.String()[len(s.config.CommandPrefix):]
-		s.grb.CommandEmit(
-			command.NewCommandContext(msg, text),
-		)
-	} else {
-		_ = s.grb.MessageEmit(
-			msg,
-		)
+	if groupMsg, ok := event.(*LgrMessage.GroupMessage); ok {
+		s.emitFileUpload(groupMsg, msg)
 	}
+	_ = s.grb.Dispatch(msg, msg.String(), s.config.CommandPrefix)
 }
@@ -1,7 +1,7 @@
 package lagrange
 
 import (
-	"fmt"
+	"errors"
 	"github.com/LagrangeDev/LagrangeGo/client"
 	"github.com/LagrangeDev/LagrangeGo/client/auth"
 	"net/url"
@@ -20,13 +20,13 @@ func (s *Service) login() error {
 	qqClient.UseVersion(appInfo)
 	qqClient.AddSignServer(s.config.SignServerUrl)
 
-	deviceInfo, err := auth.LoadOrSaveDevice(path.Join(DefaultConfigPath, "device.json"))
+	deviceInfo, err := auth.LoadOrSaveDevice(path.Join(s.ConfigPath, "device.json"))
 	if err != nil {
 		return err
 	}
 	qqClient.UseDevice(deviceInfo)
 
-	data, err := os.ReadFile(path.Join(DefaultConfigPath, s.config.Account.SigPath))
+	data, err := os.ReadFile(path.Join(s.ConfigPath, s.config.Account.SigPath))
 	if err == nil {
 		sig, err := auth.UnmarshalSigInfo(data, true)
 		if err != nil {
@@ -66,7 +66,7 @@ func (s *Service) login() error {
 				continue
 			}
 			if !retCode.Success() {
-				return fmt.Errorf(retCode.Name())
+				return errors.New(retCode.Name())
 			}
 			break
 		}
@@ -67,7 +67,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 					"ext": {strings.TrimPrefix(path.Ext(resourceURL), ".")},
 				}.Encode()
 			}
-			resourceID := service.grb.SaveResourceLink(service.Protocol(), refLink)
+			resourceID := service.grb.SaveResourceLink(service.getContext().ID(), refLink)
 
 			b.Append(
 				botc.VoiceElement,
@@ -105,7 +105,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 					"ext": {strings.TrimPrefix(path.Ext(resourceURL), ".")},
 				}.Encode()
 			}
-			resourceID := service.grb.SaveResourceLink(service.Protocol(), refLink)
+			resourceID := service.grb.SaveResourceLink(service.getContext().ID(), refLink)
 
 			b.Append(
 				botc.ImageElement,
@@ -142,7 +142,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 					service.logger.Error("get group file url err: %v", err)
 				}
 			}
-			resourceID := service.grb.SaveResourceLink(service.Protocol(), refLink)
+			resourceID := service.grb.SaveResourceLink(service.getContext().ID(), refLink)
 			b.Append(botc.FileElement, "[文件]", resourceID)
 		case *LgrMessage.ShortVideoElement:
 			resourceID := ""
@@ -153,7 +153,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 						"url": {resourceURL},
 						"ext": {strings.TrimPrefix(path.Ext(resourceURL), ".")},
 					}.Encode()
-					resourceID = service.grb.SaveResourceLink(service.Protocol(), refLink)
+					resourceID = service.grb.SaveResourceLink(service.getContext().ID(), refLink)
 				} else {
 					service.logger.Error("get private video url err: %v", err)
 				}
@@ -163,7 +163,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 						"url": {resourceURL},
 						"ext": {strings.TrimPrefix(path.Ext(resourceURL), ".")},
 					}.Encode()
-					resourceID = service.grb.SaveResourceLink(service.Protocol(), refLink)
+					resourceID = service.grb.SaveResourceLink(service.getContext().ID(), refLink)
 				} else {
 					service.logger.Error("get group video url err: %v", err)
 				}
@@ -180,6 +180,7 @@ func ParseElementsFromEvent(service *Service, msgEvent any) []*botc.MessageEleme
 }
 
 func ParseMessageEvent(service *Service, msgEvent any) (*botc.BaseMessage, error) {
+	service.rememberMessage(msgEvent)
 	elems := ParseElementsFromEvent(service, msgEvent)
 	content := botc.ElemsToString(elems)
 	switch msgEvent := msgEvent.(type) {
@@ -205,6 +206,41 @@ func ParseMessageEvent(service *Service, msgEvent any) (*botc.BaseMessage, error
 	return nil, fmt.Errorf("unhandled message type: %T", msgEvent)
 }
 
+// sendPrivateMessage 经过发送限流后发送私聊消息
+func (s *Service) sendPrivateMessage(uin uint32, elements []LgrMessage.IMessageElement) (*botc.BaseMessage, error) {
+	s.grb.WaitSend(s.getContext().ID())
+	msg, err := s.qqClient.SendPrivateMessage(uin, elements)
+	if err != nil {
+		return nil, err
+	}
+	return s.sentMessage(msg)
+}
+
+// sendGroupMessage 经过发送限流后发送群消息
+func (s *Service) sendGroupMessage(groupUin uint32, elements []LgrMessage.IMessageElement) (*botc.BaseMessage, error) {
+	s.grb.WaitSend(s.getContext().ID())
+	msg, err := s.qqClient.SendGroupMessage(groupUin, elements)
+	if err != nil {
+		return nil, err
+	}
+	return s.sentMessage(msg)
+}
+
+// sentMessage 转换机器人发出的消息，并保存到 GoroBot 消息存储
+func (s *Service) sentMessage(msgEvent any) (*botc.BaseMessage, error) {
+	msg, err := ParseMessageEvent(s, msgEvent)
+	if err != nil {
+		return nil, err
+	}
+	switch msgEvent := msgEvent.(type) {
+	case *LgrMessage.PrivateMessage:
+		s.grb.StoreMessage(s.getContext().ID(), GenUserID(msgEvent.Target), msg)
+	case *LgrMessage.GroupMessage:
+		s.grb.StoreMessage(s.getContext().ID(), GenGroupID(msgEvent.GroupUin), msg)
+	}
+	return msg, nil
+}
+
 func TranslateMessageElement(service *Service, elements []*botc.MessageElement) []LgrMessage.IMessageElement {
 	b := MessageBuilder{}
 	for _, elem := range elements {
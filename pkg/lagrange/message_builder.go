@@ -97,6 +97,23 @@ func (b *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
 	return b
 }
 
+// ImageFromReader 在 r 可以 Seek 时以流的方式上传，否则读入内存
+func (b *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
+	if b.err != nil {
+		return b
+	}
+	if rs, ok := r.(io.ReadSeeker); ok {
+		b.elements = append(b.elements, LgrMessage.NewStreamImage(rs))
+		return b
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.ImageFromData(data)
+}
+
 func (b *MessageBuilder) ImageFromUrl(url string) botc.MessageBuilder {
 	if b.err != nil {
 		return b
@@ -234,21 +251,11 @@ func (b *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
 		return nil, fmt.Errorf("cannot convert id %s to uin", info.Args[1])
 	}
 
-	client := b.service.qqClient
-
 	switch idType {
 	case "user":
-		if event, err := client.SendPrivateMessage(uint32(uin), b.elements); err != nil {
-			return nil, err
-		} else {
-			return ParseMessageEvent(b.service, event)
-		}
+		return b.service.sendPrivateMessage(uint32(uin), b.elements)
 	case "group":
-		if event, err := client.SendGroupMessage(uint32(uin), b.elements); err != nil {
-			return nil, err
-		} else {
-			return ParseMessageEvent(b.service, event)
-		}
+		return b.service.sendGroupMessage(uint32(uin), b.elements)
 	}
 	return nil, fmt.Errorf("unhandled id type %s", idType)
 }
@@ -113,17 +113,9 @@ func (m *MessageContext) reply(elements []LgrMessage.IMessageElement) (*botc.Bas
 	}
 	switch m.messageType {
 	case botc.DirectMessage:
-		if msg, err := m.service.qqClient.SendPrivateMessage(m.privateMsg.Sender.Uin, elements); err != nil {
-			return nil, err
-		} else {
-			return ParseMessageEvent(m.service, msg)
-		}
+		return m.service.sendPrivateMessage(m.privateMsg.Sender.Uin, elements)
 	case botc.GroupMessage:
-		if msg, err := m.service.qqClient.SendGroupMessage(m.groupMsg.GroupUin, elements); err != nil {
-			return nil, err
-		} else {
-			return ParseMessageEvent(m.service, msg)
-		}
+		return m.service.sendGroupMessage(m.groupMsg.GroupUin, elements)
 	}
 	return nil, fmt.Errorf("unhandled message type: %v", m.messageType)
 }
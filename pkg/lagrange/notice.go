@@ -0,0 +1,260 @@
+package lagrange
+
+import (
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
+	"github.com/LagrangeDev/LagrangeGo/client"
+	lgrEntity "github.com/LagrangeDev/LagrangeGo/client/entity"
+	lgrEvent "github.com/LagrangeDev/LagrangeGo/client/event"
+	LgrMessage "github.com/LagrangeDev/LagrangeGo/message"
+)
+
+// noticeSubscribe 将 LagrangeGo 的通知与请求事件转换为 GoroBot 类型化事件
+func (s *Service) noticeSubscribe() {
+	qqClient := s.qqClient
+
+	qqClient.GroupMemberJoinEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupMemberIncrease) {
+		s.emitEvent(s.newEventContext(&event.MemberJoin{
+			Member:   s.userEntity(e.UserUin, e.GroupUin),
+			Operator: s.userEntity(e.InvitorUin, e.GroupUin),
+			Invited:  e.InvitorUin != 0,
+		}, e.UserUin, e.GroupUin))
+	})
+
+	qqClient.GroupJoinEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupMemberIncrease) {
+		s.emitEvent(s.newEventContext(&event.MemberJoin{
+			Member:   s.userEntity(client.Uin, e.GroupUin),
+			Operator: s.userEntity(e.InvitorUin, e.GroupUin),
+			Invited:  e.InvitorUin != 0,
+		}, client.Uin, e.GroupUin))
+	})
+
+	leave := func(client *client.QQClient, e *lgrEvent.GroupMemberDecrease) {
+		payload := &event.MemberLeave{
+			Member: s.userEntity(e.UserUin, e.GroupUin),
+			Kicked: e.IsKicked(),
+		}
+		if payload.Kicked {
+			payload.Operator = s.userEntity(e.OperatorUin, e.GroupUin)
+		}
+		s.emitEvent(s.newEventContext(payload, e.UserUin, e.GroupUin))
+	}
+	qqClient.GroupMemberLeaveEvent.Subscribe(leave)
+	qqClient.GroupLeaveEvent.Subscribe(leave)
+
+	qqClient.NewFriendEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.NewFriend) {
+		user := s.userEntityWithNick(e.FromUin, 0, e.FromNick)
+		s.emitEvent(s.newEventContext(&event.FriendAdd{User: user}, e.FromUin, 0))
+	})
+
+	qqClient.GroupRecallEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupRecall) {
+		s.emitEvent(s.newEventContext(&event.MessageRecall{
+			MessageID: GenMsgSeqID(uint32(e.Sequence)),
+			Author:    s.userEntity(e.UserUin, e.GroupUin),
+			Operator:  s.userEntity(e.OperatorUin, e.GroupUin),
+		}, e.OperatorUin, e.GroupUin))
+	})
+
+	qqClient.FriendRecallEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.FriendRecall) {
+		user := s.userEntity(e.FromUin, 0)
+		s.emitEvent(s.newEventContext(&event.MessageRecall{
+			MessageID: GenMsgSeqID(uint32(e.Sequence)),
+			Author:    user,
+			Operator:  user,
+		}, e.FromUin, 0))
+	})
+
+	qqClient.GroupMuteEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupMute) {
+		payload := &event.GroupMute{
+			Operator: s.userEntity(e.OperatorUin, e.GroupUin),
+			Duration: time.Duration(e.Duration) * time.Second,
+		}
+		if !e.MuteAll() {
+			payload.Target = s.userEntity(e.UserUin, e.GroupUin)
+		}
+		s.emitEvent(s.newEventContext(payload, e.OperatorUin, e.GroupUin))
+	})
+
+	qqClient.GroupNotifyEvent.Subscribe(func(client *client.QQClient, e lgrEvent.INotifyEvent) {
+		if poke, ok := e.(*lgrEvent.GroupPokeEvent); ok {
+			s.emitEvent(s.newEventContext(&event.Poke{
+				Operator: s.userEntity(poke.UserUin, poke.GroupUin),
+				Target:   s.userEntity(poke.Receiver, poke.GroupUin),
+			}, poke.UserUin, poke.GroupUin))
+		}
+	})
+
+	qqClient.FriendNotifyEvent.Subscribe(func(client *client.QQClient, e lgrEvent.INotifyEvent) {
+		if poke, ok := e.(*lgrEvent.FriendPokeEvent); ok {
+			s.emitEvent(s.newEventContext(&event.Poke{
+				Operator: s.userEntity(poke.Sender, 0),
+				Target:   s.userEntity(poke.Receiver, 0),
+			}, poke.Sender, 0))
+		}
+	})
+
+	qqClient.NewFriendRequestEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.NewFriendRequest) {
+		user := s.userEntityWithNick(e.SourceUin, 0, e.SourceNick)
+		s.emitEvent(s.newEventContext(&event.FriendRequest{
+			Request: event.NewRequest(
+				func(remark string) error {
+					return client.SetFriendRequest(true, e.SourceUID)
+				},
+				func(reason string) error {
+					return client.SetFriendRequest(false, e.SourceUID)
+				},
+			),
+			User:    user,
+			Comment: e.Msg,
+		}, e.SourceUin, 0))
+	})
+
+	qqClient.GroupMemberJoinRequestEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupMemberJoinRequest) {
+		user := s.userEntityWithNick(e.UserUin, e.GroupUin, e.TargetNick)
+		typ := lgrEntity.UserJoinRequest
+		if e.InvitorUin != 0 {
+			typ = lgrEntity.UserInvited
+		}
+		s.emitEvent(s.newEventContext(&event.GroupRequest{
+			Request: s.groupRequest(client, e.RequestSeq, typ, e.GroupUin),
+			User:    user,
+			Comment: e.Answer,
+		}, e.UserUin, e.GroupUin))
+	})
+
+	qqClient.GroupInvitedEvent.Subscribe(func(client *client.QQClient, e *lgrEvent.GroupInvite) {
+		user := s.userEntityWithNick(e.InvitorUin, 0, e.InvitorNick)
+		ctx := s.newEventContext(&event.GroupRequest{
+			Request: s.groupRequest(client, e.RequestSeq, lgrEntity.GroupInvited, e.GroupUin),
+			User:    user,
+			Invite:  true,
+		}, e.InvitorUin, e.GroupUin)
+		if ctx.Group != nil && ctx.Group.Name == "" {
+			ctx.Group.Name = e.GroupName
+		}
+		s.emitEvent(ctx)
+	})
+}
+
+func (s *Service) groupRequest(client *client.QQClient, seq uint64, typ lgrEntity.EventType, groupUin uint32) *event.Request {
+	return event.NewRequest(
+		func(remark string) error {
+			return client.SetGroupRequest(false, lgrEntity.GroupRequestOperateAllow, seq, uint32(typ), groupUin, "")
+		},
+		func(reason string) error {
+			return client.SetGroupRequest(false, lgrEntity.GroupRequestOperateDeny, seq, uint32(typ), groupUin, reason)
+		},
+	)
+}
+
+// emitFileUpload 群消息中包含文件时触发 file_upload 事件
+func (s *Service) emitFileUpload(msg *LgrMessage.GroupMessage, msgCtx *MessageContext) {
+	var resources []string
+	for _, elem := range msgCtx.Message().Elements {
+		if elem.Type == botc.FileElement {
+			resources = append(resources, elem.Source)
+		}
+	}
+
+	idx := 0
+	for _, elem := range msg.Elements {
+		file, ok := elem.(*LgrMessage.FileElement)
+		if !ok {
+			continue
+		}
+		payload := &event.FileUpload{
+			Uploader: s.userEntity(msg.Sender.Uin, msg.GroupUin),
+			Name:     file.FileName,
+			Size:     int64(file.FileSize),
+		}
+		if idx < len(resources) {
+			payload.ResourceID = resources[idx]
+		}
+		idx++
+		s.emitEvent(s.newEventContext(payload, msg.Sender.Uin, msg.GroupUin))
+	}
+}
+
+// setStatus 更新登录状态，状态变化时触发 bot_online / bot_offline 事件
+func (s *Service) setStatus(status botc.LoginStatus, reason string) {
+	if s.status == status {
+		return
+	}
+	s.status = status
+
+	if status == botc.Online {
+		s.emitEvent(event.NewContext(s.getContext(), &event.BotOnline{}))
+	} else {
+		s.emitEvent(event.NewContext(s.getContext(), &event.BotOffline{Reason: reason}))
+	}
+}
+
+func (s *Service) emitEvent(ctx *event.Context) {
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("触发 %s 事件失败：%v", ctx.Content, err)
+	}
+}
+
+// userEntity 根据 uin 构造用户实体，优先从群成员缓存和好友缓存中获取昵称
+func (s *Service) userEntity(uin uint32, groupUin uint32) *entity.User {
+	if uin == 0 {
+		return nil
+	}
+	user := &entity.User{
+		Base: &entity.Base{
+			ID: GenUserID(uin),
+		},
+	}
+	if groupUin != 0 {
+		if member := s.qqClient.GetCachedMemberInfo(uin, groupUin); member != nil {
+			user.Name = member.Nickname
+			user.Nickname = member.DisplayName()
+			return user
+		}
+	}
+	if friend := s.qqClient.GetCachedFriendInfo(uin); friend != nil {
+		user.Name = friend.Nickname
+		user.Nickname = friend.Nickname
+	}
+	return user
+}
+
+// userEntityWithNick 与 userEntity 相同，缓存中没有名称时使用事件携带的昵称
+func (s *Service) userEntityWithNick(uin uint32, groupUin uint32, nick string) *entity.User {
+	user := s.userEntity(uin, groupUin)
+	if user != nil && user.Name == "" {
+		user.Name = nick
+		user.Nickname = nick
+	}
+	return user
+}
+
+func (s *Service) groupEntity(groupUin uint32) *entity.Group {
+	if groupUin == 0 {
+		return nil
+	}
+	group := &entity.Group{
+		Base: &entity.Base{
+			ID: GenGroupID(groupUin),
+		},
+	}
+	if info := s.qqClient.GetCachedGroupInfo(groupUin); info != nil {
+		group.Name = info.GroupName
+	}
+	return group
+}
+
+func (s *Service) newEventContext(payload event.Payload, senderUin uint32, groupUin uint32) *event.Context {
+	ctx := event.NewContext(s.getContext(), payload)
+	ctx.Group = s.groupEntity(groupUin)
+	if user := s.userEntity(senderUin, groupUin); user != nil {
+		ctx.Sender = &entity.Sender{User: user}
+		if ctx.Group != nil {
+			ctx.Sender.From = ctx.Group.Base
+		}
+	}
+	return ctx
+}
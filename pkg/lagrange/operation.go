@@ -0,0 +1,250 @@
+package lagrange
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	LgrMessage "github.com/LagrangeDev/LagrangeGo/message"
+)
+
+// 消息索引容量，超出后最早的消息将无法撤回或回应
+const messageIndexSize = 4096
+
+// msgRef 记录撤回消息所需的信息，GenMsgSeqID 生成的 ID 中只有序号
+type msgRef struct {
+	groupUin  uint32
+	peerUin   uint32
+	seq       uint32
+	random    uint32
+	clientSeq uint32
+	time      uint32
+}
+
+// rememberMessage 记录收到或发出的消息，供 Recall / React 查找
+func (s *Service) rememberMessage(msg any) {
+	switch msg := msg.(type) {
+	case *LgrMessage.GroupMessage:
+		s.messages.Add(GenMsgSeqID(msg.ID), msgRef{
+			groupUin: msg.GroupUin,
+			seq:      msg.ID,
+			time:     msg.Time,
+		})
+	case *LgrMessage.PrivateMessage:
+		peer := msg.Target
+		if msg.Sender != nil && msg.Sender.Uin != s.qqClient.Uin {
+			peer = msg.Sender.Uin
+		}
+		s.messages.Add(GenMsgSeqID(msg.ID), msgRef{
+			peerUin:   peer,
+			seq:       msg.ID,
+			random:    msg.InternalID,
+			clientSeq: msg.ClientSeq,
+			time:      msg.Time,
+		})
+	}
+}
+
+func (s *Service) lookupMessage(msgID string) (msgRef, error) {
+	if ref, ok := s.messages.Get(msgID); ok {
+		return ref, nil
+	}
+	return msgRef{}, fmt.Errorf("unknown message %s", msgID)
+}
+
+// --- MessageOperator 接口实现 ---
+
+func (ctx *Context) Recall(msgID string) error {
+	ref, err := ctx.service.lookupMessage(msgID)
+	if err != nil {
+		return err
+	}
+	if ref.groupUin != 0 {
+		return ctx.service.qqClient.RecallGroupMessage(ref.groupUin, ref.seq)
+	}
+	return ctx.service.qqClient.RecallFriendMessage(ref.peerUin, ref.seq, ref.random, ref.clientSeq, ref.time)
+}
+
+func (ctx *Context) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	return nil, botc.ErrUnsupported
+}
+
+func (ctx *Context) React(msgID string, emoji string) error {
+	ref, err := ctx.service.lookupMessage(msgID)
+	if err != nil {
+		return err
+	}
+	if ref.groupUin == 0 {
+		return botc.ErrUnsupported
+	}
+	return ctx.service.qqClient.SetGroupReaction(ref.groupUin, ref.seq, emojiCode(emoji), true)
+}
+
+// emojiCode 将 Unicode 表情转换为十进制码点，QQ 表情 ID 保持不变
+func emojiCode(emoji string) string {
+	if _, err := strconv.Atoi(emoji); err == nil {
+		return emoji
+	}
+	r, _ := utf8.DecodeRuneInString(emoji)
+	return strconv.Itoa(int(r))
+}
+
+// --- GroupManager 接口实现 ---
+
+func (ctx *Context) Kick(groupID, userID string, reject bool) error {
+	groupUin, uin, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	client := ctx.service.qqClient
+	if err := client.KickGroupMember(groupUin, uin, reject); err != nil {
+		return err
+	}
+	ctx.service.refreshMembers(groupUin)
+	return nil
+}
+
+func (ctx *Context) Mute(groupID, userID string, duration time.Duration) error {
+	groupUin, uin, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.service.qqClient.SetGroupMemberMute(groupUin, uin, uint32(duration/time.Second)); err != nil {
+		return err
+	}
+	ctx.service.refreshMember(groupUin, uin)
+	return nil
+}
+
+func (ctx *Context) MuteAll(groupID string, enable bool) error {
+	groupUin, ok := ParseUin(groupID)
+	if !ok {
+		return fmt.Errorf("invalid group ID %s", groupID)
+	}
+	return ctx.service.qqClient.SetGroupGlobalMute(groupUin, enable)
+}
+
+func (ctx *Context) SetAdmin(groupID, userID string, enable bool) error {
+	groupUin, uin, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.service.qqClient.SetGroupAdmin(groupUin, uin, enable); err != nil {
+		return err
+	}
+	// LagrangeGo 取消管理员时也会把缓存中的权限写为管理员，需要重新拉取
+	ctx.service.refreshMember(groupUin, uin)
+	return nil
+}
+
+func (ctx *Context) SetCard(groupID, userID, card string) error {
+	groupUin, uin, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.qqClient.SetGroupMemberName(groupUin, uin, card)
+}
+
+func (ctx *Context) SetTitle(groupID, userID, title string) error {
+	groupUin, uin, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.service.qqClient.SetGroupMemberSpecialTitle(groupUin, uin, title); err != nil {
+		return err
+	}
+	ctx.service.refreshMember(groupUin, uin)
+	return nil
+}
+
+func parseMember(groupID, userID string) (uint32, uint32, error) {
+	groupUin, ok := ParseUin(groupID)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid group ID %s", groupID)
+	}
+	uin, ok := ParseUin(userID)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid user ID %s", userID)
+	}
+	return groupUin, uin, nil
+}
+
+// refreshMember 操作成功后刷新群成员缓存，失败时只记录日志
+func (s *Service) refreshMember(groupUin, uin uint32) {
+	if err := s.qqClient.RefreshGroupMemberCache(groupUin, uin); err != nil {
+		s.logger.Warning("刷新群成员缓存失败：%v", err)
+	}
+}
+
+func (s *Service) refreshMembers(groupUin uint32) {
+	if err := s.qqClient.RefreshGroupMembersCache(groupUin); err != nil {
+		s.logger.Warning("刷新群成员缓存失败：%v", err)
+	}
+}
+
+// --- MessageHistory 接口实现 ---
+
+// 未指定 limit 时一次获取的历史消息数量
+const defaultHistoryLimit = 20
+
+// GetMessage 群消息通过 GetGroupMessages 获取，私聊消息和索引中找不到的消息从 GoroBot 消息存储中查找
+func (ctx *Context) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	if ref, err := ctx.service.lookupMessage(msgID); err == nil && ref.groupUin != 0 {
+		msgs, err := ctx.service.qqClient.GetGroupMessages(ref.groupUin, ref.seq, ref.seq)
+		if err == nil && len(msgs) > 0 {
+			return ParseMessageEvent(ctx.service, msgs[0])
+		}
+	}
+	return ctx.service.grb.StoredMessage(ctx.ID(), msgID)
+}
+
+func (ctx *Context) GetHistory(chatType botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	if chatType != botc.GroupMessage {
+		return ctx.service.grb.StoredHistory(ctx.ID(), chatID, before, limit)
+	}
+	groupUin, ok := ParseUin(chatID)
+	if !ok {
+		return nil, fmt.Errorf("invalid group ID %s", chatID)
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	var end uint32
+	if before != "" {
+		seq, ok := ParseUin(before)
+		if !ok {
+			return nil, fmt.Errorf("invalid message ID %s", before)
+		}
+		end = seq - 1
+	} else {
+		group, err := ctx.service.qqClient.FetchGroupInfo(groupUin, false)
+		if err != nil {
+			return nil, err
+		}
+		end = group.LastMsgSeq
+	}
+	if end == 0 {
+		return nil, nil
+	}
+	start := uint32(1)
+	if end > uint32(limit) {
+		start = end - uint32(limit) + 1
+	}
+
+	msgs, err := ctx.service.qqClient.GetGroupMessages(groupUin, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*botc.BaseMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		base, err := ParseMessageEvent(ctx.service, msg)
+		if err != nil {
+			continue
+		}
+		result = append(result, base)
+	}
+	return result, nil
+}
@@ -1,9 +1,9 @@
 package lagrange
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	urlpkg "net/url"
 	"os"
 	"path"
@@ -14,6 +14,7 @@ import (
 	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
 	"github.com/Jel1ySpot/conic"
 	"github.com/LagrangeDev/LagrangeGo/client"
 	"github.com/google/uuid"
@@ -30,6 +31,7 @@ type Service struct {
 	grb        *GoroBot.Instant
 	owner      uint32
 	status     botc.LoginStatus
+	messages   *util.LRU[string, msgRef]
 
 	conic  *conic.Conic
 	logger logger.Inst
@@ -39,12 +41,18 @@ func (s *Service) Name() string {
 	return "Lagrange-adapter"
 }
 
-func Create() *Service {
-	return &Service{
+// Create 创建 Lagrange 适配器，可选传入配置目录以便同时登录多个账号，默认为 DefaultConfigPath
+func Create(configPath ...string) *Service {
+	s := &Service{
 		conic:      conic.New(),
 		status:     botc.Offline,
 		ConfigPath: DefaultConfigPath,
+		messages:   util.NewLRU[string, msgRef](messageIndexSize),
 	}
+	if len(configPath) > 0 && configPath[0] != "" {
+		s.ConfigPath = configPath[0]
+	}
+	return s
 }
 
 func (s *Service) getContext() *Context {
@@ -73,10 +81,10 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 		return err
 	}
 
-	s.status = botc.Online
-
 	grb.AddContext(s.getContext())
 
+	s.setStatus(botc.Online, "")
+
 	return nil
 }
 
@@ -87,6 +95,9 @@ func (s *Service) Release(grb *GoroBot.Instant) error {
 		}
 	}
 
+	s.setStatus(botc.Offline, "adapter released")
+	grb.RemoveContext(s.getContext().ID())
+
 	return nil
 }
 
@@ -121,30 +132,29 @@ func (s *Service) DownloadResourceFromRefLink(refLink string) (string, error) {
 		target = filepath.Join("resources", uuid.NewString()+ext)
 	}
 
-	resp, err := http.Get(rawURL)
-	if err != nil {
-		return "", fmt.Errorf("request resource failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if _, err := util.Download(context.Background(), nil, rawURL, target, util.TransferOptions{}); err != nil {
+		return "", fmt.Errorf("download resource failed: %w", err)
 	}
+	return target, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// OpenResourceFromRefLink 打开资源内容的流，由调用方关闭
+func (s *Service) OpenResourceFromRefLink(ctx context.Context, refLink string) (io.ReadCloser, int64, error) {
+	values, err := urlpkg.ParseQuery(refLink)
 	if err != nil {
-		return "", fmt.Errorf("read resource failed: %w", err)
+		return nil, 0, fmt.Errorf("invalid ref link: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-		return "", fmt.Errorf("create cache dir failed: %w", err)
+	rawURL := values.Get("url")
+	if rawURL == "" {
+		return nil, 0, fmt.Errorf("ref link missing url")
 	}
 
-	if err := os.WriteFile(target, data, 0644); err != nil {
-		return "", fmt.Errorf("write resource failed: %w", err)
+	resp, err := util.OpenURL(ctx, nil, rawURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request resource failed: %w", err)
 	}
-
-	return target, nil
+	return resp.Body, resp.ContentLength, nil
 }
 
 func (s *Service) releaseQQClient() error {
@@ -212,6 +212,7 @@ func (s *Service) sendPrivateMessage(userID int64, message interface{}) (*SendMe
 		"message": message,
 	}
 
+	s.grb.WaitSend(s.getContext().ID())
 	resp, err := s.makeAPIRequest("send_private_msg", params)
 	if err != nil {
 		return nil, err
@@ -236,6 +237,7 @@ func (s *Service) sendGroupMessage(groupID int64, message interface{}) (*SendMes
 		"message":  message,
 	}
 
+	s.grb.WaitSend(s.getContext().ID())
 	resp, err := s.makeAPIRequest("send_group_msg", params)
 	if err != nil {
 		return nil, err
@@ -391,6 +393,175 @@ func (s *Service) getGroupMemberInfo(groupID, userID int64, noCache bool) (*Grou
 	return &member, nil
 }
 
+func (s *Service) setFriendAddRequest(flag string, approve bool, remark string) error {
+	params := map[string]interface{}{
+		"flag":    flag,
+		"approve": approve,
+		"remark":  remark,
+	}
+
+	_, err := s.makeAPIRequest("set_friend_add_request", params)
+	return err
+}
+
+func (s *Service) setGroupAddRequest(flag string, subType string, approve bool, reason string) error {
+	params := map[string]interface{}{
+		"flag":     flag,
+		"sub_type": subType,
+		"approve":  approve,
+		"reason":   reason,
+	}
+
+	_, err := s.makeAPIRequest("set_group_add_request", params)
+	return err
+}
+
+func (s *Service) deleteMessage(messageID int64) error {
+	params := map[string]interface{}{
+		"message_id": messageID,
+	}
+
+	_, err := s.makeAPIRequest("delete_msg", params)
+	return err
+}
+
+// setMessageEmojiLike is a go-cqhttp/NapCat extension for message reactions
+func (s *Service) setMessageEmojiLike(messageID int64, emojiID string) error {
+	params := map[string]interface{}{
+		"message_id": messageID,
+		"emoji_id":   emojiID,
+	}
+
+	_, err := s.makeAPIRequest("set_msg_emoji_like", params)
+	return err
+}
+
+func (s *Service) setGroupKick(groupID, userID int64, rejectAddRequest bool) error {
+	params := map[string]interface{}{
+		"group_id":           groupID,
+		"user_id":            userID,
+		"reject_add_request": rejectAddRequest,
+	}
+
+	_, err := s.makeAPIRequest("set_group_kick", params)
+	return err
+}
+
+func (s *Service) setGroupBan(groupID, userID int64, duration int64) error {
+	params := map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+		"duration": duration,
+	}
+
+	_, err := s.makeAPIRequest("set_group_ban", params)
+	return err
+}
+
+func (s *Service) setGroupWholeBan(groupID int64, enable bool) error {
+	params := map[string]interface{}{
+		"group_id": groupID,
+		"enable":   enable,
+	}
+
+	_, err := s.makeAPIRequest("set_group_whole_ban", params)
+	return err
+}
+
+func (s *Service) setGroupAdmin(groupID, userID int64, enable bool) error {
+	params := map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+		"enable":   enable,
+	}
+
+	_, err := s.makeAPIRequest("set_group_admin", params)
+	return err
+}
+
+func (s *Service) setGroupCard(groupID, userID int64, card string) error {
+	params := map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+		"card":     card,
+	}
+
+	_, err := s.makeAPIRequest("set_group_card", params)
+	return err
+}
+
+func (s *Service) setGroupSpecialTitle(groupID, userID int64, title string) error {
+	params := map[string]interface{}{
+		"group_id":      groupID,
+		"user_id":       userID,
+		"special_title": title,
+		"duration":      -1,
+	}
+
+	_, err := s.makeAPIRequest("set_group_special_title", params)
+	return err
+}
+
+func (s *Service) getMsg(messageID int64) (*MessageEvent, error) {
+	params := map[string]interface{}{
+		"message_id": messageID,
+	}
+
+	resp, err := s.makeAPIRequest("get_msg", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	var msg MessageEvent
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %v", err)
+	}
+	if msg.UserID == 0 {
+		msg.UserID = msg.Sender.UserID
+	}
+
+	return &msg, nil
+}
+
+// getMsgHistory fetches group or friend history through get_group_msg_history / get_friend_msg_history,
+// messageSeq 0 starts from the latest message
+func (s *Service) getMsgHistory(action string, params map[string]interface{}, messageSeq int64, count int) ([]MessageEvent, error) {
+	if messageSeq != 0 {
+		params["message_seq"] = messageSeq
+	}
+	params["count"] = count
+
+	resp, err := s.makeAPIRequest(action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message history: %v", err)
+	}
+
+	var history struct {
+		Messages []MessageEvent `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse message history: %v", err)
+	}
+
+	for idx := range history.Messages {
+		if history.Messages[idx].UserID == 0 {
+			history.Messages[idx].UserID = history.Messages[idx].Sender.UserID
+		}
+	}
+
+	return history.Messages, nil
+}
+
 func (s *Service) getStatus() (*Status, error) {
 	resp, err := s.makeAPIRequest("get_status", nil)
 	if err != nil {
@@ -1,10 +1,13 @@
 package onebot
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
@@ -30,6 +33,10 @@ func (ctx *Context) DownloadResourceFromRefLink(refLink string) (string, error)
 	return ctx.service.DownloadResourceFromRefLink(refLink)
 }
 
+func (ctx *Context) OpenResourceFromRefLink(c context.Context, refLink string) (io.ReadCloser, int64, error) {
+	return ctx.service.OpenResourceFromRefLink(c, refLink)
+}
+
 func (ctx *Context) Status() botc.LoginStatus {
 	return ctx.service.status
 }
@@ -54,7 +61,7 @@ func (ctx *Context) SendDirectMessage(target entity.User, elements []*botc.Messa
 		return nil, err
 	}
 
-	return &botc.BaseMessage{
+	msg := &botc.BaseMessage{
 		ID:          fmt.Sprintf("%d", response.MessageID),
 		MessageType: botc.DirectMessage,
 		Content:     extractTextContent(elements),
@@ -67,7 +74,10 @@ func (ctx *Context) SendDirectMessage(target entity.User, elements []*botc.Messa
 				},
 			},
 		},
-	}, nil
+		Time: time.Now(),
+	}
+	ctx.service.grb.StoreMessage(ctx.ID(), target.ID, msg)
+	return msg, nil
 }
 
 func (ctx *Context) SendGroupMessage(target entity.Group, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
@@ -83,7 +93,7 @@ func (ctx *Context) SendGroupMessage(target entity.Group, elements []*botc.Messa
 		return nil, err
 	}
 
-	return &botc.BaseMessage{
+	msg := &botc.BaseMessage{
 		ID:          fmt.Sprintf("%d", response.MessageID),
 		MessageType: botc.GroupMessage,
 		Content:     extractTextContent(elements),
@@ -97,7 +107,10 @@ func (ctx *Context) SendGroupMessage(target entity.Group, elements []*botc.Messa
 			},
 			From: target.Base,
 		},
-	}, nil
+		Time: time.Now(),
+	}
+	ctx.service.grb.StoreMessage(ctx.ID(), target.ID, msg)
+	return msg, nil
 }
 
 func (ctx *Context) Contacts() []entity.User {
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
-	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 	"github.com/Jel1ySpot/GoroBot/pkg/util"
 )
@@ -68,6 +67,7 @@ type NoticeEvent struct {
 		Name  string `json:"name"`
 		Size  int64  `json:"size"`
 		BusID int64  `json:"busid"`
+		URL   string `json:"url,omitempty"`
 	} `json:"file,omitempty"`
 }
 
@@ -434,23 +434,8 @@ func (s *Service) processMessageEvent(eventData []byte) error {
 
 	s.logger.Debug("Triggering message event for content: %s", message.Content)
 
-	// Check if message is a command based on configured prefix
-	if s.config.CommandPrefix != "" && strings.HasPrefix(message.Content, s.config.CommandPrefix) {
-		// Extract command text without prefix
-		commandText := strings.TrimSpace(message.Content[len(s.config.CommandPrefix):])
-		if commandText != "" {
-			s.logger.Debug("Command detected: %s", commandText)
-
-			// Emit command event
-			s.grb.CommandEmit(
-				command.NewCommandContext(messageCtx, commandText),
-			)
-			return nil
-		}
-	}
-
-	// Trigger regular message event
-	if err := s.grb.EventEmit("message", messageCtx); err != nil {
+	// Emit a command or message event depending on the prefix and channel policy
+	if err := s.grb.Dispatch(messageCtx, message.Content, s.config.CommandPrefix); err != nil {
 		s.logger.Error("Failed to emit message event: %v", err)
 		return fmt.Errorf("failed to emit message event: %v", err)
 	}
@@ -482,6 +467,7 @@ func (s *Service) processNoticeEvent(eventData []byte) error {
 		s.invalidateGroupCache()
 	}
 
+	s.emitNotice(&noticeEvent)
 	return nil
 }
 
@@ -492,7 +478,7 @@ func (s *Service) processRequestEvent(eventData []byte) error {
 	}
 
 	s.logger.Debug("Received request event: %s", requestEvent.RequestType)
-	// Additional request event processing can be added here
+	s.emitRequest(&requestEvent)
 	return nil
 }
 
@@ -504,8 +490,16 @@ func (s *Service) processMetaEvent(eventData []byte) error {
 
 	s.logger.Debug("Received meta event: %s", metaEvent.MetaEventType)
 
-	if metaEvent.MetaEventType == "heartbeat" {
+	switch metaEvent.MetaEventType {
+	case "heartbeat":
 		s.logger.Debug("Heartbeat received")
+	case "lifecycle":
+		switch metaEvent.SubType {
+		case "enable", "connect":
+			s.setStatus(botc.Online, "")
+		case "disable":
+			s.setStatus(botc.Offline, "OneBot implementation disabled")
+		}
 	}
 
 	return nil
@@ -38,7 +38,8 @@ func (mb *MessageBuilder) Quote(msg *botc.BaseMessage) botc.MessageBuilder {
 	}
 	mb.elements = append(mb.elements, &botc.MessageElement{
 		Type:    botc.QuoteElement,
-		Content: msg.ID,
+		Content: "[回复]",
+		Source:  msg.ID,
 	})
 	return mb
 }
@@ -95,6 +96,19 @@ func (mb *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
 	return mb
 }
 
+// ImageFromReader buffers the whole image in memory, since OneBot expects images inline as base64
+func (mb *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
+	if mb.err != nil {
+		return mb
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		mb.err = fmt.Errorf("failed to read image: %w", err)
+		return mb
+	}
+	return mb.ImageFromData(data)
+}
+
 func (mb *MessageBuilder) ReplyTo(ctx botc.MessageContext) (*botc.BaseMessage, error) {
 	if mb.err != nil {
 		return nil, mb.err
@@ -133,17 +147,28 @@ func (mb *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
 		id = strings.TrimPrefix(id, "onebot:")
 	}
 
-	// Try to parse as user ID first
-	if userID, err := strconv.ParseInt(id, 10, 64); err == nil {
-		target := entity.User{
+	targetID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target ID: %s", id)
+	}
+
+	// User and group IDs share the same format, so check the group cache first
+	_, _ = mb.service.getGroupList()
+	if _, isGroup := mb.service.getCachedGroupInfo(targetID); isGroup {
+		target := entity.Group{
 			Base: &entity.Base{
-				ID: genUserID(userID),
+				ID: genGroupID(targetID),
 			},
 		}
-		return mb.service.getContext().SendDirectMessage(target, mb.elements)
+		return mb.service.getContext().SendGroupMessage(target, mb.elements)
 	}
 
-	return nil, fmt.Errorf("invalid target ID: %s", id)
+	target := entity.User{
+		Base: &entity.Base{
+			ID: genUserID(targetID),
+		},
+	}
+	return mb.service.getContext().SendDirectMessage(target, mb.elements)
 }
 
 // Additional helper methods for OneBot-specific functionality
@@ -191,7 +216,8 @@ func (mb *MessageBuilder) Reply(messageID string) botc.MessageBuilder {
 	}
 	mb.elements = append(mb.elements, &botc.MessageElement{
 		Type:    botc.QuoteElement,
-		Content: messageID,
+		Content: "[回复]",
+		Source:  messageID,
 	})
 	return mb
 }
@@ -0,0 +1,203 @@
+package onebot
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"path"
+	"strings"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
+)
+
+// setStatus updates the login status and emits bot_online / bot_offline on transitions
+func (s *Service) setStatus(status botc.LoginStatus, reason string) {
+	if s.status == status {
+		return
+	}
+	s.status = status
+
+	if status == botc.Online {
+		s.emitEvent(event.NewContext(s.getContext(), &event.BotOnline{}))
+	} else {
+		s.emitEvent(event.NewContext(s.getContext(), &event.BotOffline{Reason: reason}))
+	}
+}
+
+func (s *Service) emitEvent(ctx *event.Context) {
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("Failed to emit %s event: %v", ctx.Content, err)
+	}
+}
+
+// userEntity builds a user entity, filling the nickname from the friend cache when possible
+func (s *Service) userEntity(userID int64) *entity.User {
+	if userID == 0 {
+		return nil
+	}
+	user := &entity.User{
+		Base: &entity.Base{
+			ID: genUserID(userID),
+		},
+	}
+	if friend, exists := s.getCachedFriendInfo(userID); exists {
+		user.Name = friend.Nickname
+		user.Nickname = friend.Nickname
+	}
+	return user
+}
+
+// groupEntity builds a group entity, filling the name from the group cache when possible
+func (s *Service) groupEntity(groupID int64) *entity.Group {
+	if groupID == 0 {
+		return nil
+	}
+	group := &entity.Group{
+		Base: &entity.Base{
+			ID: genGroupID(groupID),
+		},
+	}
+	if cached, exists := s.getCachedGroupInfo(groupID); exists {
+		group.Name = cached.GroupName
+	}
+	return group
+}
+
+// newEventContext creates an event context with the sender and group filled in
+func (s *Service) newEventContext(payload event.Payload, senderID, groupID int64, t int64) *event.Context {
+	ctx := event.NewContext(s.getContext(), payload)
+	if t != 0 {
+		ctx.Time = time.Unix(t, 0)
+	}
+	ctx.Group = s.groupEntity(groupID)
+	if user := s.userEntity(senderID); user != nil {
+		ctx.Sender = &entity.Sender{User: user}
+		if ctx.Group != nil {
+			ctx.Sender.From = ctx.Group.Base
+		}
+	}
+	return ctx
+}
+
+// noticePayload translates a OneBot notice into a typed core event, returning the payload and the acting user
+func (s *Service) noticePayload(notice *NoticeEvent) (event.Payload, int64) {
+	switch notice.NoticeType {
+	case "group_increase":
+		return &event.MemberJoin{
+			Member:   s.userEntity(notice.UserID),
+			Operator: s.userEntity(notice.OperatorID),
+			Invited:  notice.SubType == "invite",
+		}, notice.UserID
+	case "group_decrease":
+		payload := &event.MemberLeave{
+			Member: s.userEntity(notice.UserID),
+			Kicked: notice.SubType == "kick" || notice.SubType == "kick_me",
+		}
+		if payload.Kicked {
+			payload.Operator = s.userEntity(notice.OperatorID)
+		}
+		return payload, notice.UserID
+	case "friend_add":
+		return &event.FriendAdd{
+			User: s.userEntity(notice.UserID),
+		}, notice.UserID
+	case "group_recall", "friend_recall":
+		operatorID := notice.OperatorID
+		if operatorID == 0 {
+			operatorID = notice.UserID
+		}
+		return &event.MessageRecall{
+			MessageID: fmt.Sprintf("%d", notice.MessageID),
+			Author:    s.userEntity(notice.UserID),
+			Operator:  s.userEntity(operatorID),
+		}, operatorID
+	case "group_ban":
+		payload := &event.GroupMute{
+			Target:   s.userEntity(notice.UserID),
+			Operator: s.userEntity(notice.OperatorID),
+		}
+		if notice.SubType != "lift_ban" {
+			payload.Duration = time.Duration(notice.Duration) * time.Second
+		}
+		return payload, notice.OperatorID
+	case "group_upload":
+		if notice.File == nil {
+			return nil, 0
+		}
+		payload := &event.FileUpload{
+			Uploader: s.userEntity(notice.UserID),
+			Name:     notice.File.Name,
+			Size:     notice.File.Size,
+		}
+		if notice.File.URL != "" {
+			payload.ResourceID = s.saveFileResource(notice.File.URL, notice.File.Name)
+		}
+		return payload, notice.UserID
+	case "notify":
+		if notice.SubType == "poke" {
+			return &event.Poke{
+				Operator: s.userEntity(notice.UserID),
+				Target:   s.userEntity(notice.TargetID),
+			}, notice.UserID
+		}
+	}
+	return nil, 0
+}
+
+func (s *Service) emitNotice(notice *NoticeEvent) {
+	payload, senderID := s.noticePayload(notice)
+	if payload == nil {
+		return
+	}
+	s.emitEvent(s.newEventContext(payload, senderID, notice.GroupID, notice.Time))
+}
+
+func (s *Service) emitRequest(request *RequestEvent) {
+	var payload event.Payload
+	switch request.RequestType {
+	case "friend":
+		payload = &event.FriendRequest{
+			Request: event.NewRequest(
+				func(remark string) error {
+					return s.setFriendAddRequest(request.Flag, true, remark)
+				},
+				func(reason string) error {
+					return s.setFriendAddRequest(request.Flag, false, "")
+				},
+			),
+			User:    s.userEntity(request.UserID),
+			Comment: request.Comment,
+		}
+	case "group":
+		payload = &event.GroupRequest{
+			Request: event.NewRequest(
+				func(remark string) error {
+					return s.setGroupAddRequest(request.Flag, request.SubType, true, "")
+				},
+				func(reason string) error {
+					return s.setGroupAddRequest(request.Flag, request.SubType, false, reason)
+				},
+			),
+			User:    s.userEntity(request.UserID),
+			Comment: request.Comment,
+			Invite:  request.SubType == "invite",
+		}
+	default:
+		s.logger.Debug("Unknown request type: %s", request.RequestType)
+		return
+	}
+	s.emitEvent(s.newEventContext(payload, request.UserID, request.GroupID, request.Time))
+}
+
+func (s *Service) saveFileResource(url string, name string) string {
+	refLink := urlpkg.Values{
+		"url": {url},
+		"ext": {strings.TrimPrefix(path.Ext(name), ".")},
+	}.Encode()
+
+	resourceID := s.grb.SaveResourceLink(s.getContext().ID(), refLink)
+	s.logger.Debug("Saved file resource link: %s -> %s", url, resourceID)
+	return resourceID
+}
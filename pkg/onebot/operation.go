@@ -0,0 +1,178 @@
+package onebot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+// MessageOperator implementation
+
+func (ctx *Context) Recall(msgID string) error {
+	messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.deleteMessage(messageID)
+}
+
+func (ctx *Context) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	return nil, botc.ErrUnsupported
+}
+
+func (ctx *Context) React(msgID string, emoji string) error {
+	messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.setMessageEmojiLike(messageID, emojiID(emoji))
+}
+
+func parseMessageID(id string) (int64, error) {
+	messageID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid message ID %s: %v", id, err)
+	}
+	return messageID, nil
+}
+
+// emojiID converts a unicode emoji to the decimal code point used by QQ, face IDs are kept as is
+func emojiID(emoji string) string {
+	if _, err := strconv.Atoi(emoji); err == nil {
+		return emoji
+	}
+	r, _ := utf8.DecodeRuneInString(emoji)
+	return strconv.Itoa(int(r))
+}
+
+// GroupManager implementation
+
+func (ctx *Context) Kick(groupID, userID string, reject bool) error {
+	gid, uid, err := parseMemberID(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.service.setGroupKick(gid, uid, reject); err != nil {
+		return err
+	}
+	ctx.service.updateCachedMemberCount(gid, -1)
+	return nil
+}
+
+func (ctx *Context) Mute(groupID, userID string, duration time.Duration) error {
+	gid, uid, err := parseMemberID(groupID, userID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.setGroupBan(gid, uid, int64(duration/time.Second))
+}
+
+func (ctx *Context) MuteAll(groupID string, enable bool) error {
+	gid, err := parseGroupID(groupID)
+	if err != nil {
+		return fmt.Errorf("invalid group ID %s: %v", groupID, err)
+	}
+	return ctx.service.setGroupWholeBan(gid, enable)
+}
+
+func (ctx *Context) SetAdmin(groupID, userID string, enable bool) error {
+	gid, uid, err := parseMemberID(groupID, userID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.setGroupAdmin(gid, uid, enable)
+}
+
+func (ctx *Context) SetCard(groupID, userID, card string) error {
+	gid, uid, err := parseMemberID(groupID, userID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.setGroupCard(gid, uid, card)
+}
+
+func (ctx *Context) SetTitle(groupID, userID, title string) error {
+	gid, uid, err := parseMemberID(groupID, userID)
+	if err != nil {
+		return err
+	}
+	return ctx.service.setGroupSpecialTitle(gid, uid, title)
+}
+
+func parseMemberID(groupID, userID string) (int64, int64, error) {
+	gid, err := parseGroupID(groupID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid group ID %s: %v", groupID, err)
+	}
+	uid, err := parseUserID(userID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user ID %s: %v", userID, err)
+	}
+	return gid, uid, nil
+}
+
+// MessageHistory implementation
+
+func (ctx *Context) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := ctx.service.getMsg(messageID)
+	if err != nil {
+		if stored, storeErr := ctx.service.grb.StoredMessage(ctx.ID(), msgID); storeErr == nil {
+			return stored, nil
+		}
+		return nil, err
+	}
+	return ctx.service.parseMessage(msg)
+}
+
+// GetHistory uses get_group_msg_history for group chats and get_friend_msg_history for direct chats.
+// OneBot user and group IDs share the same format, so the chat type cannot be derived from chatID
+func (ctx *Context) GetHistory(chatType botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	id, err := parseGroupID(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat ID %s: %v", chatID, err)
+	}
+
+	var beforeID int64
+	if before != "" {
+		if beforeID, err = parseMessageID(before); err != nil {
+			return nil, err
+		}
+	}
+
+	action, params := "get_friend_msg_history", map[string]interface{}{"user_id": id}
+	if chatType == botc.GroupMessage {
+		action, params = "get_group_msg_history", map[string]interface{}{"group_id": id}
+	}
+
+	// Request one extra message since the history may include the anchor itself
+	events, err := ctx.service.getMsgHistory(action, params, beforeID, limit+1)
+	if err != nil {
+		if stored, storeErr := ctx.service.grb.StoredHistory(ctx.ID(), chatID, before, limit); storeErr == nil {
+			return stored, nil
+		}
+		return nil, err
+	}
+
+	messages := make([]*botc.BaseMessage, 0, len(events))
+	for idx := range events {
+		if beforeID != 0 && events[idx].MessageID == beforeID {
+			continue
+		}
+		msg, err := ctx.service.parseMessage(&events[idx])
+		if err != nil {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	if limit > 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	urlpkg "net/url"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -16,6 +15,7 @@ import (
 	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
 	"github.com/Jel1ySpot/conic"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -66,8 +66,10 @@ type Service struct {
 	cache Cache
 }
 
-func Create() *Service {
-	return &Service{
+// Create creates a OneBot adapter. An optional config directory can be given
+// to run several accounts side by side, defaulting to DefaultConfigPath
+func Create(configPath ...string) *Service {
+	s := &Service{
 		configPath: DefaultConfigPath,
 		conic:      conic.New(),
 		status:     botc.Offline,
@@ -82,6 +84,10 @@ func Create() *Service {
 			groupList:  make(map[int64]Group),
 		},
 	}
+	if len(configPath) > 0 && configPath[0] != "" {
+		s.configPath = configPath[0]
+	}
+	return s
 }
 
 func (s *Service) Name() string {
@@ -123,8 +129,15 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 	// Start cache refresh routine
 	go s.cacheRefreshRoutine()
 
-	s.status = botc.Online
 	grb.AddContext(s.getContext())
+	if s.config.RateLimit != nil && s.config.RateLimit.Enable && s.config.RateLimit.Interval > 0 {
+		// The OneBot rate limit interval overrides the global send limit for this account
+		grb.SetSendLimit(s.getContext().ID(), GoroBot.SendLimitConfig{
+			Rate:  1000 / float64(s.config.RateLimit.Interval),
+			Burst: 1,
+		})
+	}
+	s.setStatus(botc.Online, "")
 
 	s.logger.Success("OneBot adapter initialized successfully")
 	return nil
@@ -132,7 +145,8 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 
 func (s *Service) Release(grb *GoroBot.Instant) error {
 	s.logger.Info("Releasing OneBot adapter...")
-	s.status = botc.Offline
+	s.setStatus(botc.Offline, "adapter released")
+	grb.RemoveContext(s.getContext().ID())
 	s.ctxCancel()
 
 	if s.apiConn != nil {
@@ -179,30 +193,29 @@ func (s *Service) DownloadResourceFromRefLink(refLink string) (string, error) {
 		target = filepath.Join("resources", uuid.NewString()+ext)
 	}
 
-	resp, err := s.httpClient.Get(rawURL)
-	if err != nil {
-		return "", fmt.Errorf("request resource failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if _, err := util.Download(context.Background(), s.httpClient, rawURL, target, util.TransferOptions{}); err != nil {
+		return "", fmt.Errorf("download resource failed: %w", err)
 	}
+	return target, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// OpenResourceFromRefLink opens the resource body for streaming, the caller closes it
+func (s *Service) OpenResourceFromRefLink(ctx context.Context, refLink string) (io.ReadCloser, int64, error) {
+	values, err := urlpkg.ParseQuery(refLink)
 	if err != nil {
-		return "", fmt.Errorf("read resource failed: %w", err)
+		return nil, 0, fmt.Errorf("invalid ref link: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-		return "", fmt.Errorf("create cache dir failed: %w", err)
+	rawURL := values.Get("url")
+	if rawURL == "" {
+		return nil, 0, fmt.Errorf("ref link missing url")
 	}
 
-	if err := os.WriteFile(target, data, 0644); err != nil {
-		return "", fmt.Errorf("write resource failed: %w", err)
+	resp, err := util.OpenURL(ctx, s.httpClient, rawURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request resource failed: %w", err)
 	}
-
-	return target, nil
+	return resp.Body, resp.ContentLength, nil
 }
 
 func (s *Service) getContext() *Context {
@@ -266,10 +279,10 @@ func (s *Service) connectionMonitor() {
 				s.logger.Info("Connection offline, attempting to reconnect...")
 				if err := s.reconnect(); err != nil {
 					s.logger.Error("Failed to reconnect: %v", err)
-					s.status = botc.Offline
+					s.setStatus(botc.Offline, err.Error())
 				} else {
 					s.logger.Success("Reconnected successfully")
-					s.status = botc.Online
+					s.setStatus(botc.Online, "")
 				}
 				continue
 			}
@@ -280,10 +293,10 @@ func (s *Service) connectionMonitor() {
 				s.logger.Info("Attempting to reconnect...")
 				if err := s.reconnect(); err != nil {
 					s.logger.Error("Failed to reconnect: %v", err)
-					s.status = botc.Offline
+					s.setStatus(botc.Offline, err.Error())
 				} else {
 					s.logger.Success("Reconnected successfully")
-					s.status = botc.Online
+					s.setStatus(botc.Online, "")
 				}
 			}
 		}
@@ -455,6 +468,17 @@ func (s *Service) invalidateGroupCache() {
 	s.logger.Debug("Group list cache invalidated")
 }
 
+// updateCachedMemberCount adjusts the cached member count of a group after a membership change
+func (s *Service) updateCachedMemberCount(groupID int64, delta int32) {
+	s.cache.groupListMu.Lock()
+	defer s.cache.groupListMu.Unlock()
+
+	if group, exists := s.cache.groupList[groupID]; exists {
+		group.MemberCount += delta
+		s.cache.groupList[groupID] = group
+	}
+}
+
 // forceCacheRefresh immediately refreshes all caches
 func (s *Service) forceCacheRefresh() error {
 	s.logger.Info("Force refreshing all OneBot caches...")
@@ -42,7 +42,7 @@ func (s *Service) initConfig() error {
 		if err := util.MkdirIfNotExists(s.configPath); err != nil {
 			return err
 		}
-		if err := os.WriteFile(s.configPath, ExampleConfig, 0644); err != nil {
+		if err := os.WriteFile(path.Join(s.configPath, "config.yaml"), ExampleConfig, 0644); err != nil {
 			return fmt.Errorf("failed to create config file: %v", err)
 		}
 		s.logger.Warning("QBot config file created.")
@@ -3,38 +3,80 @@ package qbot
 import (
 	"strings"
 
-	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 	"github.com/tencent-connect/botgo/dto"
 	"github.com/tencent-connect/botgo/event"
 )
 
+// eventHandler 处理一条已通过签名校验的回调事件
+type eventHandler func(payload *dto.WSPayload) error
+
+// handle 将 botgo 风格的类型化处理函数包装为 eventHandler
+func handle[T any](fn func(payload *dto.WSPayload, data *T) error) eventHandler {
+	return func(payload *dto.WSPayload) error {
+		data := new(T)
+		if err := event.ParseData(payload.RawMessage, data); err != nil {
+			return err
+		}
+		return fn(payload, data)
+	}
+}
+
+// registerHandlers 注册当前实例的事件处理函数。
+// botgo 的 event.RegisterHandlers 是全局的，多个实例会互相覆盖，因此由 webhook 自行分发
 func (s *Service) registerHandlers() {
-	_ = event.RegisterHandlers(
+	message := func(event *dto.WSPayload, data *dto.Message) error {
+		return s.emitMessage(event, data)
+	}
+	guildMember := handle(s.onGuildMember)
+	c2cFriend := handle(s.onC2CFriend)
+
+	s.handlers = map[dto.EventType]eventHandler{
 		// ***********消息事件***********
-		// 群@机器人消息事件
-		event.ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
-			return s.emitMessage(event, (*dto.Message)(data))
+		// 频道@机器人消息事件
+		dto.EventAtMessageCreate: handle(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			return message(event, (*dto.Message)(data))
 		}),
 		// C2C消息事件
-		event.C2CMessageEventHandler(func(event *dto.WSPayload, data *dto.WSC2CMessageData) error {
-			return s.emitMessage(event, (*dto.Message)(data))
+		dto.EventC2CMessageCreate: handle(func(event *dto.WSPayload, data *dto.WSC2CMessageData) error {
+			return message(event, (*dto.Message)(data))
+		}),
+		// 群@机器人事件
+		dto.EventGroupAtMessageCreate: handle(func(event *dto.WSPayload, data *dto.WSGroupATMessageData) error {
+			return message(event, (*dto.Message)(data))
+		}),
+		// ***********通知事件***********
+		// 频道成员变更事件
+		dto.EventGuildMemberAdd:    guildMember,
+		dto.EventGuildMemberUpdate: guildMember,
+		dto.EventGuildMemberRemove: guildMember,
+		// 单聊好友变更事件
+		dto.EventC2CFriendAdd: c2cFriend,
+		dto.EventC2CFriendDel: c2cFriend,
+		// 消息撤回事件
+		dto.EventMessageDelete: handle(func(event *dto.WSPayload, data *dto.WSMessageDeleteData) error {
+			return s.onMessageDelete(event, (*dto.MessageDelete)(data))
+		}),
+		dto.EventPublicMessageDelete: handle(func(event *dto.WSPayload, data *dto.WSPublicMessageDeleteData) error {
+			return s.onMessageDelete(event, (*dto.MessageDelete)(data))
 		}),
-		// 频道@机器人事件
-		event.GroupATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSGroupATMessageData) error {
-			return s.emitMessage(event, (*dto.Message)(data))
+		dto.EventDirectMessageDelete: handle(func(event *dto.WSPayload, data *dto.WSDirectMessageDeleteData) error {
+			return s.onMessageDelete(event, (*dto.MessageDelete)(data))
 		}),
-	)
+	}
 }
 
-func (s *Service) emitMessage(event *dto.WSPayload, data *dto.Message) error {
-	data.Content = strings.TrimSpace(data.Content)
-	if strings.HasPrefix(data.Content, "/") {
-		return s.emitCommand(event, data)
+// dispatch 将事件投递给当前实例注册的处理函数，未注册的事件类型直接忽略
+func (s *Service) dispatch(payload *dto.WSPayload) error {
+	if h, ok := s.handlers[payload.Type]; ok {
+		return h(payload)
 	}
-	return s.grb.MessageEmit(NewMessageContext(s, event, data))
+	return nil
 }
 
-func (s *Service) emitCommand(event *dto.WSPayload, data *dto.Message) error {
-	s.grb.CommandEmit(command.NewCommandContext(NewMessageContext(s, event, data), data.Content[1:]))
-	return nil
+func (s *Service) emitMessage(event *dto.WSPayload, data *dto.Message) error {
+	data.Content = strings.TrimSpace(data.Content)
+	if ref, ok := refFromMessage(data); ok {
+		s.rememberMessage(data.ID, ref)
+	}
+	return s.grb.Dispatch(NewMessageContext(s, event, data), data.Content, "/")
 }
@@ -1,35 +1,46 @@
 package qbot
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/tencent-connect/botgo/interaction/webhook"
+	"io"
 	"net/http"
-	"os"
 	"strings"
 	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/interaction/signature"
+	"github.com/tencent-connect/botgo/interaction/webhook"
 )
 
 func (s *Service) runHttp() error {
 	conf := &s.config.Http
-	http.HandleFunc(conf.Path, func(writer http.ResponseWriter, request *http.Request) {
-		webhook.HTTPHandler(writer, request, &s.config.Credentials)
-	})
+	// 每个实例使用独立的 ServeMux，避免多个实例注册到 http.DefaultServeMux 时冲突
+	mux := http.NewServeMux()
+	mux.HandleFunc(conf.Path, s.webhookService)
+	mux.HandleFunc("/resource/", s.resourceService)
 
-	http.HandleFunc("/resource/", s.resourceService)
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", conf.Host, conf.Port),
+		Handler: mux,
+	}
+	server := s.server
 
 	errChan := make(chan error, 1)
 
 	go func() {
+		var err error
 		if conf.TLS.CertPath != "" && conf.TLS.KeyPath != "" {
 			s.logger.Debug("Serving HTTPS on TLS")
-			if err := http.ListenAndServeTLS(fmt.Sprintf("%s:%d", conf.Host, conf.Port), conf.TLS.CertPath, conf.TLS.KeyPath, nil); err != nil {
-				errChan <- err
-			}
+			err = server.ListenAndServeTLS(conf.TLS.CertPath, conf.TLS.KeyPath)
 		} else {
 			s.logger.Debug("Serving HTTP")
-			if err := http.ListenAndServe(fmt.Sprintf("%s:%d", conf.Host, conf.Port), nil); err != nil {
-				errChan <- err
-			}
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
 		}
 
 		close(errChan)
@@ -43,6 +54,60 @@ func (s *Service) runHttp() error {
 	return nil
 }
 
+// webhookService 处理 QQ 开放平台的 HTTP 回调，流程与 webhook.HTTPHandler 相同，
+// 但事件只投递给当前实例的处理函数
+func (s *Service) webhookService(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		s.logger.Error("Read webhook body failed: %v", err)
+		return
+	}
+
+	credentials := &s.config.Credentials
+	if pass, err := signature.Verify(credentials.AppSecret, request.Header, body); err != nil || !pass {
+		s.logger.Warning("Webhook signature verify failed: %v", err)
+		return
+	}
+
+	payload := &dto.WSPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		s.logger.Error("Unmarshal webhook body failed: %v", err)
+		return
+	}
+	// 原始数据放入，解析事件时需要从里面提取 d
+	payload.RawMessage = body
+	payload.Session = &dto.Session{AppID: credentials.AppID}
+
+	switch payload.OPCode {
+	case dto.HTTPCallbackValidation:
+		data, ok := payload.Data.(map[string]interface{})
+		if !ok {
+			s.logger.Error("Invalid webhook validation data: %+v", payload.Data)
+			return
+		}
+		plainToken, _ := data["plain_token"].(string)
+		eventTs, _ := data["event_ts"].(string)
+		rsp := webhook.GenValidationACK(&dto.WHValidationReq{
+			PlainToken: plainToken,
+			EventTs:    eventTs,
+		}, request.Header, credentials.AppSecret)
+		if rsp != nil {
+			_, _ = writer.Write(rsp)
+		}
+	case dto.WSHeartbeat:
+		seq, _ := payload.Data.(float64)
+		_, _ = writer.Write([]byte(webhook.GenHeartbeatACK(uint32(seq))))
+	case dto.WSDispatchEvent:
+		if err := s.dispatch(payload); err != nil {
+			s.logger.Error("Handle %s event failed: %v", payload.Type, err)
+			_, _ = writer.Write([]byte(webhook.GenDispatchACK(false)))
+			return
+		}
+		_, _ = writer.Write([]byte(webhook.GenDispatchACK(true)))
+	}
+}
+
 func (s *Service) resourceService(writer http.ResponseWriter, request *http.Request) {
 	// 检查请求路径是否符合 /resource/<id> 格式
 	path := strings.TrimPrefix(request.URL.Path, "/resource")
@@ -53,21 +118,15 @@ func (s *Service) resourceService(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
-	// 获取资源路径
-	resourcePath, err := s.grb.LoadResourceFromID(id)
-	if err != nil {
-		http.Error(writer, "Resource not found", http.StatusNotFound)
-		return
-	}
-
-	// 读取资源文件内容
-	data, err := os.ReadFile(resourcePath)
+	// 打开资源内容，请求断开时取消下载
+	body, _, err := s.grb.OpenResourceContext(request.Context(), id, GoroBot.ResourceOptions{})
 	if err != nil {
 		http.Error(writer, "Resource not found", http.StatusNotFound)
 		return
 	}
+	defer body.Close()
 
-	// 返回资源文件内容
+	// 以流的方式返回资源文件内容
 	writer.WriteHeader(http.StatusOK)
-	_, _ = writer.Write(data)
+	_, _ = io.Copy(writer, body)
 }
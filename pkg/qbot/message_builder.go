@@ -3,6 +3,7 @@ package qbot
 import (
 	"context"
 	"fmt"
+	"io"
 	urlpkg "net/url"
 	"os"
 	"path"
@@ -112,6 +113,15 @@ func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
 	return m
 }
 
+// ImageFromReader 将全部内容读入内存，QQ 机器人的富媒体接口需要 base64 编码的文件数据，不会流式上传
+func (m *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return m
+	}
+	return m.ImageFromData(data)
+}
+
 func (m *MessageBuilder) VideoFromFile(path string) *MessageBuilder {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -221,30 +231,31 @@ func (m *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
 	}
 
 	info, ok := entity.ParseInfo(id)
-	if !ok || info.Protocol != "lagrange" {
+	if !ok || info.Protocol != m.Protocol() {
 		return nil, fmt.Errorf("invalid id %s", id)
 	}
 	idType, id := info.Args[0], info.Args[1]
 
+	m.ctx.grb.WaitSend(m.ctx.ID())
 	switch idType {
 	case "user":
 		data, err := m.ctx.api.PostC2CMessage(context.Background(), id, m.Build())
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.ctx.grb, m.ctx, data), nil
+		return m.ctx.recordSent(data, msgRef{kind: idType, target: id}, FormatID(idType, id)), nil
 	case "group":
 		data, err := m.ctx.api.PostGroupMessage(context.Background(), id, m.Build())
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.ctx.grb, m.ctx, data), nil
+		return m.ctx.recordSent(data, msgRef{kind: idType, target: id}, FormatID(idType, id)), nil
 	case "channel":
 		data, err := m.ctx.api.PostMessage(context.Background(), id, m.Build())
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.ctx.grb, m.ctx, data), nil
+		return m.ctx.recordSent(data, msgRef{kind: idType, target: id}, FormatID(idType, id)), nil
 	}
 	return nil, fmt.Errorf("invalid id type %s", idType)
 }
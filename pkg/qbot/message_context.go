@@ -72,26 +72,27 @@ func (m *MessageContext) reply(body *dto.MessageToCreate) (*botc.BaseMessage, er
 		body.EventID = m.event.EventID
 	}
 	body.MsgID = m.data.ID
+	m.bot.grb.WaitSend(m.bot.ID())
 	if m.data.DirectMessage {
 		msg, err := m.bot.api.PostC2CMessage(context.Background(), m.data.Author.ID, body)
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.bot.grb, m.bot, msg), nil
+		return m.bot.sentMessage(msg, m.data), nil
 	}
 	if m.data.GroupID != "" {
 		msg, err := m.bot.api.PostGroupMessage(context.Background(), m.data.GroupID, body)
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.bot.grb, m.bot, msg), nil
+		return m.bot.sentMessage(msg, m.data), nil
 	}
 	if m.data.ChannelID != "" {
 		msg, err := m.bot.api.PostMessage(context.Background(), m.data.ChannelID, body)
 		if err != nil {
 			return nil, err
 		}
-		return ParseMessage(m.bot.grb, m.bot, msg), nil
+		return m.bot.sentMessage(msg, m.data), nil
 	}
 	return nil, nil
 }
@@ -100,6 +101,19 @@ func (m *MessageContext) ReplyText(a ...any) (*botc.BaseMessage, error) {
 	return m.NewMessageBuilder().Text(fmt.Sprint(a...)).ReplyTo(m)
 }
 
+// sentMessage 转换机器人回复 to 时发出的消息，并记录其所在会话
+func (s *Service) sentMessage(sent *dto.Message, to *dto.Message) *botc.BaseMessage {
+	ref, _ := refFromMessage(to)
+	chat := &botc.BaseMessage{
+		MessageType: botc.GroupMessage,
+		Sender:      parseSender(to),
+	}
+	if to.DirectMessage {
+		chat.MessageType = botc.DirectMessage
+	}
+	return s.recordSent(sent, ref, chat.ChatID())
+}
+
 // ParseMessage 将 dto.Message 转换为 BaseMessage
 func ParseMessage(grb *GoroBot.Instant, bot *Service, data *dto.Message) *botc.BaseMessage {
 	b := botc.NewBuilder()
@@ -0,0 +1,132 @@
+package qbot
+
+import (
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	coreEvent "github.com/Jel1ySpot/GoroBot/pkg/core/event"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+func (s *Service) onGuildMember(event *dto.WSPayload, data *dto.WSGuildMemberData) error {
+	member := (*dto.Member)(data)
+	user := parseMember(member)
+	if user == nil {
+		return nil
+	}
+
+	var payload coreEvent.Payload
+	switch event.Type {
+	case dto.EventGuildMemberAdd:
+		payload = &coreEvent.MemberJoin{
+			Member: user,
+		}
+	case dto.EventGuildMemberRemove:
+		leave := &coreEvent.MemberLeave{
+			Member: user,
+		}
+		if member.OpUserID != "" && member.OpUserID != member.User.ID {
+			leave.Operator = userEntity(member.OpUserID)
+			leave.Kicked = true
+		}
+		payload = leave
+	default:
+		return nil
+	}
+
+	ctx := s.newEventContext(payload, user, &entity.Base{
+		ID:   FormatID("guild", member.GuildID),
+		Name: member.GuildID,
+	})
+	return s.grb.EventContextEmit(ctx)
+}
+
+func (s *Service) onC2CFriend(event *dto.WSPayload, data *dto.WSC2CFriendData) error {
+	if event.Type != dto.EventC2CFriendAdd {
+		return nil
+	}
+	user := userEntity(data.OpenID)
+	user.Name = data.Nick
+	user.Nickname = data.Nick
+	user.Avatar = data.Avatar
+
+	ctx := s.newEventContext(&coreEvent.FriendAdd{User: user}, user, nil)
+	if data.Timestamp != 0 {
+		ctx.Time = time.Unix(int64(data.Timestamp), 0)
+	}
+	return s.grb.EventContextEmit(ctx)
+}
+
+func (s *Service) onMessageDelete(event *dto.WSPayload, data *dto.MessageDelete) error {
+	operator := userEntity(data.OpUser.ID)
+	operator.Name = data.OpUser.Username
+	operator.Avatar = data.OpUser.Avatar
+
+	var author *entity.User
+	var from *entity.Base
+	if sender := parseSender(&data.Message); sender != nil {
+		author, from = sender.User, sender.From
+	}
+
+	ctx := s.newEventContext(&coreEvent.MessageRecall{
+		MessageID: data.Message.ID,
+		Author:    author,
+		Operator:  operator,
+	}, operator, from)
+	return s.grb.EventContextEmit(ctx)
+}
+
+// setStatus 更新登录状态，状态变化时触发 bot_online / bot_offline 事件
+func (s *Service) setStatus(status botc.LoginStatus, reason string) {
+	if s.status == status {
+		return
+	}
+	s.status = status
+
+	var ctx *coreEvent.Context
+	if status == botc.Online {
+		ctx = coreEvent.NewContext(s, &coreEvent.BotOnline{})
+	} else {
+		ctx = coreEvent.NewContext(s, &coreEvent.BotOffline{Reason: reason})
+	}
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("触发 %s 事件失败：%v", ctx.Content, err)
+	}
+}
+
+func (s *Service) newEventContext(payload coreEvent.Payload, sender *entity.User, from *entity.Base) *coreEvent.Context {
+	ctx := coreEvent.NewContext(s, payload)
+	if from != nil {
+		ctx.Group = &entity.Group{Base: from}
+	}
+	if sender != nil {
+		ctx.Sender = &entity.Sender{
+			User: sender,
+			From: from,
+		}
+	}
+	return ctx
+}
+
+func userEntity(id string) *entity.User {
+	return &entity.User{
+		Base: &entity.Base{
+			ID: FormatID("user", id),
+		},
+	}
+}
+
+func parseMember(member *dto.Member) *entity.User {
+	if member.User == nil {
+		return nil
+	}
+	return &entity.User{
+		Base: &entity.Base{
+			ID:     FormatID("user", member.User.ID),
+			Name:   member.User.Username,
+			Avatar: member.User.Avatar,
+		},
+		Nickname: member.Nick,
+	}
+}
@@ -0,0 +1,105 @@
+package qbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 消息索引容量，超出后最早的消息将无法撤回或回应
+const messageIndexSize = 4096
+
+// msgRef 记录消息所在的会话，撤回接口需要会话 ID 而消息 ID 中没有
+type msgRef struct {
+	kind   string // user / group / channel / dms
+	target string
+}
+
+// refFromMessage 根据消息内容推断所在会话
+func refFromMessage(data *dto.Message) (msgRef, bool) {
+	switch {
+	case data.GroupID != "":
+		return msgRef{kind: "group", target: data.GroupID}, true
+	case data.ChannelID != "":
+		return msgRef{kind: "channel", target: data.ChannelID}, true
+	case data.DirectMessage && data.GuildID != "":
+		return msgRef{kind: "dms", target: data.GuildID}, true
+	case data.Author != nil:
+		return msgRef{kind: "user", target: data.Author.ID}, true
+	}
+	return msgRef{}, false
+}
+
+func (s *Service) rememberMessage(id string, ref msgRef) {
+	if id == "" || ref.target == "" {
+		return
+	}
+	s.messages.Add(id, ref)
+}
+
+// recordSent 转换机器人发出的消息，记录其所在会话并保存到 GoroBot 消息存储
+func (s *Service) recordSent(data *dto.Message, ref msgRef, chatID string) *botc.BaseMessage {
+	s.rememberMessage(data.ID, ref)
+	msg := ParseMessage(s.grb, s, data)
+	s.grb.StoreMessage(s.ID(), chatID, msg)
+	return msg
+}
+
+// --- MessageOperator 接口实现 ---
+
+func (s *Service) Recall(msgID string) error {
+	ref, ok := s.messages.Get(msgID)
+	if !ok {
+		return fmt.Errorf("unknown message %s", msgID)
+	}
+
+	ctx := context.Background()
+	switch ref.kind {
+	case "user":
+		return s.api.RetractC2CMessage(ctx, ref.target, msgID)
+	case "group":
+		return s.api.RetractGroupMessage(ctx, ref.target, msgID)
+	case "channel":
+		return s.api.RetractMessage(ctx, ref.target, msgID)
+	case "dms":
+		return s.api.RetractDMMessage(ctx, ref.target, msgID)
+	}
+	return botc.ErrUnsupported
+}
+
+func (s *Service) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	return nil, botc.ErrUnsupported
+}
+
+// React 仅支持频道消息，数字视为系统表情 ID，其余视为 emoji
+func (s *Service) React(msgID string, emoji string) error {
+	ref, ok := s.messages.Get(msgID)
+	if !ok {
+		return fmt.Errorf("unknown message %s", msgID)
+	}
+	if ref.kind != "channel" {
+		return botc.ErrUnsupported
+	}
+
+	e := dto.Emoji{ID: emoji, Type: 1}
+	if _, err := strconv.Atoi(emoji); err != nil {
+		r, _ := utf8.DecodeRuneInString(emoji)
+		e = dto.Emoji{ID: strconv.Itoa(int(r)), Type: 2}
+	}
+	return s.api.CreateMessageReaction(context.Background(), ref.target, msgID, e)
+}
+
+// --- MessageHistory 接口实现 ---
+// 开放平台不提供历史消息接口，全部从 GoroBot 消息存储中查询
+
+func (s *Service) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	return s.grb.StoredMessage(s.ID(), msgID)
+}
+
+func (s *Service) GetHistory(_ botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	return s.grb.StoredHistory(s.ID(), chatID, before, limit)
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	urlpkg "net/url"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -16,9 +15,11 @@ import (
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
 	"github.com/Jel1ySpot/conic"
 	"github.com/google/uuid"
 	"github.com/tencent-connect/botgo"
+	"github.com/tencent-connect/botgo/dto"
 	"github.com/tencent-connect/botgo/openapi"
 	"github.com/tencent-connect/botgo/token"
 )
@@ -30,18 +31,28 @@ type Service struct {
 
 	api       openapi.OpenAPI
 	ctxCancel context.CancelFunc
-
-	grb    *GoroBot.Instant
-	status botc.LoginStatus
-	logger logger.Inst
+	server    *http.Server
+	handlers  map[dto.EventType]eventHandler
+	botID     string
+
+	grb      *GoroBot.Instant
+	status   botc.LoginStatus
+	logger   logger.Inst
+	messages *util.LRU[string, msgRef]
 }
 
-func Create() *Service {
-	return &Service{
+// Create 创建 QBot 适配器，可选传入配置目录以便同时运行多个 bot，默认为 DefaultConfigPath
+func Create(configPath ...string) *Service {
+	s := &Service{
 		configPath: DefaultConfigPath,
 		conic:      conic.New(),
 		status:     botc.Offline,
+		messages:   util.NewLRU[string, msgRef](messageIndexSize),
+	}
+	if len(configPath) > 0 && configPath[0] != "" {
+		s.configPath = configPath[0]
 	}
+	return s
 }
 
 func (s *Service) Name() string {
@@ -51,18 +62,25 @@ func (s *Service) Name() string {
 func (s *Service) Init(grb *GoroBot.Instant) error {
 	s.grb = grb
 	s.logger = grb.GetLogger()
-	_, s.ctxCancel = context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctxCancel = cancel
 
 	if err := s.initConfig(); err != nil {
 		return err
 	}
 
 	tokenSource := token.NewQQBotTokenSource(&s.config.Credentials)
-	if err := token.StartRefreshAccessToken(context.Background(), tokenSource); err != nil {
+	if err := token.StartRefreshAccessToken(ctx, tokenSource); err != nil {
 		return err
 	}
 	s.api = botgo.NewOpenAPI(s.config.Credentials.AppID, tokenSource).WithTimeout(5 * time.Second).SetDebug(s.config.Debug)
 
+	// 上下文 ID 用于区分同一进程中的多个 bot，初始化时确定后不再变化
+	s.botID = s.config.Credentials.AppID
+	if u, err := s.api.Me(ctx); err == nil {
+		s.botID = u.ID
+	}
+
 	s.registerHandlers()
 
 	if err := s.runHttp(); err != nil {
@@ -70,21 +88,29 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 	}
 
 	grb.AddContext(s)
+	s.setStatus(botc.Online, "")
 
 	return nil
 }
 
 func (s *Service) Release(grb *GoroBot.Instant) error {
-	s.ctxCancel()
+	if s.ctxCancel != nil {
+		s.ctxCancel()
+	}
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.server.Shutdown(ctx); err != nil {
+			s.logger.Warning("Shutdown http server failed: %v", err)
+		}
+	}
+	s.setStatus(botc.Offline, "adapter released")
+	grb.RemoveContext(s.ID())
 	return nil
 }
 
 func (s *Service) ID() string {
-	u, err := s.api.Me(context.Background())
-	if err != nil {
-		return fmt.Sprintf("%s:%s", s.Protocol(), s.config.Credentials.AppID)
-	}
-	return fmt.Sprintf("%s:%s", s.Protocol(), u.ID)
+	return fmt.Sprintf("%s:%s", s.Protocol(), s.botID)
 }
 
 func (s *Service) Protocol() string {
@@ -146,28 +172,27 @@ func (s *Service) DownloadResourceFromRefLink(refLink string) (string, error) {
 		target = filepath.Join("resources", uuid.NewString()+ext)
 	}
 
-	resp, err := http.Get(rawURL)
-	if err != nil {
-		return "", fmt.Errorf("request resource failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if _, err := util.Download(context.Background(), nil, rawURL, target, util.TransferOptions{}); err != nil {
+		return "", fmt.Errorf("download resource failed: %w", err)
 	}
+	return target, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// OpenResourceFromRefLink 打开资源内容的流，由调用方关闭
+func (s *Service) OpenResourceFromRefLink(ctx context.Context, refLink string) (io.ReadCloser, int64, error) {
+	values, err := urlpkg.ParseQuery(refLink)
 	if err != nil {
-		return "", fmt.Errorf("read resource failed: %w", err)
+		return nil, 0, fmt.Errorf("invalid ref link: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-		return "", fmt.Errorf("create cache dir failed: %w", err)
+	rawURL := values.Get("url")
+	if rawURL == "" {
+		return nil, 0, fmt.Errorf("ref link missing url")
 	}
 
-	if err := os.WriteFile(target, data, 0644); err != nil {
-		return "", fmt.Errorf("write resource failed: %w", err)
+	resp, err := util.OpenURL(ctx, nil, rawURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request resource failed: %w", err)
 	}
-
-	return target, nil
+	return resp.Body, resp.ContentLength, nil
 }
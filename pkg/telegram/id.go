@@ -22,3 +22,20 @@ func ParseChatID(id string) (int64, error) {
 	id = strings.TrimPrefix(id, "telegram:")
 	return strconv.ParseInt(id, 10, 64)
 }
+
+// parseMessageID 解析 genMessageID 生成的 ID，返回 chat ID 与消息 ID
+func parseMessageID(id string) (int64, int, error) {
+	parts := strings.Split(strings.TrimPrefix(id, "telegram:msg&"), "&")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid message id %s", id)
+	}
+	chatID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid message id %s: %w", id, err)
+	}
+	msgID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid message id %s: %w", id, err)
+	}
+	return chatID, msgID, nil
+}
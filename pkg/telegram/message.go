@@ -18,6 +18,11 @@ func ParseMessage(msg *models.Message, s *Service) *botc.BaseMessage {
 
 	builder := botc.NewBuilder()
 
+	// 话题中的消息总是回复话题的创建消息，不视为引用
+	if reply := msg.ReplyToMessage; reply != nil && reply.ForumTopicCreated == nil {
+		builder.Append(botc.QuoteElement, "[回复]", genMessageID(msg.Chat.ID, reply.ID))
+	}
+
 	if msg.Text != "" {
 		builder.Text(msg.Text)
 	}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
@@ -17,6 +19,7 @@ import (
 type MessageBuilder struct {
 	service  *Service
 	elements []*botc.MessageElement
+	temps    []string // 发送后需要删除的临时文件
 	err      error
 }
 
@@ -41,7 +44,8 @@ func (m *MessageBuilder) Quote(msg *botc.BaseMessage) botc.MessageBuilder {
 	}
 	m.elements = append(m.elements, &botc.MessageElement{
 		Type:    botc.QuoteElement,
-		Content: msg.ID,
+		Content: "[回复]",
+		Source:  msg.ID,
 	})
 	return m
 }
@@ -85,6 +89,11 @@ func (m *MessageBuilder) ImageFromUrl(url string) botc.MessageBuilder {
 }
 
 func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
+	return m.ImageFromReader(bytes.NewReader(data))
+}
+
+// ImageFromReader 将内容写入临时文件，发送时从文件流式上传，临时文件在发送后删除
+func (m *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
 	if m.err != nil {
 		return m
 	}
@@ -94,8 +103,9 @@ func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
 		return m
 	}
 	defer tmp.Close()
+	m.temps = append(m.temps, tmp.Name())
 
-	if _, err := tmp.Write(data); err != nil {
+	if _, err := io.Copy(tmp, r); err != nil {
 		m.err = fmt.Errorf("写入临时图片失败: %w", err)
 		return m
 	}
@@ -107,7 +117,37 @@ func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
 	return m
 }
 
+// removeTemps 删除 ImageFromData / ImageFromReader 创建的临时文件
+func (m *MessageBuilder) removeTemps() {
+	for _, name := range m.temps {
+		_ = os.Remove(name)
+	}
+	m.temps = nil
+}
+
+// File 以文件形式发送本地文件，name 为空时使用文件名
+func (m *MessageBuilder) File(path string, name ...string) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	if _, err := os.Stat(path); err != nil {
+		m.err = fmt.Errorf("读取文件失败: %w", err)
+		return m
+	}
+	fileName := filepath.Base(path)
+	if len(name) > 0 && name[0] != "" {
+		fileName = name[0]
+	}
+	m.elements = append(m.elements, &botc.MessageElement{
+		Type:    botc.FileElement,
+		Content: fileName,
+		Source:  path,
+	})
+	return m
+}
+
 func (m *MessageBuilder) ReplyTo(msgCtx botc.MessageContext) (*botc.BaseMessage, error) {
+	defer m.removeTemps()
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -137,6 +177,7 @@ func (m *MessageBuilder) ReplyTo(msgCtx botc.MessageContext) (*botc.BaseMessage,
 }
 
 func (m *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
+	defer m.removeTemps()
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -150,26 +191,43 @@ func (m *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
 	return m.service.sendToChat(chatID, m.elements)
 }
 
-// sendToChat 根据消息元素发送文本或图片消息
+// sendToChat 经过发送限流后根据消息元素发送文本、图片或文件消息，发出的消息会记录到 GoroBot 消息存储中
 func (s *Service) sendToChat(chatID int64, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
 	text := extractText(elements)
 	photoSource := firstImageSource(s, elements)
+	file := firstFile(s, elements)
+	reply := replyParameters(chatID, elements)
 
 	ctx := context.Background()
+	if s.grb != nil {
+		s.grb.WaitSend(s.ID())
+	}
 
+	var (
+		msg *botc.BaseMessage
+		err error
+	)
 	if photoSource != "" {
-		return s.sendPhoto(ctx, chatID, photoSource, text)
+		msg, err = s.sendPhoto(ctx, chatID, photoSource, text, reply)
+	} else if file != nil {
+		msg, err = s.sendDocument(ctx, chatID, file.Source, file.Content, text, reply)
+	} else {
+		msg, err = s.sendText(ctx, chatID, text, reply)
+	}
+	if err == nil && s.grb != nil {
+		s.grb.StoreMessage(s.ID(), genGroupID(chatID), msg)
 	}
-	return s.sendText(ctx, chatID, text)
+	return msg, err
 }
 
-func (s *Service) sendText(ctx context.Context, chatID int64, text string) (*botc.BaseMessage, error) {
+func (s *Service) sendText(ctx context.Context, chatID int64, text string, reply *models.ReplyParameters) (*botc.BaseMessage, error) {
 	if text == "" {
 		text = "(空消息)"
 	}
 	msg, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: chatID,
-		Text:   text,
+		ChatID:          chatID,
+		Text:            text,
+		ReplyParameters: reply,
 	})
 	if err != nil {
 		return nil, err
@@ -177,22 +235,49 @@ func (s *Service) sendText(ctx context.Context, chatID int64, text string) (*bot
 	return ParseMessage(msg, s), nil
 }
 
-func (s *Service) sendPhoto(ctx context.Context, chatID int64, source string, caption string) (*botc.BaseMessage, error) {
+func (s *Service) sendPhoto(ctx context.Context, chatID int64, source string, caption string, reply *models.ReplyParameters) (*botc.BaseMessage, error) {
 	var photo models.InputFile
 
-	if data, err := os.ReadFile(source); err == nil {
+	if file, err := os.Open(source); err == nil {
+		defer file.Close()
 		photo = &models.InputFileUpload{
 			Filename: "image.jpg",
-			Data:     bytes.NewReader(data),
+			Data:     file,
 		}
 	} else {
 		photo = &models.InputFileString{Data: source}
 	}
 
 	msg, err := s.bot.SendPhoto(ctx, &bot.SendPhotoParams{
-		ChatID:  chatID,
-		Photo:   photo,
-		Caption: caption,
+		ChatID:          chatID,
+		Photo:           photo,
+		Caption:         caption,
+		ReplyParameters: reply,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseMessage(msg, s), nil
+}
+
+func (s *Service) sendDocument(ctx context.Context, chatID int64, source string, name string, caption string, reply *models.ReplyParameters) (*botc.BaseMessage, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer file.Close()
+	if name == "" {
+		name = filepath.Base(source)
+	}
+
+	msg, err := s.bot.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID: chatID,
+		Document: &models.InputFileUpload{
+			Filename: name,
+			Data:     file,
+		},
+		Caption:         caption,
+		ReplyParameters: reply,
 	})
 	if err != nil {
 		return nil, err
@@ -200,6 +285,24 @@ func (s *Service) sendPhoto(ctx context.Context, chatID int64, source string, ca
 	return ParseMessage(msg, s), nil
 }
 
+// replyParameters 取第一个引用元素作为回复目标，引用其他会话的消息时忽略
+func replyParameters(chatID int64, elements []*botc.MessageElement) *models.ReplyParameters {
+	for _, elem := range elements {
+		if elem.Type != botc.QuoteElement {
+			continue
+		}
+		quoteChat, msgID, err := parseMessageID(elem.Source)
+		if err != nil || quoteChat != chatID {
+			return nil
+		}
+		return &models.ReplyParameters{
+			MessageID:                msgID,
+			AllowSendingWithoutReply: true,
+		}
+	}
+	return nil
+}
+
 func firstImageSource(s *Service, elements []*botc.MessageElement) string {
 	for _, elem := range elements {
 		if elem.Type != botc.ImageElement {
@@ -216,6 +319,27 @@ func firstImageSource(s *Service, elements []*botc.MessageElement) string {
 	return ""
 }
 
+// firstFile 返回第一个文件元素，Source 为资源 ID 时转换为本地路径
+func firstFile(s *Service, elements []*botc.MessageElement) *botc.MessageElement {
+	for _, elem := range elements {
+		if elem.Type != botc.FileElement {
+			continue
+		}
+		source := elem.Source
+		if s.grb != nil {
+			if p, err := s.grb.LoadResourceFromID(elem.Source); err == nil {
+				source = p
+			}
+		}
+		return &botc.MessageElement{
+			Type:    botc.FileElement,
+			Content: elem.Content,
+			Source:  strings.TrimPrefix(source, "file://"),
+		}
+	}
+	return nil
+}
+
 func extractText(elements []*botc.MessageElement) string {
 	if len(elements) == 0 {
 		return ""
@@ -223,7 +347,7 @@ func extractText(elements []*botc.MessageElement) string {
 	var builder strings.Builder
 	for _, elem := range elements {
 		switch elem.Type {
-		case botc.TextElement, botc.MentionElement, botc.StickerElement, botc.QuoteElement:
+		case botc.TextElement, botc.MentionElement, botc.StickerElement:
 			builder.WriteString(elem.Content)
 		}
 	}
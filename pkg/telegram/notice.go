@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handleNotice 将 Telegram 的服务消息转换为类型化事件，返回 true 表示该消息已被处理
+func (s *Service) handleNotice(update *models.Update) bool {
+	if req := update.ChatJoinRequest; req != nil {
+		chatID, userID := req.Chat.ID, req.From.ID
+		ctx := s.newEventContext(&event.GroupRequest{
+			Request: event.NewRequest(
+				func(remark string) error {
+					_, err := s.bot.ApproveChatJoinRequest(s.ctx, &bot.ApproveChatJoinRequestParams{
+						ChatID: chatID,
+						UserID: userID,
+					})
+					return err
+				},
+				func(reason string) error {
+					_, err := s.bot.DeclineChatJoinRequest(s.ctx, &bot.DeclineChatJoinRequestParams{
+						ChatID: chatID,
+						UserID: userID,
+					})
+					return err
+				},
+			),
+			User:    userEntity(&req.From),
+			Comment: req.Bio,
+		}, &req.From, &req.Chat, req.Date)
+		s.emitEvent(ctx)
+		return true
+	}
+
+	msg := update.Message
+	if msg == nil {
+		return false
+	}
+
+	if len(msg.NewChatMembers) > 0 {
+		for i := range msg.NewChatMembers {
+			member := &msg.NewChatMembers[i]
+			payload := &event.MemberJoin{
+				Member: userEntity(member),
+			}
+			if msg.From != nil && msg.From.ID != member.ID {
+				payload.Operator = userEntity(msg.From)
+				payload.Invited = true
+			}
+			s.emitEvent(s.newEventContext(payload, member, &msg.Chat, msg.Date))
+		}
+		return true
+	}
+
+	if member := msg.LeftChatMember; member != nil {
+		payload := &event.MemberLeave{
+			Member: userEntity(member),
+		}
+		if msg.From != nil && msg.From.ID != member.ID {
+			payload.Operator = userEntity(msg.From)
+			payload.Kicked = true
+		}
+		s.emitEvent(s.newEventContext(payload, member, &msg.Chat, msg.Date))
+		return true
+	}
+
+	return false
+}
+
+// emitFileUpload 群内发送的文件在作为普通消息派发的同时触发 file_upload 事件
+func (s *Service) emitFileUpload(msgCtx *MessageContext) {
+	msg := msgCtx.msg
+	if msg.Document == nil || msg.From == nil || !isGroupChat(&msg.Chat) {
+		return
+	}
+	payload := &event.FileUpload{
+		Uploader: userEntity(msg.From),
+		Name:     msg.Document.FileName,
+		Size:     msg.Document.FileSize,
+	}
+	for _, elem := range msgCtx.Message().Elements {
+		if elem.Type == botc.FileElement {
+			payload.ResourceID = elem.Source
+			break
+		}
+	}
+	s.emitEvent(s.newEventContext(payload, msg.From, &msg.Chat, msg.Date))
+}
+
+// setStatus 更新登录状态，状态变化时触发 bot_online / bot_offline 事件
+func (s *Service) setStatus(status botc.LoginStatus, reason string) {
+	if s.status == status {
+		return
+	}
+	s.status = status
+
+	if status == botc.Online {
+		s.emitEvent(event.NewContext(s, &event.BotOnline{}))
+	} else {
+		s.emitEvent(event.NewContext(s, &event.BotOffline{Reason: reason}))
+	}
+}
+
+func (s *Service) emitEvent(ctx *event.Context) {
+	if err := s.grb.EventContextEmit(ctx); err != nil {
+		s.logger.Error("触发 %s 事件失败: %v", ctx.Content, err)
+	}
+}
+
+func (s *Service) newEventContext(payload event.Payload, sender *models.User, chat *models.Chat, date int) *event.Context {
+	ctx := event.NewContext(s, payload)
+	ctx.Time = time.Unix(int64(date), 0)
+	if isGroupChat(chat) {
+		ctx.Group = &entity.Group{
+			Base: &entity.Base{
+				ID:   genGroupID(chat.ID),
+				Name: chooseName(chat.Title, chat.Username),
+			},
+		}
+	}
+	if sender != nil {
+		ctx.Sender = &entity.Sender{User: userEntity(sender)}
+		if ctx.Group != nil {
+			ctx.Sender.From = ctx.Group.Base
+		}
+	}
+	return ctx
+}
+
+func userEntity(u *models.User) *entity.User {
+	return &entity.User{
+		Base: &entity.Base{
+			ID:   genUserID(u.ID),
+			Name: chooseName(u.Username, u.FirstName),
+		},
+		Nickname: u.FirstName,
+	}
+}
+
+func isGroupChat(chat *models.Chat) bool {
+	return chat.Type == models.ChatTypeGroup || chat.Type == models.ChatTypeSupergroup
+}
@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// --- MessageOperator 接口实现 ---
+
+func (s *Service) Recall(msgID string) error {
+	chatID, messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return err
+	}
+	_, err = s.bot.DeleteMessage(s.ctx, &bot.DeleteMessageParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+	})
+	return err
+}
+
+// Edit 编辑机器人发出的文本消息，目前只支持文本和提及元素
+func (s *Service) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	chatID, messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	for _, elem := range elements {
+		switch elem.Type {
+		case botc.TextElement:
+			text += elem.Content
+		case botc.MentionElement:
+			text += elem.Content
+		default:
+			return nil, fmt.Errorf("telegram can only edit text messages")
+		}
+	}
+
+	msg, err := s.bot.EditMessageText(s.ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseMessage(msg, s), nil
+}
+
+func (s *Service) React(msgID string, emoji string) error {
+	chatID, messageID, err := parseMessageID(msgID)
+	if err != nil {
+		return err
+	}
+	_, err = s.bot.SetMessageReaction(s.ctx, &bot.SetMessageReactionParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Reaction: []models.ReactionType{
+			{
+				Type: models.ReactionTypeTypeEmoji,
+				ReactionTypeEmoji: &models.ReactionTypeEmoji{
+					Emoji: emoji,
+				},
+			},
+		},
+	})
+	return err
+}
+
+// --- GroupManager 接口实现 ---
+
+// Kick 将成员移出群聊，reject 为 false 时立即解封，对方仍可通过链接重新加入
+func (s *Service) Kick(groupID, userID string, reject bool) error {
+	chatID, uid, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.bot.BanChatMember(s.ctx, &bot.BanChatMemberParams{
+		ChatID: chatID,
+		UserID: uid,
+	}); err != nil {
+		return err
+	}
+	if reject {
+		return nil
+	}
+	_, err = s.bot.UnbanChatMember(s.ctx, &bot.UnbanChatMemberParams{
+		ChatID:       chatID,
+		UserID:       uid,
+		OnlyIfBanned: true,
+	})
+	return err
+}
+
+// Mute 限制成员发言，Telegram 要求时长至少 30 秒，不足时按 30 秒处理；duration 为 0 时恢复为群默认权限
+func (s *Service) Mute(groupID, userID string, duration time.Duration) error {
+	chatID, uid, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+
+	if duration == 0 {
+		permissions, err := s.defaultPermissions(chatID)
+		if err != nil {
+			return err
+		}
+		_, err = s.bot.RestrictChatMember(s.ctx, &bot.RestrictChatMemberParams{
+			ChatID:      chatID,
+			UserID:      uid,
+			Permissions: permissions,
+		})
+		return err
+	}
+
+	if duration < 30*time.Second {
+		duration = 30 * time.Second
+	}
+	_, err = s.bot.RestrictChatMember(s.ctx, &bot.RestrictChatMemberParams{
+		ChatID:      chatID,
+		UserID:      uid,
+		Permissions: &models.ChatPermissions{},
+		UntilDate:   int(time.Now().Add(duration).Unix()),
+	})
+	return err
+}
+
+// MuteAll 通过修改群默认权限实现全员禁言，关闭时恢复所有发言权限
+func (s *Service) MuteAll(groupID string, enable bool) error {
+	chatID, err := ParseChatID(groupID)
+	if err != nil {
+		return fmt.Errorf("invalid group id %s: %w", groupID, err)
+	}
+	permissions := models.ChatPermissions{}
+	if !enable {
+		permissions = sendPermissions
+	}
+	_, err = s.bot.SetChatPermissions(s.ctx, &bot.SetChatPermissionsParams{
+		ChatID:      chatID,
+		Permissions: permissions,
+	})
+	return err
+}
+
+// SetAdmin 提升为管理员时授予除提升他人以外的常用权限，取消时撤销全部权限
+func (s *Service) SetAdmin(groupID, userID string, enable bool) error {
+	chatID, uid, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.bot.PromoteChatMember(s.ctx, &bot.PromoteChatMemberParams{
+		ChatID:             chatID,
+		UserID:             uid,
+		CanManageChat:      enable,
+		CanDeleteMessages:  enable,
+		CanRestrictMembers: enable,
+		CanChangeInfo:      enable,
+		CanInviteUsers:     enable,
+		CanPinMessages:     enable,
+	})
+	return err
+}
+
+// SetCard Telegram 没有群名片
+func (s *Service) SetCard(groupID, userID, card string) error {
+	return botc.ErrUnsupported
+}
+
+// SetTitle 设置管理员头衔，目标必须是由机器人提升的管理员
+func (s *Service) SetTitle(groupID, userID, title string) error {
+	chatID, uid, err := parseMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.bot.SetChatAdministratorCustomTitle(s.ctx, &bot.SetChatAdministratorCustomTitleParams{
+		ChatID:      chatID,
+		UserID:      uid,
+		CustomTitle: title,
+	})
+	return err
+}
+
+// sendPermissions 是关闭全员禁言时恢复的权限
+var sendPermissions = models.ChatPermissions{
+	CanSendMessages:       true,
+	CanSendAudios:         true,
+	CanSendDocuments:      true,
+	CanSendPhotos:         true,
+	CanSendVideos:         true,
+	CanSendVideoNotes:     true,
+	CanSendVoiceNotes:     true,
+	CanSendPolls:          true,
+	CanSendOtherMessages:  true,
+	CanAddWebPagePreviews: true,
+}
+
+// defaultPermissions 获取群默认权限，用于解除单个成员的禁言
+func (s *Service) defaultPermissions(chatID int64) (*models.ChatPermissions, error) {
+	chat, err := s.bot.GetChat(s.ctx, &bot.GetChatParams{ChatID: chatID})
+	if err != nil {
+		return nil, err
+	}
+	if chat.Permissions == nil {
+		permissions := sendPermissions
+		return &permissions, nil
+	}
+	return chat.Permissions, nil
+}
+
+func parseMember(groupID, userID string) (int64, int64, error) {
+	chatID, err := ParseChatID(groupID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid group id %s: %w", groupID, err)
+	}
+	uid, err := ParseChatID(userID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user id %s: %w", userID, err)
+	}
+	return chatID, uid, nil
+}
+
+// --- MessageHistory 接口实现 ---
+// Bot API 不提供历史消息接口，全部从 GoroBot 消息存储中查询
+
+func (s *Service) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	return s.grb.StoredMessage(s.ID(), msgID)
+}
+
+func (s *Service) GetHistory(_ botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	return s.grb.StoredHistory(s.ID(), chatID, before, limit)
+}
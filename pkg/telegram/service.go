@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	urlpkg "net/url"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -14,9 +12,9 @@ import (
 
 	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
 	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
-	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
 	"github.com/Jel1ySpot/GoroBot/pkg/core/logger"
+	"github.com/Jel1ySpot/GoroBot/pkg/util"
 	"github.com/Jel1ySpot/conic"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -41,12 +39,17 @@ type Service struct {
 	botUsername string
 }
 
-func Create() *Service {
-	return &Service{
+// Create 创建 Telegram 适配器，可选传入配置目录以便同时运行多个 bot，默认为 DefaultConfigPath
+func Create(configPath ...string) *Service {
+	s := &Service{
 		configPath: DefaultConfigPath,
 		conic:      conic.New(),
 		status:     botc.Offline,
 	}
+	if len(configPath) > 0 && configPath[0] != "" {
+		s.configPath = configPath[0]
+	}
+	return s
 }
 
 func (s *Service) Name() string {
@@ -97,7 +100,7 @@ func (s *Service) Init(grb *GoroBot.Instant) error {
 		}
 	}()
 
-	s.status = botc.Online
+	s.setStatus(botc.Online, "")
 	s.logger.Success("Telegram adapter 初始化完成，Bot: %s (@%s)", s.botName, s.botUsername)
 	return nil
 }
@@ -106,36 +109,42 @@ func (s *Service) Release(grb *GoroBot.Instant) error {
 	if s.cancel != nil {
 		s.cancel()
 	}
-	s.status = botc.Offline
+	s.setStatus(botc.Offline, "adapter released")
+	grb.RemoveContext(s.ID())
 	return nil
 }
 
 // handleUpdate 处理收到的 Telegram 更新
 func (s *Service) handleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
-	if update.Message == nil {
+	if s.handleNotice(update) || update.Message == nil {
 		return
 	}
 
 	msgCtx := NewMessageContext(update.Message, s)
-	text := msgCtx.String()
-
-	if strings.HasPrefix(text, "/") {
-		cmd := strings.TrimSpace(strings.TrimPrefix(text, "/"))
-		// 移除 @botname 后缀
-		if at := strings.Index(cmd, "@"); at != -1 {
-			cmd = cmd[:at]
-		}
-		if cmd != "" {
-			s.grb.CommandEmit(command.NewCommandContext(msgCtx, cmd))
-			return
-		}
+	s.emitFileUpload(msgCtx)
+	// 使用原始文本识别命令，避免引用、图片等元素的占位文本干扰
+	text := update.Message.Text
+	if text == "" {
+		text = update.Message.Caption
 	}
 
-	if err := s.grb.MessageEmit(msgCtx); err != nil {
+	if err := s.grb.Dispatch(msgCtx, trimCommandMention(text), "/"); err != nil {
 		s.logger.Error("触发 message 事件失败: %v", err)
 	}
 }
 
+// trimCommandMention 移除 /command@botname 中的 @botname 后缀
+func trimCommandMention(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return text
+	}
+	name, rest, _ := strings.Cut(text, " ")
+	if at := strings.Index(name, "@"); at != -1 {
+		return strings.TrimSpace(name[:at] + " " + rest)
+	}
+	return text
+}
+
 // Bot 返回底层的 go-telegram/bot 实例，用于调用未封装的 Telegram API
 func (s *Service) Bot() *bot.Bot {
 	return s.bot
@@ -257,28 +266,34 @@ func (s *Service) DownloadResourceFromRefLink(refLink string) (string, error) {
 		target = filepath.Join("resources", uuid.NewString()+ext)
 	}
 
-	resp, err := http.Get(rawURL)
-	if err != nil {
+	if _, err := util.Download(s.ctx, nil, rawURL, target, util.TransferOptions{}); err != nil {
 		return "", fmt.Errorf("download resource failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download resource status %d", resp.StatusCode)
-	}
+	return target, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// OpenResourceFromRefLink 打开资源内容的流，file_id 会先通过 getFile 换成下载地址，由调用方关闭
+func (s *Service) OpenResourceFromRefLink(ctx context.Context, refLink string) (io.ReadCloser, int64, error) {
+	values, err := urlpkg.ParseQuery(refLink)
 	if err != nil {
-		return "", fmt.Errorf("read resource failed: %w", err)
+		return nil, 0, fmt.Errorf("invalid ref link: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-		return "", fmt.Errorf("create dir failed: %w", err)
+	rawURL := values.Get("url")
+	if fileID := values.Get("file_id"); fileID != "" {
+		file, err := s.bot.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+		if err != nil {
+			return nil, 0, fmt.Errorf("getFile failed: %w", err)
+		}
+		rawURL = s.bot.FileDownloadLink(file)
 	}
-
-	if err := os.WriteFile(target, data, 0644); err != nil {
-		return "", fmt.Errorf("write file failed: %w", err)
+	if rawURL == "" {
+		return nil, 0, fmt.Errorf("ref link missing url or file_id")
 	}
 
-	return target, nil
+	resp, err := util.OpenURL(ctx, nil, rawURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("download resource failed: %w", err)
+	}
+	return resp.Body, resp.ContentLength, nil
 }
@@ -0,0 +1,22 @@
+package telegram
+
+import "testing"
+
+func TestTrimCommandMention(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/start", "/start"},
+		{"/start@GoroBot", "/start"},
+		{"/roll@GoroBot 1d6 --hidden", "/roll 1d6 --hidden"},
+		{"/roll 1d6 @alice", "/roll 1d6 @alice"},
+		{"hello @GoroBot", "hello @GoroBot"},
+		{"/@GoroBot", "/"},
+	}
+	for _, tt := range tests {
+		if got := trimCommandMention(tt.text); got != tt.want {
+			t.Errorf("trimCommandMention(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
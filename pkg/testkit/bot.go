@@ -0,0 +1,327 @@
+package testkit
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	GoroBot "github.com/Jel1ySpot/GoroBot/pkg/core"
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/event"
+)
+
+const (
+	Protocol = "testkit"
+
+	DefaultWaitTimeout = 3 * time.Second
+)
+
+// Outbound 记录一条由机器人发出的消息
+type Outbound struct {
+	Target      string           // 目标用户或群组 ID
+	MessageType botc.MessageType // 私聊或群聊
+	Elements    []*botc.MessageElement
+	Message     *botc.BaseMessage // Send/Reply 返回给调用方的消息
+	ReplyTo     *MessageContext   // 通过 Reply 发送时对应的入站消息
+}
+
+// Bot 是一个进程内的 botc.BotContext 实现，不连接任何平台，
+// 所有出站消息都会被记录下来以供断言
+type Bot struct {
+	grb  *GoroBot.Instant
+	id   string
+	name string
+
+	// DefaultSender 为未显式指定发送者的入站消息所使用的用户
+	DefaultSender *entity.User
+
+	contacts []entity.User
+	groups   []entity.Group
+
+	outbound   []Outbound
+	operations []Operation
+	notify     chan struct{}
+	mu         sync.Mutex
+
+	msgSeq atomic.Int64
+}
+
+// New 创建一个测试用适配器上下文并注册到 grb
+func New(grb *GoroBot.Instant, name ...string) *Bot {
+	n := "bot"
+	if len(name) > 0 && name[0] != "" {
+		n = name[0]
+	}
+	b := &Bot{
+		grb:    grb,
+		id:     UserID(n),
+		name:   n,
+		notify: make(chan struct{}),
+		DefaultSender: &entity.User{
+			Base: &entity.Base{
+				ID:   UserID("tester"),
+				Name: "tester",
+			},
+			Nickname:  "tester",
+			Authority: entity.Member,
+		},
+	}
+	if grb != nil {
+		grb.AddContext(b)
+	}
+	return b
+}
+
+// NewInstant 创建一个 Instant 并注册测试适配器，资源保存在 t.TempDir() 中，
+// 测试结束后自动清理
+func NewInstant(t testing.TB, name ...string) (*GoroBot.Instant, *Bot) {
+	t.Helper()
+	grb := GoroBot.Create()
+	grb.UseResourceStore(GoroBot.NewLocalResourceStore(t.TempDir()))
+	return grb, New(grb, name...)
+}
+
+func UserID(name string) string {
+	return fmt.Sprintf("%s:user&%s", Protocol, name)
+}
+
+func GroupID(name string) string {
+	return fmt.Sprintf("%s:group&%s", Protocol, name)
+}
+
+func (b *Bot) genMessageID() string {
+	return fmt.Sprintf("%s:msg&%d", Protocol, b.msgSeq.Add(1))
+}
+
+// --- BotContext 接口实现 ---
+
+func (b *Bot) ID() string {
+	return b.id
+}
+
+func (b *Bot) Name() string {
+	return b.name
+}
+
+func (b *Bot) Protocol() string {
+	return Protocol
+}
+
+func (b *Bot) Status() botc.LoginStatus {
+	return botc.Online
+}
+
+func (b *Bot) NewMessageBuilder() botc.MessageBuilder {
+	return &MessageBuilder{bot: b}
+}
+
+func (b *Bot) SendDirectMessage(target entity.User, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	return b.record(target.ID, botc.DirectMessage, elements, nil), nil
+}
+
+func (b *Bot) SendGroupMessage(target entity.Group, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	return b.record(target.ID, botc.GroupMessage, elements, nil), nil
+}
+
+func (b *Bot) Contacts() []entity.User {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]entity.User(nil), b.contacts...)
+}
+
+func (b *Bot) Groups() []entity.Group {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]entity.Group(nil), b.groups...)
+}
+
+// DownloadResourceFromRefLink 直接返回 refLink 中 file 参数指向的本地文件
+func (b *Bot) DownloadResourceFromRefLink(refLink string) (string, error) {
+	values, err := urlpkg.ParseQuery(refLink)
+	if err != nil {
+		return "", fmt.Errorf("invalid ref link: %w", err)
+	}
+	file := values.Get("file")
+	if file == "" {
+		return "", fmt.Errorf("ref link missing file")
+	}
+	return file, nil
+}
+
+// --- 测试辅助方法 ---
+
+// SetContacts 设置 Contacts 返回的好友列表
+func (b *Bot) SetContacts(users ...entity.User) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.contacts = users
+}
+
+// SetGroups 设置 Groups 返回的群组列表
+func (b *Bot) SetGroups(groups ...entity.Group) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.groups = groups
+}
+
+// SaveResource 将本地文件登记为本适配器的资源，返回可放入 MessageElement.Source 的资源 ID
+func (b *Bot) SaveResource(path string) string {
+	return b.grb.SaveResourceLink(b.ID(), urlpkg.Values{"file": {path}}.Encode())
+}
+
+// Emit 将入站消息作为普通消息事件派发
+func (b *Bot) Emit(msg *MessageContext) error {
+	return b.grb.MessageEmit(msg)
+}
+
+// EmitCommand 将入站消息作为命令派发，text 为去除前缀后的命令文本
+func (b *Bot) EmitCommand(msg *MessageContext, text string) {
+	b.grb.CommandEmit(command.NewCommandContext(msg, text))
+}
+
+// RunCommand 清空出站记录后将 msg 作为命令派发，返回第一条回复的文本，
+// 在 DefaultWaitTimeout 内没有回复时测试失败
+func (b *Bot) RunCommand(t testing.TB, msg *MessageContext, text string) string {
+	t.Helper()
+	b.Reset()
+	b.EmitCommand(msg, text)
+	out, err := b.WaitOutbound(1)
+	if err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return out[0].Text()
+}
+
+// NewEvent 创建一个以 DefaultSender 为发送者的类型化事件上下文，
+// 可在派发前修改 Sender / Group
+func (b *Bot) NewEvent(payload event.Payload) *event.Context {
+	ctx := event.NewContext(b, payload)
+	sender := *b.DefaultSender
+	ctx.Sender = &entity.Sender{User: &sender}
+	return ctx
+}
+
+// EmitEvent 派发一个类型化事件，如 member_join、friend_request 等
+func (b *Bot) EmitEvent(ctx *event.Context) error {
+	return b.grb.EventContextEmit(ctx)
+}
+
+// Outbound 返回目前为止记录到的全部出站消息
+func (b *Bot) Outbound() []Outbound {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Outbound(nil), b.outbound...)
+}
+
+// Reset 清空出站消息与消息操作记录
+func (b *Bot) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.outbound = nil
+	b.operations = nil
+}
+
+// WaitOutbound 等待直到至少记录了 n 条出站消息，超时返回错误。
+// timeout 省略时使用 DefaultWaitTimeout
+func (b *Bot) WaitOutbound(n int, timeout ...time.Duration) ([]Outbound, error) {
+	d := DefaultWaitTimeout
+	if len(timeout) > 0 {
+		d = timeout[0]
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		b.mu.Lock()
+		if len(b.outbound) >= n {
+			out := append([]Outbound(nil), b.outbound...)
+			b.mu.Unlock()
+			return out, nil
+		}
+		ch := b.notify
+		got := len(b.outbound)
+		b.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-timer.C:
+			return b.Outbound(), fmt.Errorf("timed out after %v waiting for %d outbound messages, got %d", d, n, got)
+		}
+	}
+}
+
+// WaitIdle 等待直到 quiet 时间内没有新的出站消息，用于断言“没有更多回复”。
+// timeout 省略时使用 DefaultWaitTimeout
+func (b *Bot) WaitIdle(quiet time.Duration, timeout ...time.Duration) ([]Outbound, error) {
+	d := DefaultWaitTimeout
+	if len(timeout) > 0 {
+		d = timeout[0]
+	}
+	deadline := time.NewTimer(d)
+	defer deadline.Stop()
+
+	for {
+		b.mu.Lock()
+		ch := b.notify
+		b.mu.Unlock()
+
+		idle := time.NewTimer(quiet)
+		select {
+		case <-ch:
+			idle.Stop()
+		case <-idle.C:
+			return b.Outbound(), nil
+		case <-deadline.C:
+			idle.Stop()
+			return b.Outbound(), fmt.Errorf("outbound messages did not settle within %v", d)
+		}
+	}
+}
+
+func (b *Bot) record(target string, messageType botc.MessageType, elements []*botc.MessageElement, replyTo *MessageContext) *botc.BaseMessage {
+	msg := &botc.BaseMessage{
+		MessageType: messageType,
+		ID:          b.genMessageID(),
+		Content:     botc.ElemsToString(elements),
+		Elements:    elements,
+		Sender: &entity.Sender{
+			User: &entity.User{
+				Base: &entity.Base{
+					ID:   b.id,
+					Name: b.name,
+				},
+			},
+		},
+		Time: time.Now(),
+	}
+	if messageType == botc.GroupMessage {
+		msg.Sender.From = &entity.Base{ID: target}
+	}
+
+	b.mu.Lock()
+	b.outbound = append(b.outbound, Outbound{
+		Target:      target,
+		MessageType: messageType,
+		Elements:    elements,
+		Message:     msg,
+		ReplyTo:     replyTo,
+	})
+	close(b.notify)
+	b.notify = make(chan struct{})
+	b.mu.Unlock()
+
+	if b.grb != nil {
+		b.grb.StoreMessage(b.id, target, msg)
+	}
+	return msg
+}
+
+// Text 返回出站消息的文本预览
+func (o Outbound) Text() string {
+	return botc.ElemsToString(o.Elements)
+}
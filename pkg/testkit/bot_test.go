@@ -0,0 +1,107 @@
+package testkit_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/testkit"
+)
+
+func TestWaitOutbound(t *testing.T) {
+	grb, bot := testkit.NewInstant(t)
+
+	if _, err := grb.Command("echo").
+		Argument("text", command.String, true, "").
+		Action(func(ctx *command.Context) error {
+			// 回复之间留出间隔，WaitOutbound 必须等到全部回复
+			for n := range 3 {
+				time.Sleep(10 * time.Millisecond)
+				_, _ = ctx.ReplyText(fmt.Sprintf("%s %d", ctx.KvArgs["text"], n))
+			}
+			return nil
+		}).
+		Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := bot.NewTextMessage("/echo hi").InGroup(testkit.GroupID("g"))
+	bot.EmitCommand(msg, "echo hi")
+
+	out, err := bot.WaitOutbound(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("got %d outbound messages, want 3", len(out))
+	}
+	for n, o := range out {
+		if want := fmt.Sprintf("hi %d", n); o.Text() != want {
+			t.Errorf("outbound %d: got %q, want %q", n, o.Text(), want)
+		}
+		if o.Target != testkit.GroupID("g") || o.MessageType != botc.GroupMessage {
+			t.Errorf("outbound %d sent to %s (%v)", n, o.Target, o.MessageType)
+		}
+		if o.ReplyTo != msg {
+			t.Errorf("outbound %d is not a reply to the inbound message", n)
+		}
+	}
+
+	// 没有更多回复
+	out, err = bot.WaitIdle(50 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("got %d outbound messages after idle, want 3", len(out))
+	}
+}
+
+func TestWaitOutboundTimeout(t *testing.T) {
+	_, bot := testkit.NewInstant(t)
+
+	start := time.Now()
+	out, err := bot.WaitOutbound(1, 20*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if len(out) != 0 {
+		t.Fatalf("got %d outbound messages, want none", len(out))
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("returned after %v, before the timeout", elapsed)
+	}
+}
+
+func TestWaitIdleSettles(t *testing.T) {
+	_, bot := testkit.NewInstant(t)
+
+	// 持续发送时 WaitIdle 不会提前返回
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 5 {
+			_, _ = bot.NewMessageBuilder().Text("tick").Send(testkit.UserID("tester"))
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	out, err := bot.WaitIdle(50 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if len(out) != 5 {
+		t.Fatalf("got %d outbound messages, want 5", len(out))
+	}
+
+	bot.Reset()
+	if _, err := bot.WaitIdle(30*time.Millisecond, 20*time.Millisecond); err == nil {
+		t.Fatal("expected an error when quiet is longer than the timeout")
+	}
+	if len(bot.Outbound()) != 0 {
+		t.Fatal("Reset did not clear outbound messages")
+	}
+}
@@ -0,0 +1,121 @@
+package testkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/command"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+type MessageBuilder struct {
+	bot      *Bot
+	elements []*botc.MessageElement
+	err      error
+}
+
+func (m *MessageBuilder) Protocol() string {
+	return Protocol
+}
+
+func (m *MessageBuilder) append(elementType botc.ElementType, content string, source string) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	m.elements = append(m.elements, &botc.MessageElement{
+		Type:    elementType,
+		Content: content,
+		Source:  source,
+	})
+	return m
+}
+
+func (m *MessageBuilder) Text(text string) botc.MessageBuilder {
+	return m.append(botc.TextElement, text, "")
+}
+
+func (m *MessageBuilder) Quote(msg *botc.BaseMessage) botc.MessageBuilder {
+	return m.append(botc.QuoteElement, "[回复]", msg.Marshall())
+}
+
+func (m *MessageBuilder) Mention(id string) botc.MessageBuilder {
+	return m.append(botc.MentionElement, fmt.Sprintf("@%s", id), id)
+}
+
+func (m *MessageBuilder) ImageFromFile(path string) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	if _, err := os.Stat(path); err != nil {
+		m.err = fmt.Errorf("failed to open image file: %w", err)
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", m.bot.SaveResource(path))
+}
+
+func (m *MessageBuilder) ImageFromUrl(url string) botc.MessageBuilder {
+	return m.append(botc.ImageElement, "[图片]", url)
+}
+
+func (m *MessageBuilder) ImageFromData(data []byte) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	id, err := m.bot.grb.SaveResourceData(data, "dat")
+	if err != nil {
+		m.err = err
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", id)
+}
+
+func (m *MessageBuilder) ImageFromReader(r io.Reader) botc.MessageBuilder {
+	if m.err != nil {
+		return m
+	}
+	id, err := m.bot.grb.SaveResourceReader(r, "dat")
+	if err != nil {
+		m.err = err
+		return m
+	}
+	return m.append(botc.ImageElement, "[图片]", id)
+}
+
+// Elements 返回已构建的消息元素
+func (m *MessageBuilder) Elements() []*botc.MessageElement {
+	return m.elements
+}
+
+func (m *MessageBuilder) ReplyTo(msgCtx botc.MessageContext) (*botc.BaseMessage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if ctx, ok := msgCtx.(*command.Context); ok {
+		msgCtx = ctx.MessageContext
+	}
+	if ctx, ok := msgCtx.(*MessageContext); ok {
+		return ctx.Reply(m.elements)
+	}
+
+	msg := msgCtx.Message()
+	if msg.MessageType == botc.GroupMessage && msg.Sender != nil && msg.Sender.From != nil {
+		return m.bot.SendGroupMessage(entity.Group{Base: &entity.Base{ID: msg.Sender.From.ID}}, m.elements)
+	}
+	if msg.Sender != nil && msg.Sender.User != nil {
+		return m.bot.SendDirectMessage(entity.User{Base: &entity.Base{ID: msg.Sender.ID}}, m.elements)
+	}
+	return nil, fmt.Errorf("unable to determine reply target")
+}
+
+func (m *MessageBuilder) Send(id string) (*botc.BaseMessage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	info, ok := entity.ParseInfo(id)
+	if ok && info.Protocol == Protocol && len(info.Args) > 0 && info.Args[0] == "group" {
+		return m.bot.SendGroupMessage(entity.Group{Base: &entity.Base{ID: id}}, m.elements)
+	}
+	return m.bot.SendDirectMessage(entity.User{Base: &entity.Base{ID: id}}, m.elements)
+}
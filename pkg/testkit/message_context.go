@@ -0,0 +1,106 @@
+package testkit
+
+import (
+	"fmt"
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+	"github.com/Jel1ySpot/GoroBot/pkg/core/entity"
+)
+
+// MessageContext 是注入到 GoroBot 中的入站消息
+type MessageContext struct {
+	bot  *Bot
+	base *botc.BaseMessage
+}
+
+// NewMessage 以 DefaultSender 的身份创建一条私聊入站消息，
+// 可继续使用 From / InGroup / WithAuthority 修改
+func (b *Bot) NewMessage(elements ...*botc.MessageElement) *MessageContext {
+	sender := *b.DefaultSender
+	base := *sender.Base
+	sender.Base = &base
+
+	return &MessageContext{
+		bot: b,
+		base: &botc.BaseMessage{
+			MessageType: botc.DirectMessage,
+			ID:          b.genMessageID(),
+			Content:     botc.ElemsToString(elements),
+			Elements:    elements,
+			Sender:      &entity.Sender{User: &sender},
+			Time:        time.Now(),
+		},
+	}
+}
+
+// NewTextMessage 创建一条纯文本入站消息
+func (b *Bot) NewTextMessage(text string) *MessageContext {
+	return b.NewMessage(botc.NewBuilder().Text(text).Build()...)
+}
+
+// From 设置发送者
+func (m *MessageContext) From(id string, name ...string) *MessageContext {
+	m.base.Sender.ID = id
+	if len(name) > 0 {
+		m.base.Sender.Name = name[0]
+		m.base.Sender.Nickname = name[0]
+	}
+	return m
+}
+
+// InGroup 将消息转为来自 groupID 的群消息
+func (m *MessageContext) InGroup(groupID string, name ...string) *MessageContext {
+	m.base.MessageType = botc.GroupMessage
+	m.base.Sender.From = &entity.Base{ID: groupID}
+	if len(name) > 0 {
+		m.base.Sender.From.Name = name[0]
+	}
+	return m
+}
+
+// WithAuthority 设置发送者的平台权限。平台权限为 Banned 时视为 Member，测试封禁请使用 GrantRole
+func (m *MessageContext) WithAuthority(authority entity.Authority) *MessageContext {
+	m.base.Sender.Authority = authority
+	return m
+}
+
+// --- MessageContext 接口实现 ---
+
+func (m *MessageContext) Protocol() string {
+	return Protocol
+}
+
+func (m *MessageContext) BotContext() botc.BotContext {
+	return m.bot
+}
+
+func (m *MessageContext) String() string {
+	return m.base.Content
+}
+
+func (m *MessageContext) Message() *botc.BaseMessage {
+	return m.base
+}
+
+func (m *MessageContext) SenderID() string {
+	return m.base.Sender.ID
+}
+
+func (m *MessageContext) NewMessageBuilder() botc.MessageBuilder {
+	return &MessageBuilder{bot: m.bot}
+}
+
+func (m *MessageContext) Reply(elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	if elements == nil {
+		return nil, fmt.Errorf("elements is nil")
+	}
+	if m.base.MessageType == botc.GroupMessage {
+		return m.bot.record(m.base.Sender.From.ID, botc.GroupMessage, elements, m), nil
+	}
+	return m.bot.record(m.base.Sender.ID, botc.DirectMessage, elements, m), nil
+}
+
+func (m *MessageContext) ReplyText(a ...any) (*botc.BaseMessage, error) {
+	return m.Reply(botc.NewBuilder().Text(fmt.Sprint(a...)).Build())
+}
@@ -0,0 +1,106 @@
+package testkit
+
+import (
+	"time"
+
+	botc "github.com/Jel1ySpot/GoroBot/pkg/core/bot_context"
+)
+
+// Operation 记录一次通过 botc.MessageOperator 或 botc.GroupManager 进行的操作
+type Operation struct {
+	Kind      string // recall / edit / react / kick / mute / mute_all / set_admin / set_card / set_title
+	MessageID string
+	Elements  []*botc.MessageElement // edit 时的新内容
+	Emoji     string                 // react 时的表情
+
+	GroupID  string
+	UserID   string
+	Duration time.Duration // mute 时的禁言时长
+	Enable   bool          // kick 时为 reject，mute_all / set_admin 时为开关
+	Text     string        // set_card / set_title 时的新内容
+}
+
+// --- MessageOperator 接口实现 ---
+
+func (b *Bot) Recall(msgID string) error {
+	b.recordOperation(Operation{Kind: "recall", MessageID: msgID})
+	return nil
+}
+
+func (b *Bot) Edit(msgID string, elements []*botc.MessageElement) (*botc.BaseMessage, error) {
+	b.recordOperation(Operation{Kind: "edit", MessageID: msgID, Elements: elements})
+	return &botc.BaseMessage{
+		ID:       msgID,
+		Content:  botc.ElemsToString(elements),
+		Elements: elements,
+	}, nil
+}
+
+func (b *Bot) React(msgID string, emoji string) error {
+	b.recordOperation(Operation{Kind: "react", MessageID: msgID, Emoji: emoji})
+	return nil
+}
+
+// --- GroupManager 接口实现 ---
+
+func (b *Bot) Kick(groupID, userID string, reject bool) error {
+	b.recordOperation(Operation{Kind: "kick", GroupID: groupID, UserID: userID, Enable: reject})
+	return nil
+}
+
+func (b *Bot) Mute(groupID, userID string, duration time.Duration) error {
+	b.recordOperation(Operation{Kind: "mute", GroupID: groupID, UserID: userID, Duration: duration})
+	return nil
+}
+
+func (b *Bot) MuteAll(groupID string, enable bool) error {
+	b.recordOperation(Operation{Kind: "mute_all", GroupID: groupID, Enable: enable})
+	return nil
+}
+
+func (b *Bot) SetAdmin(groupID, userID string, enable bool) error {
+	b.recordOperation(Operation{Kind: "set_admin", GroupID: groupID, UserID: userID, Enable: enable})
+	return nil
+}
+
+func (b *Bot) SetCard(groupID, userID, card string) error {
+	b.recordOperation(Operation{Kind: "set_card", GroupID: groupID, UserID: userID, Text: card})
+	return nil
+}
+
+func (b *Bot) SetTitle(groupID, userID, title string) error {
+	b.recordOperation(Operation{Kind: "set_title", GroupID: groupID, UserID: userID, Text: title})
+	return nil
+}
+
+// --- MessageHistory 接口实现 ---
+// 查询 grb 的消息存储，需要先调用 grb.UseMessageStore
+
+func (b *Bot) GetMessage(msgID string) (*botc.BaseMessage, error) {
+	if b.grb == nil {
+		return nil, botc.ErrUnsupported
+	}
+	return b.grb.StoredMessage(b.id, msgID)
+}
+
+func (b *Bot) GetHistory(_ botc.MessageType, chatID string, before string, limit int) ([]*botc.BaseMessage, error) {
+	if b.grb == nil {
+		return nil, botc.ErrUnsupported
+	}
+	return b.grb.StoredHistory(b.id, chatID, before, limit)
+}
+
+// Operations 返回目前为止记录到的全部操作
+func (b *Bot) Operations() []Operation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Operation(nil), b.operations...)
+}
+
+func (b *Bot) recordOperation(op Operation) {
+	b.mu.Lock()
+	b.operations = append(b.operations, op)
+	close(b.notify)
+	b.notify = make(chan struct{})
+	b.mu.Unlock()
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ErrTooLarge 表示传输的内容超过了大小限制
+var ErrTooLarge = errors.New("content exceeds size limit")
+
+// 每传输这么多字节报告一次进度
+const progressStep = 64 << 10
+
+// ProgressFunc 报告传输进度，done 为已传输的字节数，total 未知时为 -1
+type ProgressFunc func(done, total int64)
+
+// TransferOptions 控制流式传输
+type TransferOptions struct {
+	MaxSize  int64        // 内容大小上限（字节），不大于 0 表示不限制
+	Progress ProgressFunc // 每传输 64KB 和结束时调用，可以为 nil
+}
+
+// TransferReader 在读取时统计字节数、检查大小限制并报告进度
+type TransferReader struct {
+	r        io.Reader
+	total    int64
+	opts     TransferOptions
+	done     int64
+	reported int64
+}
+
+// NewTransferReader 包装 r，total 为内容长度，未知时为 -1。
+// 超过 MaxSize 时读取返回 ErrTooLarge
+func NewTransferReader(r io.Reader, total int64, opts TransferOptions) *TransferReader {
+	return &TransferReader{r: r, total: total, opts: opts}
+}
+
+func (t *TransferReader) Read(p []byte) (int, error) {
+	if t.opts.MaxSize > 0 && t.total > t.opts.MaxSize {
+		return 0, ErrTooLarge
+	}
+	n, err := t.r.Read(p)
+	t.done += int64(n)
+	if t.opts.MaxSize > 0 && t.done > t.opts.MaxSize {
+		return n, ErrTooLarge
+	}
+	if t.opts.Progress != nil && (t.done-t.reported >= progressStep || err == io.EOF && t.done != t.reported) {
+		t.reported = t.done
+		t.opts.Progress(t.done, t.total)
+	}
+	return n, err
+}
+
+// Done 返回已读取的字节数
+func (t *TransferReader) Done() int64 {
+	return t.done
+}
+
+// StatusError 是响应状态码不是 200 时 OpenURL 返回的错误
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.Code)
+}
+
+// Temporary 报告这个状态码是否可能在重试后成功，即请求超时、请求过多和服务端错误
+func (e *StatusError) Temporary() bool {
+	return e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// OpenURL 发起 GET 请求并检查状态码，client 为 nil 时使用 http.DefaultClient。
+// 状态码不是 200 时返回 *StatusError
+func OpenURL(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{Code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// Download 以流的方式将 url 的内容保存到 target，返回写入的字节数。
+// 内容先写入同目录下的临时文件，成功后再重命名，失败或取消时不会留下不完整的文件
+func Download(ctx context.Context, client *http.Client, url string, target string, opts TransferOptions) (int64, error) {
+	resp, err := OpenURL(ctx, client, url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return WriteFile(target, NewTransferReader(resp.Body, resp.ContentLength, opts))
+}
+
+// WriteFile 将 r 的全部内容写入 target，必要时创建目录。
+// 内容先写入同目录下的临时文件，成功后再重命名
+func WriteFile(target string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".download-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), target)
+}
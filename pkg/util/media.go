@@ -0,0 +1,468 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// ErrUnknownMedia 表示无法识别内容的格式或其中没有时长信息
+var ErrUnknownMedia = errors.New("unknown media format")
+
+// MediaDuration 读取音视频的时长，只解析文件头和必要的索引，不解码内容。
+// 支持 WAV、AVI、FLAC、Ogg（Vorbis、Opus）、MP4/MOV/M4A、Matroska/WebM 和 MP3
+func MediaDuration(r io.ReadSeeker) (time.Duration, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	head := make([]byte, 12)
+	n, err := readAt(r, head, 0)
+	if err != nil && n < 4 {
+		return 0, ErrUnknownMedia
+	}
+	head = head[:n]
+
+	switch {
+	case n >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return wavDuration(r, size)
+	case n >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "AVI ":
+		return aviDuration(r)
+	case string(head[:4]) == "fLaC":
+		return flacDuration(r)
+	case string(head[:4]) == "OggS":
+		return oggDuration(r, size)
+	case n >= 8 && string(head[4:8]) == "ftyp":
+		return mp4Duration(r, size)
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return matroskaDuration(r)
+	case string(head[:3]) == "ID3" || parseMP3Header(head) != nil:
+		return mp3Duration(r, size)
+	}
+	return 0, ErrUnknownMedia
+}
+
+// readAt 从 offset 处读取，内容不足时返回实际读取的字节数
+func readAt(r io.ReadSeeker, p []byte, offset int64) (int, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r, p)
+}
+
+func seconds(n float64, rate float64) (time.Duration, error) {
+	if rate <= 0 || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, ErrUnknownMedia
+	}
+	return time.Duration(n / rate * float64(time.Second)), nil
+}
+
+func wavDuration(r io.ReadSeeker, fileSize int64) (time.Duration, error) {
+	var (
+		hdr      [8]byte
+		byteRate uint32
+		offset   int64 = 12
+	)
+	for {
+		if _, err := readAt(r, hdr[:], offset); err != nil {
+			return 0, ErrUnknownMedia
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		switch string(hdr[:4]) {
+		case "fmt ":
+			var format [16]byte
+			if size < 16 {
+				return 0, ErrUnknownMedia
+			}
+			if _, err := io.ReadFull(r, format[:]); err != nil {
+				return 0, ErrUnknownMedia
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:12])
+		case "data":
+			// 截断或边录边写的文件中 data 块的长度可能超出实际内容
+			return seconds(float64(min(size, fileSize-offset-8)), float64(byteRate))
+		}
+		offset += 8 + size + size&1
+	}
+}
+
+func aviDuration(r io.ReadSeeker) (time.Duration, error) {
+	// RIFF 头之后是 LIST hdrl，其中第一个块是 avih
+	var b [40]byte
+	if _, err := readAt(r, b[:], 12); err != nil {
+		return 0, ErrUnknownMedia
+	}
+	if string(b[0:4]) != "LIST" || string(b[8:12]) != "hdrl" || string(b[12:16]) != "avih" {
+		return 0, ErrUnknownMedia
+	}
+	usPerFrame := binary.LittleEndian.Uint32(b[20:24])
+	frames := binary.LittleEndian.Uint32(b[36:40])
+	return time.Duration(usPerFrame) * time.Duration(frames) * time.Microsecond, nil
+}
+
+func flacDuration(r io.ReadSeeker) (time.Duration, error) {
+	// 第一个元数据块必须是 STREAMINFO
+	var b [42]byte
+	if _, err := readAt(r, b[:], 0); err != nil || b[4]&0x7f != 0 {
+		return 0, ErrUnknownMedia
+	}
+	info := b[8:]
+	rate := uint64(info[10])<<12 | uint64(info[11])<<4 | uint64(info[12])>>4
+	total := uint64(info[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if total == 0 {
+		// 总采样数为 0 表示未知
+		return 0, ErrUnknownMedia
+	}
+	return seconds(float64(total), float64(rate))
+}
+
+func oggDuration(r io.ReadSeeker, size int64) (time.Duration, error) {
+	// 第一页的第一个包是编码的识别头
+	page := make([]byte, 27+255+64)
+	n, _ := readAt(r, page, 0)
+	if n < 27 || n < 27+int(page[26]) {
+		return 0, ErrUnknownMedia
+	}
+	packet := page[27+int(page[26]) : n]
+
+	var rate, preSkip float64
+	switch {
+	case len(packet) >= 16 && string(packet[:7]) == "\x01vorbis":
+		rate = float64(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && string(packet[:8]) == "OpusHead":
+		// Opus 的 granule 总是以 48kHz 计数
+		rate = 48000
+		preSkip = float64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return 0, ErrUnknownMedia
+	}
+
+	// 最后一页的 granule 是总采样数，一页最大不超过 65307 字节
+	start := max(size-65536-27, 0)
+	tail := make([]byte, size-start)
+	if _, err := readAt(r, tail, start); err != nil {
+		return 0, ErrUnknownMedia
+	}
+	for end := len(tail); ; {
+		i := bytes.LastIndex(tail[:end], []byte("OggS"))
+		if i < 0 {
+			return 0, ErrUnknownMedia
+		}
+		if i+14 <= len(tail) {
+			// 没有包在本页结束时 granule 为 -1，只有头部的页 granule 为 0
+			if granule := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14])); granule > 0 {
+				return seconds(float64(granule)-preSkip, rate)
+			}
+		}
+		end = i
+	}
+}
+
+func mp4Duration(r io.ReadSeeker, size int64) (time.Duration, error) {
+	start, end, err := findMP4Box(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	moov, moovEnd := start, end
+	start, _, err = findMP4Box(r, moov, moovEnd, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+
+	var b [32]byte
+	if _, err := readAt(r, b[:], start); err != nil {
+		return 0, ErrUnknownMedia
+	}
+	var duration, timescale uint64
+	if b[0] == 1 {
+		duration, timescale = binary.BigEndian.Uint64(b[24:32]), uint64(binary.BigEndian.Uint32(b[20:24]))
+	} else {
+		duration, timescale = uint64(binary.BigEndian.Uint32(b[16:20])), uint64(binary.BigEndian.Uint32(b[12:16]))
+		if duration == math.MaxUint32 {
+			return 0, ErrUnknownMedia
+		}
+	}
+	if duration == 0 {
+		// 分片 MP4 的 mvhd 中没有时长，总时长记录在 mvex 的 mehd 中
+		if duration, err = mp4FragmentDuration(r, moov, moovEnd); err != nil {
+			return 0, err
+		}
+	}
+	return seconds(float64(duration), float64(timescale))
+}
+
+func mp4FragmentDuration(r io.ReadSeeker, moov, moovEnd int64) (uint64, error) {
+	start, end, err := findMP4Box(r, moov, moovEnd, "mvex")
+	if err != nil {
+		return 0, err
+	}
+	start, _, err = findMP4Box(r, start, end, "mehd")
+	if err != nil {
+		return 0, err
+	}
+	var b [12]byte
+	if _, err := readAt(r, b[:4], start); err != nil {
+		return 0, ErrUnknownMedia
+	}
+	var duration uint64
+	if b[0] == 1 {
+		if _, err := io.ReadFull(r, b[4:12]); err != nil {
+			return 0, ErrUnknownMedia
+		}
+		duration = binary.BigEndian.Uint64(b[4:12])
+	} else {
+		if _, err := io.ReadFull(r, b[4:8]); err != nil {
+			return 0, ErrUnknownMedia
+		}
+		duration = uint64(binary.BigEndian.Uint32(b[4:8]))
+	}
+	if duration == 0 {
+		return 0, ErrUnknownMedia
+	}
+	return duration, nil
+}
+
+// findMP4Box 在 [start, end) 中查找类型为 typ 的 box，返回其内容的范围
+func findMP4Box(r io.ReadSeeker, start, end int64, typ string) (int64, int64, error) {
+	var b [16]byte
+	for pos := start; pos+8 <= end; {
+		if _, err := readAt(r, b[:8], pos); err != nil {
+			return 0, 0, ErrUnknownMedia
+		}
+		boxSize, hdrLen := int64(binary.BigEndian.Uint32(b[:4])), int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - pos
+		case 1:
+			if _, err := io.ReadFull(r, b[8:16]); err != nil {
+				return 0, 0, ErrUnknownMedia
+			}
+			boxSize, hdrLen = int64(binary.BigEndian.Uint64(b[8:16])), 16
+		}
+		if boxSize < hdrLen || boxSize > end-pos {
+			return 0, 0, ErrUnknownMedia
+		}
+		if string(b[4:8]) == typ {
+			return pos + hdrLen, pos + boxSize, nil
+		}
+		pos += boxSize
+	}
+	return 0, 0, ErrUnknownMedia
+}
+
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlCluster       = 0x1F43B675
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+	ebmlUnknownSize   = -1
+)
+
+func matroskaDuration(r io.ReadSeeker) (time.Duration, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	// EBML 头
+	if _, size, err := readEBMLElement(r); err != nil || size == ebmlUnknownSize {
+		return 0, ErrUnknownMedia
+	} else if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+		return 0, err
+	}
+	if id, _, err := readEBMLElement(r); err != nil || id != ebmlSegment {
+		return 0, ErrUnknownMedia
+	}
+
+	// Info 一般在 Segment 的开头，遇到 Cluster 时说明没有时长信息
+	for range 64 {
+		id, size, err := readEBMLElement(r)
+		if err != nil || id == ebmlCluster || size == ebmlUnknownSize {
+			return 0, ErrUnknownMedia
+		}
+		if id != ebmlInfo {
+			if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		info := make([]byte, min(size, 4096))
+		if _, err := io.ReadFull(r, info); err != nil {
+			return 0, ErrUnknownMedia
+		}
+		scale, duration := float64(1000000), -1.0
+		reader := bytes.NewReader(info)
+		for reader.Len() > 0 {
+			id, size, err := readEBMLElement(reader)
+			if err != nil || size < 0 || size > int64(reader.Len()) {
+				break
+			}
+			value := make([]byte, size)
+			_, _ = io.ReadFull(reader, value)
+			switch {
+			case id == ebmlTimecodeScale && size <= 8:
+				var v uint64
+				for _, c := range value {
+					v = v<<8 | uint64(c)
+				}
+				scale = float64(v)
+			case id == ebmlDuration && size == 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(value)))
+			case id == ebmlDuration && size == 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(value))
+			}
+		}
+		if duration < 0 {
+			return 0, ErrUnknownMedia
+		}
+		return seconds(duration*scale, float64(time.Second))
+	}
+	return 0, ErrUnknownMedia
+}
+
+// readEBMLElement 读取元素的 ID（保留长度标记位）和内容长度，长度未知时为 ebmlUnknownSize
+func readEBMLElement(r io.Reader) (uint64, int64, error) {
+	id, _, err := readEBMLVint(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, length, err := readEBMLVint(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	mask := uint64(1)<<(7*length) - 1
+	if size&mask == mask {
+		return id, ebmlUnknownSize, nil
+	}
+	return id, int64(size & mask), nil
+}
+
+func readEBMLVint(r io.Reader) (uint64, int, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:1]); err != nil {
+		return 0, 0, err
+	}
+	length := 1
+	for length <= 8 && b[0]&(0x80>>(length-1)) == 0 {
+		length++
+	}
+	if length > 8 {
+		return 0, 0, ErrUnknownMedia
+	}
+	if _, err := io.ReadFull(r, b[1:length]); err != nil {
+		return 0, 0, err
+	}
+	var v uint64
+	for _, c := range b[:length] {
+		v = v<<8 | uint64(c)
+	}
+	return v, length, nil
+}
+
+// mp3Frame 是 MPEG 音频帧头中与时长相关的信息
+type mp3Frame struct {
+	mpeg1      bool
+	mono       bool
+	bitrate    int // kbps
+	sampleRate int
+	samples    int // 每帧的采样数
+}
+
+var (
+	mp3Bitrates = [2][3][14]int{
+		{ // MPEG-1 Layer I、II、III
+			{32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+			{32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+			{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		},
+		{ // MPEG-2、MPEG-2.5 Layer I、II、III
+			{32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+			{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+			{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		},
+	}
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+func parseMP3Header(b []byte) *mp3Frame {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return nil
+	}
+	version := b[1] >> 3 & 3 // 0: MPEG-2.5，2: MPEG-2，3: MPEG-1
+	layer := 4 - int(b[1]>>1&3)
+	bitrateIndex := int(b[2] >> 4)
+	rateIndex := int(b[2] >> 2 & 3)
+	if version == 1 || layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return nil
+	}
+
+	frame := &mp3Frame{mpeg1: version == 3, mono: b[3]>>6 == 3}
+	table := 1
+	if frame.mpeg1 {
+		table = 0
+	}
+	frame.bitrate = mp3Bitrates[table][layer-1][bitrateIndex-1]
+	frame.sampleRate = mp3SampleRates[rateIndex]
+	switch version {
+	case 2:
+		frame.sampleRate /= 2
+	case 0:
+		frame.sampleRate /= 4
+	}
+	switch {
+	case layer == 1:
+		frame.samples = 384
+	case layer == 2 || frame.mpeg1:
+		frame.samples = 1152
+	default:
+		frame.samples = 576
+	}
+	return frame
+}
+
+func mp3Duration(r io.ReadSeeker, size int64) (time.Duration, error) {
+	var offset int64
+	var id3 [10]byte
+	if _, err := readAt(r, id3[:], 0); err == nil && string(id3[:3]) == "ID3" {
+		offset = 10 + (int64(id3[6]&0x7f)<<21 | int64(id3[7]&0x7f)<<14 | int64(id3[8]&0x7f)<<7 | int64(id3[9]&0x7f))
+		if id3[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	buf := make([]byte, 4096)
+	n, _ := readAt(r, buf, offset)
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		frame := parseMP3Header(buf[i:])
+		if frame == nil {
+			continue
+		}
+
+		// VBR 文件的第一帧中有 Xing/Info 或 VBRI 头记录了总帧数
+		side := 17
+		switch {
+		case frame.mpeg1 && !frame.mono:
+			side = 32
+		case !frame.mpeg1 && frame.mono:
+			side = 9
+		}
+		if x := i + 4 + side; x+12 <= len(buf) {
+			if tag := string(buf[x : x+4]); (tag == "Xing" || tag == "Info") && buf[x+7]&1 != 0 {
+				frames := binary.BigEndian.Uint32(buf[x+8 : x+12])
+				return seconds(float64(frames)*float64(frame.samples), float64(frame.sampleRate))
+			}
+		}
+		if v := i + 4 + 32; v+18 <= len(buf) && string(buf[v:v+4]) == "VBRI" {
+			frames := binary.BigEndian.Uint32(buf[v+14 : v+18])
+			return seconds(float64(frames)*float64(frame.samples), float64(frame.sampleRate))
+		}
+
+		// 否则按固定码率估算
+		return seconds(float64(size-offset-int64(i))*8, float64(frame.bitrate)*1000)
+	}
+	return 0, ErrUnknownMedia
+}
@@ -0,0 +1,221 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// 以下函数按各格式的规范拼出只包含文件头和索引的最小样本
+
+func le16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func be64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+func concat(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func riffChunk(id string, data []byte) []byte {
+	chunk := concat([]byte(id), le32(uint32(len(data))), data)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// wavFile 返回 8000Hz 单声道 8 位的 WAV，data 块声明 declared 字节，实际包含 actual 字节
+func wavFile(declared uint32, actual int, chunks ...[]byte) []byte {
+	format := concat(le16(1), le16(1), le32(8000), le32(8000), le16(1), le16(8))
+	body := concat([]byte("WAVE"), riffChunk("fmt ", format), concat(chunks...),
+		[]byte("data"), le32(declared), make([]byte, actual))
+	return concat([]byte("RIFF"), le32(uint32(len(body))), body)
+}
+
+func aviFile(usPerFrame, frames uint32) []byte {
+	avih := concat(le32(usPerFrame), le32(0), le32(0), le32(0), le32(frames), make([]byte, 36))
+	hdrl := concat([]byte("hdrl"), riffChunk("avih", avih))
+	return concat([]byte("RIFF"), le32(uint32(4+8+len(hdrl))), []byte("AVI "), riffChunk("LIST", hdrl))
+}
+
+func flacFile(blockType byte, rate uint32, total uint64) []byte {
+	info := concat(make([]byte, 10), be64(uint64(rate)<<44|1<<41|15<<36|total), make([]byte, 16))
+	return concat([]byte("fLaC"), []byte{0x80 | blockType, 0, 0, byte(len(info))}, info)
+}
+
+func oggPage(granule int64, packet []byte) []byte {
+	return concat([]byte("OggS"), []byte{0, 0}, binary.LittleEndian.AppendUint64(nil, uint64(granule)),
+		le32(1), le32(0), le32(0), []byte{1, byte(len(packet))}, packet)
+}
+
+func vorbisHead(rate uint32) []byte {
+	return concat([]byte("\x01vorbis"), le32(0), []byte{2}, le32(rate), make([]byte, 14))
+}
+
+func opusHead(preSkip uint16) []byte {
+	return concat([]byte("OpusHead"), []byte{1, 2}, le16(preSkip), le32(48000), le16(0), []byte{0})
+}
+
+func mp4Box(typ string, payload ...[]byte) []byte {
+	data := concat(payload...)
+	return concat(be32(uint32(8+len(data))), []byte(typ), data)
+}
+
+func mvhd0(timescale, duration uint32) []byte {
+	return mp4Box("mvhd", make([]byte, 12), be32(timescale), be32(duration), make([]byte, 80))
+}
+
+func mvhd1(timescale uint32, duration uint64) []byte {
+	return mp4Box("mvhd", []byte{1, 0, 0, 0}, make([]byte, 16), be32(timescale), be64(duration), make([]byte, 80))
+}
+
+var ftyp = mp4Box("ftyp", []byte("isom"), be32(512), []byte("isomiso2mp41"))
+
+func ebml(id uint32, data []byte) []byte {
+	idBytes := be32(id)
+	for idBytes[0] == 0 {
+		idBytes = idBytes[1:]
+	}
+	return concat(idBytes, []byte{0x01}, be64(uint64(len(data)))[1:], data)
+}
+
+func matroskaFile(elements ...[]byte) []byte {
+	header := ebml(0x1A45DFA3, ebml(0x4282, []byte("webm")))
+	// Segment 的长度未知，这在直播录制的文件中很常见
+	segment := concat([]byte{0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, concat(elements...))
+	return concat(header, segment)
+}
+
+func float64Bytes(v float64) []byte { return be64(math.Float64bits(v)) }
+func float32Bytes(v float32) []byte { return be32(math.Float32bits(v)) }
+
+// MPEG-1 Layer III 128kbps 44100Hz 立体声、MPEG-2 Layer III 64kbps 22050Hz 单声道的帧头
+var (
+	mp3Header  = []byte{0xFF, 0xFB, 0x90, 0x64}
+	mp3Header2 = []byte{0xFF, 0xF3, 0x80, 0xC4}
+)
+
+func id3Tag(size int) []byte {
+	return concat([]byte("ID3"), []byte{3, 0, 0, 0, 0, byte(size >> 7), byte(size & 0x7f)}, make([]byte, size))
+}
+
+func xingFrame(header []byte, side int, frames uint32) []byte {
+	return concat(header, make([]byte, side), []byte("Xing"), be32(1), be32(frames), make([]byte, 400))
+}
+
+func frameDuration(frames, samples, rate float64) time.Duration {
+	return time.Duration(frames * samples / rate * float64(time.Second))
+}
+
+func TestMediaDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want time.Duration // 为 0 时期望 ErrUnknownMedia
+	}{
+		{"wav", wavFile(4000, 4000), 500 * time.Millisecond},
+		{"wav with odd chunk", wavFile(8000, 8000, riffChunk("LIST", []byte("INFOx"))), time.Second},
+		{"wav truncated data", wavFile(8000, 4000), 500 * time.Millisecond},
+		{"wav streaming size", wavFile(math.MaxUint32, 2000), 250 * time.Millisecond},
+		{"wav without fmt", concat([]byte("RIFF"), le32(100), []byte("WAVE"), []byte("data"), le32(8), make([]byte, 8)), 0},
+		{"wav short fmt", concat([]byte("RIFF"), le32(100), []byte("WAVE"), riffChunk("fmt ", make([]byte, 8))), 0},
+		{"wav without data", concat([]byte("RIFF"), le32(100), []byte("WAVE")), 0},
+
+		{"avi", aviFile(40000, 50), 2 * time.Second},
+		{"avi without avih", concat([]byte("RIFF"), le32(40), []byte("AVI "), riffChunk("LIST", concat([]byte("movi"), make([]byte, 36)))), 0},
+		{"avi truncated", aviFile(40000, 50)[:40], 0},
+
+		{"flac", flacFile(0, 44100, 88200), 2 * time.Second},
+		{"flac unknown length", flacFile(0, 44100, 0), 0},
+		{"flac without streaminfo", flacFile(4, 44100, 88200), 0},
+		{"flac zero rate", flacFile(0, 0, 88200), 0},
+		{"flac truncated", flacFile(0, 44100, 88200)[:30], 0},
+
+		{"vorbis", concat(oggPage(0, vorbisHead(48000)), oggPage(0, []byte("\x03vorbis")), oggPage(96000, make([]byte, 100))), 2 * time.Second},
+		{"vorbis unfinished last page", concat(oggPage(0, vorbisHead(48000)), oggPage(48000, make([]byte, 10)), oggPage(-1, make([]byte, 10))), time.Second},
+		{"opus pre-skip", concat(oggPage(0, opusHead(312)), oggPage(0, []byte("OpusTags")), oggPage(3*48000+312, make([]byte, 50))), 3 * time.Second},
+		{"ogg headers only", concat(oggPage(0, vorbisHead(48000)), oggPage(0, []byte("\x03vorbis"))), 0},
+		{"ogg unknown codec", concat(oggPage(0, []byte("\x80theora0123456789")), oggPage(1000, nil)), 0},
+		{"ogg truncated", oggPage(0, vorbisHead(48000))[:20], 0},
+
+		{"mp4", concat(ftyp, mp4Box("moov", mvhd0(1000, 2500))), 2500 * time.Millisecond},
+		{"mp4 version 1", concat(ftyp, mp4Box("moov", mvhd1(600, 1200))), 2 * time.Second},
+		{"mp4 moov after mdat", concat(ftyp, mp4Box("free"), mp4Box("mdat", make([]byte, 64)), mp4Box("moov", mp4Box("udta"), mvhd0(90000, 450000))), 5 * time.Second},
+		{"mp4 large box", concat(ftyp, be32(1), []byte("mdat"), be64(16+32), make([]byte, 32), mp4Box("moov", mvhd0(1000, 1000))), time.Second},
+		{"mp4 fragmented", concat(ftyp, mp4Box("moov", mvhd0(1000, 0), mp4Box("mvex", mp4Box("mehd", make([]byte, 4), be32(4000))))), 4 * time.Second},
+		{"mp4 fragmented version 1", concat(ftyp, mp4Box("moov", mvhd0(1000, 0), mp4Box("mvex", mp4Box("trex"), mp4Box("mehd", []byte{1, 0, 0, 0}, be64(1500))))), 1500 * time.Millisecond},
+		{"mp4 fragmented without mehd", concat(ftyp, mp4Box("moov", mvhd0(1000, 0))), 0},
+		{"mp4 unknown duration", concat(ftyp, mp4Box("moov", mvhd0(1000, math.MaxUint32))), 0},
+		{"mp4 without moov", concat(ftyp, mp4Box("mdat", make([]byte, 16))), 0},
+		{"mp4 small box", concat(ftyp, be32(4), []byte("moov")), 0},
+		{"mp4 overflowing box", concat(ftyp, be32(1), []byte("mdat"), be64(math.MaxInt64), mp4Box("moov", mvhd0(1000, 1000))), 0},
+		{"mp4 truncated moov", concat(ftyp, mp4Box("moov", mvhd0(1000, 2500)))[:len(ftyp)+20], 0},
+
+		{"matroska", matroskaFile(ebml(0x114D9B74, make([]byte, 10)), ebml(0x1549A966, concat(ebml(0x2AD7B1, []byte{0x0F, 0x42, 0x40}), ebml(0x4489, float64Bytes(1500))))), 1500 * time.Millisecond},
+		{"matroska float32", matroskaFile(ebml(0x1549A966, ebml(0x4489, float32Bytes(2000)))), 2 * time.Second},
+		{"matroska timecode scale", matroskaFile(ebml(0x1549A966, concat(ebml(0x4489, float64Bytes(3)), ebml(0x2AD7B1, []byte{0x3B, 0x9A, 0xCA, 0x00})))), 3 * time.Second},
+		{"matroska cluster before info", matroskaFile(ebml(0x1F43B675, make([]byte, 4)), ebml(0x1549A966, ebml(0x4489, float64Bytes(1500)))), 0},
+		{"matroska info without duration", matroskaFile(ebml(0x1549A966, ebml(0x2AD7B1, []byte{0x0F, 0x42, 0x40}))), 0},
+		{"matroska truncated info", matroskaFile(ebml(0x1549A966, ebml(0x4489, float64Bytes(1500))))[:40], 0},
+		{"matroska invalid vint", concat([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x00}, make([]byte, 8)), 0},
+
+		{"mp3 cbr", concat(mp3Header, make([]byte, 16000-4)), time.Second},
+		{"mp3 id3", concat(id3Tag(300), mp3Header, make([]byte, 32000-4)), 2 * time.Second},
+		{"mp3 junk before sync", concat(id3Tag(10), []byte{0, 0, 0xFF, 0}, mp3Header, make([]byte, 16000-4)), time.Second},
+		{"mp3 xing", xingFrame(mp3Header, 32, 441), frameDuration(441, 1152, 44100)},
+		{"mp3 xing mpeg-2 mono", xingFrame(mp3Header2, 9, 1000), frameDuration(1000, 576, 22050)},
+		{"mp3 vbri", concat(mp3Header, make([]byte, 32), []byte("VBRI"), make([]byte, 10), be32(100), make([]byte, 400)), frameDuration(100, 1152, 44100)},
+		{"mp3 id3 beyond end", concat([]byte("ID3"), []byte{3, 0, 0, 0x7f, 0x7f, 0x7f, 0x7f}, mp3Header), 0},
+		{"mp3 id3 without frames", id3Tag(100), 0},
+
+		{"empty", nil, 0},
+		{"too short", []byte("RIF"), 0},
+		{"text", []byte("hello, world! this is not a media file"), 0},
+	}
+
+	for _, tt := range tests {
+		got, err := MediaDuration(bytes.NewReader(tt.data))
+		if tt.want == 0 {
+			if !errors.Is(err, ErrUnknownMedia) {
+				t.Errorf("%s: got %v, %v, want ErrUnknownMedia", tt.name, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if diff := got - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 任意截断的样本都只能返回错误或时长，不能 panic 或卡住
+func TestMediaDurationTruncated(t *testing.T) {
+	samples := [][]byte{
+		wavFile(4000, 64, riffChunk("LIST", []byte("INFO"))),
+		aviFile(40000, 50),
+		flacFile(0, 44100, 88200),
+		concat(oggPage(0, opusHead(312)), oggPage(48000, make([]byte, 20))),
+		concat(ftyp, mp4Box("moov", mvhd0(1000, 0), mp4Box("mvex", mp4Box("mehd", make([]byte, 4), be32(4000))))),
+		matroskaFile(ebml(0x1549A966, ebml(0x4489, float64Bytes(1500)))),
+		concat(id3Tag(20), xingFrame(mp3Header, 32, 441)),
+	}
+	for _, sample := range samples {
+		for n := range len(sample) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_, _ = MediaDuration(bytes.NewReader(sample[:n]))
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("MediaDuration did not return for %q truncated to %d bytes", sample[:min(n, 4)], n)
+			}
+		}
+	}
+}
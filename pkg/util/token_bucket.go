@@ -0,0 +1,99 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket 是一个并发安全的令牌桶限流器，每秒补充 rate 个令牌，最多积累 burst 个
+type TokenBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	waiting int
+	mu      sync.Mutex
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+		last:   time.Now(),
+	}
+}
+
+// refill 需在持有 mu 时调用
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+// reserve 取走一个令牌并返回需要等待的时间，需在持有 mu 时调用
+func (b *TokenBucket) reserve(now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 || b.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Allow 在有可用令牌时取走一个并返回 true，不会等待
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate <= 0 {
+		return true
+	}
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait 阻塞直到取得一个令牌，rate 不大于 0 时不限流
+func (b *TokenBucket) Wait() {
+	b.mu.Lock()
+	if b.rate <= 0 {
+		b.mu.Unlock()
+		return
+	}
+	delay := b.reserve(time.Now())
+	if delay > 0 {
+		b.waiting++
+	}
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return
+	}
+	time.Sleep(delay)
+
+	b.mu.Lock()
+	b.waiting--
+	b.mu.Unlock()
+}
+
+// SetLimit 修改补充速率和容量，已积累的令牌不超过新的容量
+func (b *TokenBucket) SetLimit(rate float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	b.rate = rate
+	b.burst = float64(max(burst, 1))
+	b.tokens = min(b.tokens, b.burst)
+}
+
+// Status 返回补充速率、容量、当前可用令牌数和正在等待的调用数
+func (b *TokenBucket) Status() (rate float64, burst int, tokens float64, waiting int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	return b.rate, int(b.burst), max(b.tokens, 0), b.waiting
+}
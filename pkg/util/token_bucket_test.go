@@ -0,0 +1,87 @@
+package util
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	b := NewTokenBucket(10, 3)
+	for n := range 3 {
+		if !b.Allow() {
+			t.Fatalf("token %d rejected within burst", n)
+		}
+	}
+	if b.Allow() {
+		t.Fatal("token allowed after burst was used up")
+	}
+
+	// 每秒补充 10 个，100ms 后应当有一个
+	time.Sleep(120 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("token not refilled")
+	}
+	if b.Allow() {
+		t.Fatal("more tokens refilled than elapsed time allows")
+	}
+}
+
+func TestTokenBucketRefillCapped(t *testing.T) {
+	b := NewTokenBucket(1000, 2)
+	time.Sleep(20 * time.Millisecond)
+	if _, burst, tokens, _ := b.Status(); burst != 2 || tokens > 2 {
+		t.Fatalf("tokens %v exceed burst %d", tokens, burst)
+	}
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	b := NewTokenBucket(0, 1)
+	for range 100 {
+		if !b.Allow() {
+			t.Fatal("bucket with rate 0 should not limit")
+		}
+	}
+	start := time.Now()
+	b.Wait()
+	b.Wait()
+	if time.Since(start) > 10*time.Millisecond {
+		t.Fatal("Wait blocked on an unlimited bucket")
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b := NewTokenBucket(50, 1)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Wait()
+		}()
+	}
+	wg.Wait()
+
+	// 第一个令牌立即可用，之后每 20ms 一个
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Fatalf("5 waits finished in %v, expected at least 80ms", elapsed)
+	}
+	if _, _, _, waiting := b.Status(); waiting != 0 {
+		t.Fatalf("%d waiters left", waiting)
+	}
+}
+
+func TestTokenBucketSetLimit(t *testing.T) {
+	b := NewTokenBucket(1, 5)
+	b.SetLimit(1, 2)
+	rate, burst, tokens, _ := b.Status()
+	if rate != 1 || burst != 2 || tokens > 2 {
+		t.Fatalf("got rate %v burst %d tokens %v", rate, burst, tokens)
+	}
+	b.Allow()
+	b.Allow()
+	if b.Allow() {
+		t.Fatal("tokens not capped by the new burst")
+	}
+}